@@ -0,0 +1,443 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	artifactapp "github.com/megamake/megamake/internal/domains/artifact/app"
+
+	"github.com/megamake/megamake/internal/app/wiring"
+	"github.com/megamake/megamake/internal/platform/console"
+)
+
+// runArtifact implements:
+//
+//	megamake artifact show    [file] [--tool T] [--dir D] [--json]
+//	megamake artifact extract [file] --part xml|json|prompt|manifest [--select EXPR] [--raw] [--tool T] [--dir D]
+//	megamake artifact latest  --tool T [--dir D]
+//	megamake artifact prune   [--keep N] [--older-than AGE] [--tool T] [--dir D] [--dry-run] [--json]
+//	megamake artifact diff    <base> [head] [--dir D] [--json]
+//	megamake artifact verify  [file] [--tool T] [--dir D] [--root R] [--json]
+//
+// Without a file, show/extract/verify read the artifact the tool's *_latest.txt pointer names.
+func runArtifact(ctr wiring.Container, globalArtifactDir string, argv []string, stdout io.Writer, stderr io.Writer) int {
+	log := console.New(stderr)
+
+	if len(argv) == 0 {
+		writeArtifactHelp(stderr)
+		return exitUsage
+	}
+
+	sub := argv[0]
+	args := argv[1:]
+
+	switch sub {
+	case "help", "-h", "--help":
+		writeArtifactHelp(stdout)
+		return exitOK
+
+	case "prune":
+		return runArtifactPrune(ctr, args, stdout, stderr)
+
+	case "diff":
+		return runArtifactDiff(ctr, globalArtifactDir, args, stdout, stderr)
+
+	case "show", "extract", "latest", "verify":
+	default:
+		log.Error("unknown artifact subcommand: " + sub)
+		writeArtifactHelp(stderr)
+		return exitUsage
+	}
+
+	fs := flag.NewFlagSet("artifact "+sub, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var tool, dir, root string
+	var jsonOut bool
+	var part, selectExpr string
+	var raw bool
+	fs.StringVar(&tool, "tool", "", "Tool whose latest artifact to use (e.g. MEGADIAG or diagnose).")
+	fs.StringVar(&dir, "dir", "", "Directory holding the artifacts and *_latest.txt pointers (default: current directory).")
+	switch sub {
+	case "show":
+		fs.BoolVar(&jsonOut, "json", false, "Output JSON instead of text.")
+	case "verify":
+		fs.StringVar(&root, "root", "", "Verify against this directory instead of the root recorded in the manifest.")
+		fs.BoolVar(&jsonOut, "json", false, "Output JSON instead of text.")
+	case "extract":
+		fs.StringVar(&part, "part", "", "Part to print: xml|json|prompt|manifest (default with --select: json).")
+		fs.StringVar(&selectExpr, "select", "", "jq-style path into the JSON part, e.g. '.files[].relPath'.")
+		fs.BoolVar(&raw, "raw", false, "Print selected strings without JSON quotes.")
+	}
+	fs.Usage = func() { writeArtifactHelp(stderr) }
+
+	leadingPos, flagArgs := splitLeadingPositionals(args)
+	argsToParse := args
+	if len(leadingPos) > 0 {
+		argsToParse = flagArgs
+	}
+	if err := fs.Parse(argsToParse); err != nil {
+		writeArtifactHelp(stderr)
+		log.Error(fmt.Sprintf("failed to parse artifact %s flags: %v", sub, err))
+		return exitUsage
+	}
+
+	paths := append(leadingPos, fs.Args()...)
+	if len(paths) > 1 || (sub == "latest" && len(paths) > 0) {
+		log.Error("artifact " + sub + ": too many positional arguments")
+		writeArtifactHelp(stderr)
+		return exitUsage
+	}
+	if len(paths) == 0 && strings.TrimSpace(tool) == "" {
+		log.Error("artifact " + sub + ": pass an artifact file or --tool")
+		return exitUsage
+	}
+
+	ref := artifactapp.Ref{Tool: tool, Dir: resolveRootPathFromInvocation(dir)}
+	if len(paths) == 1 {
+		ref.Path = resolveRootPathFromInvocation(paths[0])
+	}
+
+	switch sub {
+	case "latest":
+		path, err := ctr.Artifact.Latest(ref.Dir, ref.Tool)
+		if err != nil {
+			log.Error(err.Error())
+			return exitError
+		}
+		_, _ = io.WriteString(stdout, path+"\n")
+		return exitOK
+
+	case "show":
+		res, err := ctr.Artifact.Show(ref)
+		if err != nil {
+			log.Error(err.Error())
+			return exitError
+		}
+		if jsonOut {
+			b, _ := json.MarshalIndent(res, "", "  ")
+			_, _ = io.WriteString(stdout, string(b)+"\n")
+			return exitOK
+		}
+		var b strings.Builder
+		b.WriteString("path:        " + res.Path + "\n")
+		b.WriteString("tool:        " + res.Tool + "\n")
+		b.WriteString("contract:    " + res.Contract + "\n")
+		b.WriteString("generatedAt: " + res.GeneratedAt + "\n")
+		if res.Format != "" {
+			b.WriteString("format:      " + res.Format + "\n")
+		}
+		b.WriteString("parts:\n")
+		for _, p := range res.Parts {
+			b.WriteString(fmt.Sprintf("  %-7s %d bytes, %d lines\n", p.Name, p.Bytes, p.Lines))
+		}
+		if len(res.JSONKeys) > 0 {
+			b.WriteString("json keys:   " + strings.Join(res.JSONKeys, ", ") + "\n")
+		}
+		if m := res.Manifest; m != nil {
+			b.WriteString(fmt.Sprintf("manifest:    %d file(s), megamake %s\n", m.Files, m.MegamakeVersion))
+			if m.GitHead != "" {
+				b.WriteString("git:         " + m.GitHead + dirtySuffix(m.GitDirty) + "\n")
+			}
+		}
+		_, _ = io.WriteString(stdout, b.String())
+		return exitOK
+
+	case "verify":
+		req := artifactapp.VerifyRequest{Ref: ref}
+		if strings.TrimSpace(root) != "" {
+			req.RootPath = resolveRootPathFromInvocation(root)
+		}
+		res, err := ctr.Artifact.Verify(req)
+		if err != nil {
+			log.Error(err.Error())
+			return exitError
+		}
+		if jsonOut {
+			b, _ := json.MarshalIndent(res, "", "  ")
+			_, _ = io.WriteString(stdout, string(b)+"\n")
+		} else {
+			_, _ = io.WriteString(stdout, formatVerifyResult(res))
+		}
+		if res.Drift {
+			return exitError
+		}
+		return exitOK
+
+	default: // extract
+		out, err := ctr.Artifact.Extract(artifactapp.ExtractRequest{
+			Ref:    ref,
+			Part:   part,
+			Select: selectExpr,
+			Raw:    raw,
+		})
+		if err != nil {
+			log.Error(err.Error())
+			return exitError
+		}
+		_, _ = io.WriteString(stdout, out)
+		return exitOK
+	}
+}
+
+// runArtifactPrune removes old artifacts from a directory. [artifact] keep / older-than in
+// the project config found from --dir supply defaults, as for the other commands.
+func runArtifactPrune(ctr wiring.Container, argv []string, stdout io.Writer, stderr io.Writer) int {
+	log := console.New(stderr)
+
+	fs := flag.NewFlagSet("artifact prune", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var tool, dir, olderThan string
+	var keep int
+	var dryRun, jsonOut bool
+	fs.StringVar(&tool, "tool", "", "Only prune this tool's artifacts (default: every tool).")
+	fs.StringVar(&dir, "dir", "", "Directory holding the artifacts (default: current directory).")
+	fs.IntVar(&keep, "keep", 0, "Keep the newest N artifacts per tool.")
+	fs.StringVar(&olderThan, "older-than", "", "Remove artifacts older than this age, e.g. 7d, 2w, 36h.")
+	fs.BoolVar(&dryRun, "dry-run", false, "List what would be removed without removing anything.")
+	fs.BoolVar(&jsonOut, "json", false, "Output JSON instead of text.")
+	fs.Usage = func() { writeArtifactHelp(stderr) }
+
+	if err := fs.Parse(argv); err != nil {
+		writeArtifactHelp(stderr)
+		log.Error(fmt.Sprintf("failed to parse artifact prune flags: %v", err))
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		log.Error("artifact prune: unexpected positional arguments (use --dir)")
+		writeArtifactHelp(stderr)
+		return exitUsage
+	}
+
+	dir = resolveRootPathFromInvocation(dir)
+	if err := applyProjectConfig(fs, "artifact", dir, log); err != nil {
+		log.Error(err.Error())
+		return exitUsage
+	}
+
+	res, err := ctr.Artifact.Prune(artifactapp.PruneRequest{
+		Dir:       dir,
+		Tool:      tool,
+		Keep:      keep,
+		OlderThan: olderThan,
+		DryRun:    dryRun,
+	})
+	if err != nil && len(res.Removed) == 0 && res.Kept == 0 {
+		log.Error(err.Error())
+		return exitError
+	}
+
+	if jsonOut {
+		b, _ := json.MarshalIndent(res, "", "  ")
+		_, _ = io.WriteString(stdout, string(b)+"\n")
+	} else {
+		verb := "removed"
+		if res.DryRun {
+			verb = "would remove"
+		}
+		var b strings.Builder
+		for _, r := range res.Removed {
+			for _, f := range r.Files {
+				b.WriteString(verb + " " + f + "\n")
+			}
+		}
+		b.WriteString(fmt.Sprintf("%s %d artifact(s), %d bytes; kept %d\n", verb, len(res.Removed), res.Bytes, res.Kept))
+		_, _ = io.WriteString(stdout, b.String())
+	}
+	if err != nil {
+		log.Error(err.Error())
+		return exitError
+	}
+	return exitOK
+}
+
+// runArtifactDiff compares two artifacts of the same tool and writes a MEGADIFF artifact.
+// Without head, base is compared with the latest artifact of its tool in --dir.
+func runArtifactDiff(ctr wiring.Container, globalArtifactDir string, argv []string, stdout io.Writer, stderr io.Writer) int {
+	log := console.New(stderr)
+
+	leadingPos, flagArgs := splitLeadingPositionals(argv)
+
+	fs := flag.NewFlagSet("artifact diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var dir string
+	var jsonOut bool
+	showSummary := true
+	fs.StringVar(&dir, "dir", "", "Where to find the latest artifact when head is omitted (default: current directory).")
+	fs.BoolVar(&jsonOut, "json", false, "Print the JSON delta instead of the XML view.")
+	fs.BoolVar(&showSummary, "summary", true, "Print a short summary to stderr.")
+	fs.Usage = func() { writeArtifactHelp(stderr) }
+
+	argsToParse := argv
+	if len(leadingPos) > 0 {
+		argsToParse = flagArgs
+	}
+	if err := fs.Parse(argsToParse); err != nil {
+		writeArtifactHelp(stderr)
+		log.Error(fmt.Sprintf("failed to parse artifact diff flags: %v", err))
+		return exitUsage
+	}
+
+	paths := append(leadingPos, fs.Args()...)
+	if len(paths) == 0 || len(paths) > 2 {
+		log.Error("artifact diff: expected <base> [head]")
+		writeArtifactHelp(stderr)
+		return exitUsage
+	}
+
+	req := artifactapp.DiffRequest{
+		Base:        artifactapp.Ref{Path: resolveRootPathFromInvocation(paths[0])},
+		Head:        artifactapp.Ref{Dir: resolveRootPathFromInvocation(dir)},
+		ArtifactDir: artifactDirForLocalTools(globalArtifactDir, log),
+		Args:        nil,
+	}
+	if len(paths) == 2 {
+		req.Head.Path = resolveRootPathFromInvocation(paths[1])
+	}
+
+	res, err := ctr.Artifact.Diff(req)
+	if err != nil {
+		log.Error(err.Error())
+		return exitError
+	}
+
+	out := res.ReportXML
+	if jsonOut {
+		out = res.ReportJSON
+	}
+	if _, err := io.WriteString(stdout, out+"\n"); err != nil {
+		log.Error(fmt.Sprintf("failed writing to stdout: %v", err))
+		return exitError
+	}
+
+	if showSummary {
+		s := res.Report.Summary
+		log.Info("mode: artifact diff (" + res.Report.ToolPrefix + ")")
+		log.Info("base: " + res.Report.Base.Path)
+		log.Info("head: " + res.Report.Head.Path)
+		log.Info("artifact: " + res.ArtifactPath)
+		log.Info("latest pointer: " + res.LatestPath)
+		log.Info(fmt.Sprintf("added: %d, removed: %d, changed: %d, unchanged: %d", s.Added, s.Removed, s.Changed, s.Unchanged))
+		for _, w := range res.Report.Warnings {
+			log.Warn(w)
+		}
+	}
+	return exitOK
+}
+
+func formatVerifyResult(res artifactapp.VerifyResult) string {
+	var b strings.Builder
+	b.WriteString("artifact:    " + res.Path + "\n")
+	b.WriteString("generatedAt: " + res.GeneratedAt + " by megamake " + res.MegamakeVersion + "\n")
+	b.WriteString("root:        " + res.RootPath + "\n")
+	if g := res.Git; g != nil {
+		b.WriteString("git:         recorded " + g.RecordedHead + dirtySuffix(g.RecordedDirty))
+		switch {
+		case g.CurrentHead == "":
+			b.WriteString(", now not a git work tree\n")
+		case g.HeadMoved:
+			b.WriteString(", now " + g.CurrentHead + dirtySuffix(g.CurrentDirty) + "\n")
+		default:
+			b.WriteString(", unchanged" + dirtySuffix(g.CurrentDirty) + "\n")
+		}
+	}
+	for _, m := range res.Modified {
+		b.WriteString(fmt.Sprintf("modified  %s (%d -> %d bytes)\n", m.Path, m.RecordedBytes, m.CurrentBytes))
+	}
+	for _, p := range res.Missing {
+		b.WriteString("missing   " + p + "\n")
+	}
+	status := "OK"
+	if res.Drift {
+		status = "DRIFT"
+	}
+	b.WriteString(fmt.Sprintf("%s: %d file(s), %d unchanged, %d modified, %d missing\n", status, res.Files, res.Unchanged, len(res.Modified), len(res.Missing)))
+	return b.String()
+}
+
+func dirtySuffix(dirty bool) string {
+	if dirty {
+		return " (dirty)"
+	}
+	return ""
+}
+
+func writeArtifactHelp(w io.Writer) {
+	help := strings.TrimSpace(`
+megamake artifact show    [file] [flags]
+megamake artifact extract [file] --part xml|json|prompt|manifest [flags]
+megamake artifact latest  --tool <tool> [--dir <dir>]
+megamake artifact prune   [--keep N] [--older-than AGE] [flags]
+megamake artifact diff    <base> [head] [flags]
+megamake artifact verify  [file] [flags]
+
+Reads MEGA* artifacts back: show prints the header and part sizes, extract prints one
+part (optionally a jq-style selection from the JSON part), latest prints the path the
+tool's *_latest.txt pointer names. Without a file, show/extract use that latest artifact.
+prune removes old timestamped artifacts (and their _partNN files). diff compares two
+artifacts of the same tool and writes the delta as a MEGADIFF artifact. verify checks
+that the files an artifact was built from are unchanged (exit 1 when they drifted).
+
+Flags:
+  --tool <tool>       MEGA* prefix or command name: MEGAPROMPT|prompt, MEGADOC|doc,
+                      MEGADIAG|diagnose, MEGATEST|test, MEGASECURE|secure,
+                      MEGAPATCH|patch, MEGAMAKE|make.
+  --dir <dir>         Where to look for *_latest.txt (default: current directory).
+  --part <part>       extract: xml|json|prompt|manifest (default with --select: json).
+  --select <expr>     extract: jq-style path into the JSON (or manifest) part. Supported: '.', '.a.b',
+                      '.["key"]', '.items[0]', '.items[-1]', '.items[]' and '|'.
+                      Each result is printed on its own line (objects indented).
+  --raw               extract: print selected strings without quotes (like jq -r).
+  --keep N            prune: keep the newest N artifacts per tool.
+  --older-than AGE    prune: remove artifacts older than AGE (7d, 2w, 36h, 90m), judged by
+                      the timestamp in the filename. With --keep too, an artifact is removed
+                      only if it is outside the newest N and older than AGE.
+  --dry-run           prune: list what would be removed without removing anything.
+  --json              show, verify, prune: output JSON instead of text; diff: print the
+                      JSON delta instead of the XML view.
+  --summary=false     diff: no summary on stderr.
+  --root <dir>        verify: check files under this directory instead of the root the
+                      manifest recorded (e.g. another checkout of the same project).
+
+Diff:
+  - base is the older artifact; without head, it is compared with the latest artifact
+    of the same tool in --dir.
+  - MEGADIAG: new and fixed diagnostics (matched on tool, file, code, severity and
+    message, so issues that only moved lines are unchanged).
+  - MEGATEST: newly uncovered subjects (new, or lost coverage) and newly covered ones.
+  - MEGAPROMPT: files added to, removed from, or resized in the context.
+  - The MEGADIFF artifact's prompt is scoped to the delta, e.g. "fix these new
+    diagnostics"; it is written to the current directory like other tool artifacts.
+
+Provenance:
+  - Artifacts that read project files carry a manifest: the SHA-256 and size of each file,
+    git HEAD and whether the work tree was dirty, and the megamake version.
+  - verify rehashes those files: modified and missing files are drift; a moved HEAD alone
+    is reported but is not drift. Artifacts written before manifests existed are refused.
+
+Retention:
+  - prune never removes the artifact a *_latest.txt pointer names.
+  - Without --tool, prune applies to every MEGA* tool in --dir, per tool.
+  - keep = N / older-than = "AGE" in the [artifact] section of megamake.toml or
+    .megamake.json (found from the artifact directory upwards) set prune defaults and
+    also prune automatically after every artifact a tool writes there.
+
+Examples:
+  megamake artifact latest --tool diagnose
+  megamake artifact extract --tool MEGADIAG --part prompt
+  megamake artifact extract --tool prompt --select '.files[].relPath' --raw
+  megamake artifact show MEGATEST_20250101_120000Z.txt
+  megamake artifact prune --keep 10 --dry-run
+  megamake artifact prune --tool prompt --older-than 7d
+  megamake artifact diff MEGADIAG_20250101_120000Z.txt          # vs. the latest MEGADIAG
+  megamake artifact diff MEGATEST_20250101_120000Z.txt MEGATEST_20250102_120000Z.txt
+  megamake artifact verify --tool prompt
+  megamake artifact extract --tool MEGATEST --part manifest --select '.files[].path' --raw
+`)
+	_, _ = io.WriteString(w, help+"\n")
+}
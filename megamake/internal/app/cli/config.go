@@ -0,0 +1,267 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/megamake/megamake/internal/platform/config"
+	"github.com/megamake/megamake/internal/platform/console"
+)
+
+// configCommands are the commands that read defaults from megamake.toml/.megamake.json,
+// each from the section of the same name ("doc" applies to doc create; "artifact" to
+// artifact prune and automatic artifact retention).
+var configCommands = []string{"prompt", "doc", "diagnose", "test", "secure", "artifact"}
+
+// applyProjectConfig loads the project config found from rootPath upwards and applies the
+// command's settings to fs as defaults: flags given on the command line win, except
+// "ignore", whose configured entries are always added. Top-level keys apply only to
+// commands that have such a flag; an unknown key in the command's own section is an error.
+func applyProjectConfig(fs *flag.FlagSet, command string, rootPath string, log console.Logger) error {
+	cfg, err := config.Load(rootPath)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Files) == 0 {
+		return nil
+	}
+
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	for _, e := range cfg.Effective(command) {
+		f := fs.Lookup(e.Key)
+		if f == nil {
+			if e.FromSection {
+				return fmt.Errorf("config: %s: unknown %s flag --%s", e.Value.Source, command, e.Key)
+			}
+			continue
+		}
+		if explicit[e.Key] && e.Key != "ignore" {
+			continue
+		}
+		_, repeatable := f.Value.(*stringListFlag)
+		if e.Value.List && !repeatable {
+			return fmt.Errorf("config: %s: --%s takes a single value, not a list", e.Value.Source, e.Key)
+		}
+		for _, v := range e.Value.Values {
+			if err := f.Value.Set(v); err != nil {
+				return fmt.Errorf("config: %s: invalid value %q for --%s: %v", e.Value.Source, v, e.Key, err)
+			}
+		}
+	}
+
+	log.Info("config: " + strings.Join(cfg.Files, ", "))
+	return nil
+}
+
+// runConfig implements:
+//
+//	megamake config show [path] [--command NAME] [--json]
+//
+// It prints the merged project configuration for each command and where every value
+// came from. Flags not listed keep their built-in defaults.
+func runConfig(argv []string, stdout io.Writer, stderr io.Writer) int {
+	log := console.New(stderr)
+
+	if len(argv) == 0 {
+		writeConfigHelp(stderr)
+		return exitUsage
+	}
+
+	sub := argv[0]
+	args := argv[1:]
+
+	switch sub {
+	case "help", "-h", "--help":
+		writeConfigHelp(stdout)
+		return exitOK
+
+	case "show":
+		fs := flag.NewFlagSet("config show", flag.ContinueOnError)
+		fs.SetOutput(stderr)
+
+		var command string
+		var jsonOut bool
+		fs.StringVar(&command, "command", "", "Only show the settings for this command (prompt|doc|diagnose|test|secure|artifact).")
+		fs.BoolVar(&jsonOut, "json", false, "Output JSON instead of TOML-style text.")
+		fs.Usage = func() { writeConfigHelp(stderr) }
+
+		leadingPos, flagArgs := splitLeadingPositionals(args)
+		argsToParse := args
+		if len(leadingPos) > 0 {
+			argsToParse = flagArgs
+		}
+		if err := fs.Parse(argsToParse); err != nil {
+			writeConfigHelp(stderr)
+			log.Error(fmt.Sprintf("failed to parse config show flags: %v", err))
+			return exitUsage
+		}
+
+		paths := append(leadingPos, fs.Args()...)
+		if len(paths) > 1 {
+			log.Error("config show: expected at most one positional path")
+			writeConfigHelp(stderr)
+			return exitUsage
+		}
+		rootPath := "."
+		if len(paths) == 1 {
+			rootPath = paths[0]
+		}
+		rootPath = resolveRootPathFromInvocation(rootPath)
+
+		commands := configCommands
+		if command != "" {
+			known := false
+			for _, c := range configCommands {
+				known = known || c == command
+			}
+			if !known {
+				log.Error("config show: unknown --command " + command + " (want prompt|doc|diagnose|test|secure|artifact)")
+				return exitUsage
+			}
+			commands = []string{command}
+		}
+
+		cfg, err := config.Load(rootPath)
+		if err != nil {
+			log.Error(err.Error())
+			return exitError
+		}
+		for _, name := range cfg.SectionNames() {
+			if !containsString(configCommands, name) {
+				log.Warn("config: section [" + name + "] is not used by any command")
+			}
+		}
+
+		if jsonOut {
+			type value struct {
+				Value  any    `json:"value"`
+				Source string `json:"source"`
+			}
+			out := struct {
+				Root     string                      `json:"root"`
+				Files    []string                    `json:"files"`
+				Commands map[string]map[string]value `json:"commands"`
+			}{Root: rootPath, Files: cfg.Files, Commands: map[string]map[string]value{}}
+			if out.Files == nil {
+				out.Files = []string{}
+			}
+			for _, c := range commands {
+				m := map[string]value{}
+				for _, e := range cfg.Effective(c) {
+					var v any = e.Value.Values
+					if !e.Value.List && len(e.Value.Values) == 1 {
+						v = e.Value.Values[0]
+					}
+					m[e.Key] = value{Value: v, Source: e.Value.Source}
+				}
+				out.Commands[c] = m
+			}
+			b, _ := json.MarshalIndent(out, "", "  ")
+			_, _ = io.WriteString(stdout, string(b)+"\n")
+			return exitOK
+		}
+
+		var b strings.Builder
+		b.WriteString("# root: " + rootPath + "\n")
+		if len(cfg.Files) == 0 {
+			b.WriteString("# no megamake.toml or .megamake.json found; built-in defaults apply\n")
+			_, _ = io.WriteString(stdout, b.String())
+			return exitOK
+		}
+		b.WriteString("# config files (outermost first; nearer files win):\n")
+		for _, f := range cfg.Files {
+			b.WriteString("#   " + f + "\n")
+		}
+		for _, c := range commands {
+			b.WriteString("\n[" + c + "]\n")
+			entries := cfg.Effective(c)
+			if len(entries) == 0 {
+				b.WriteString("# (built-in defaults)\n")
+			}
+			for _, e := range entries {
+				b.WriteString(e.Key + " = " + formatConfigValue(e.Value) + "  # " + e.Value.Source + "\n")
+			}
+		}
+		_, _ = io.WriteString(stdout, b.String())
+		return exitOK
+
+	default:
+		log.Error("unknown config subcommand: " + sub)
+		writeConfigHelp(stderr)
+		return exitUsage
+	}
+}
+
+// formatConfigValue renders a value TOML-style: integers and booleans bare, strings quoted.
+func formatConfigValue(v config.Value) string {
+	quote := func(s string) string {
+		if s == "true" || s == "false" {
+			return s
+		}
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return s
+		}
+		return strconv.Quote(s)
+	}
+	if !v.List && len(v.Values) == 1 {
+		return quote(v.Values[0])
+	}
+	parts := make([]string, 0, len(v.Values))
+	for _, s := range v.Values {
+		parts = append(parts, quote(s))
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func containsString(xs []string, s string) bool {
+	for _, x := range xs {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func writeConfigHelp(w io.Writer) {
+	help := strings.TrimSpace(`
+megamake config show [path] [flags]
+
+Print the effective project configuration: the megamake.toml / .megamake.json files found
+from path (default: current directory) up to the filesystem root, merged, per command,
+with the file and line each value came from.
+
+Flags:
+  --command <name>   Only show one command: prompt|doc|diagnose|test|secure|artifact.
+  --json             Output JSON instead of TOML-style text.
+
+Config files:
+  - Keys are command flag names without "--"; [prompt], [doc] (doc create), [diagnose],
+    [test], [secure] and [artifact] (artifact prune) sections apply to one command,
+    top-level keys to every command that has the flag.
+  - [artifact] keep / older-than also prune automatically after each artifact a tool
+    writes to a directory under that config (see: megamake artifact help).
+  - Nearer files override farther ones per key; "ignore" lists from all files add up.
+  - Flags given on the command line override the config ("ignore" entries are added).
+  - One directory may contain only one of megamake.toml and .megamake.json.
+
+Example megamake.toml:
+  ignore = ["vendor", "testdata/**"]
+
+  [prompt]
+  format = "markdown"
+  max-tokens = 120000
+
+  [diagnose]
+  timeout-seconds = 300
+
+  [artifact]
+  keep = 20
+`)
+	_, _ = io.WriteString(w, help+"\n")
+}
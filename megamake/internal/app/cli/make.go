@@ -0,0 +1,279 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/megamake/megamake/internal/app/wiring"
+	"github.com/megamake/megamake/internal/platform/console"
+	"github.com/megamake/megamake/internal/platform/policy"
+
+	contractworkflow "github.com/megamake/megamake/internal/contracts/v1/workflow"
+	workflowapp "github.com/megamake/megamake/internal/domains/workflow/app"
+	workflowdomain "github.com/megamake/megamake/internal/domains/workflow/domain"
+)
+
+// runMake implements:
+//
+//	megamake make [workflow-file] [--root DIR] [--dry-run]
+//
+// It runs the steps declared in a workflow file (default: megamake.workflow.yaml in the
+// invocation directory) and writes one MEGAMAKE summary artifact next to the per-step artifacts.
+func runMake(ctr wiring.Container, pol policy.Policy, globalArtifactDir string, argv []string, stdout io.Writer, stderr io.Writer) int {
+	log := console.New(stderr)
+
+	leadingPos, flagArgs := splitLeadingPositionals(argv)
+
+	fs := flag.NewFlagSet("make", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var rootPath string
+	var dryRun bool
+	var jsonOut string
+	var promptOut string
+	var showSummary bool
+	var ignores stringListFlag
+
+	fs.StringVar(&rootPath, "root", "", "Project root for all steps (overrides the workflow's root).")
+	fs.BoolVar(&dryRun, "dry-run", false, "Validate the workflow and list its steps without running them.")
+	fs.Var(&ignores, "ignore", "Directory names or glob paths to ignore in every step (repeatable).")
+	fs.Var(&ignores, "I", "Alias for --ignore (repeatable).")
+	fs.StringVar(&jsonOut, "json-out", "", "Write JSON report to this path (optional).")
+	fs.StringVar(&promptOut, "prompt-out", "", "Write the run summary prompt to this path (optional).")
+	fs.BoolVar(&showSummary, "show-summary", true, "Print step progress and a brief summary to stderr.")
+
+	fs.Usage = func() { writeMakeHelp(stderr) }
+
+	argsToParse := argv
+	if len(leadingPos) > 0 {
+		argsToParse = flagArgs
+	}
+	if err := fs.Parse(argsToParse); err != nil {
+		writeMakeHelp(stderr)
+		log.Error(fmt.Sprintf("failed to parse make flags: %v", err))
+		return exitUsage
+	}
+
+	workflowPath := ""
+	if len(leadingPos) > 0 {
+		if len(leadingPos) != 1 {
+			log.Error("make: expected at most one workflow file")
+			writeMakeHelp(stderr)
+			return exitUsage
+		}
+		workflowPath = leadingPos[0]
+	} else {
+		rest := fs.Args()
+		if len(rest) >= 1 {
+			workflowPath = rest[0]
+			rest = rest[1:]
+		}
+		if len(rest) > 0 {
+			log.Error("make: unexpected extra arguments: " + strings.Join(rest, " "))
+			writeMakeHelp(stderr)
+			return exitUsage
+		}
+	}
+
+	if strings.TrimSpace(workflowPath) == "" {
+		workflowPath = findDefaultWorkflow(invocationCWD())
+		if workflowPath == "" {
+			log.Error("make: no workflow file given and none found (looked for: " + strings.Join(workflowdomain.DefaultWorkflowFiles, ", ") + ")")
+			return exitUsage
+		}
+	}
+	workflowPath = resolveRootPathFromInvocation(workflowPath)
+
+	data, err := os.ReadFile(workflowPath)
+	if err != nil {
+		log.Error(fmt.Sprintf("make: failed to read workflow: %v", err))
+		return exitError
+	}
+
+	spec, err := ctr.Workflow.Validate(data, workflowPath)
+	if err != nil {
+		log.Error(err.Error())
+		return exitError
+	}
+
+	if dryRun {
+		log.Info("workflow: " + workflowPath + " (valid)")
+		for i, st := range spec.Steps {
+			line := itoa(i+1) + ". " + st.ID + " [" + st.Type + "]"
+			if strings.TrimSpace(st.If) != "" {
+				line += " if: " + st.If
+			}
+			if st.Retries > 0 {
+				line += " retries: " + itoa(st.Retries)
+			}
+			if st.ContinueOnError {
+				line += " continueOnError"
+			}
+			if _, err := io.WriteString(stdout, line+"\n"); err != nil {
+				log.Error(fmt.Sprintf("failed writing to stdout: %v", err))
+				return exitError
+			}
+		}
+		return exitOK
+	}
+
+	// --root wins; otherwise the workflow's "root" is relative to the workflow file.
+	workflowDir := filepath.Dir(workflowPath)
+	if strings.TrimSpace(rootPath) != "" {
+		rootPath = resolveRootPathFromInvocation(rootPath)
+	} else if r := strings.TrimSpace(spec.Root); r != "" && !filepath.IsAbs(r) {
+		rootPath = filepath.Clean(filepath.Join(workflowDir, filepath.FromSlash(r)))
+	} else if r != "" {
+		rootPath = r
+	} else {
+		rootPath = workflowDir
+	}
+
+	artifactRoot := artifactDirForLocalTools(globalArtifactDir, log)
+	ignoreNames, ignoreGlobs := splitIgnores(ignores.values)
+	ignoreGlobs = append(ignoreGlobs, defaultLocalArtifactsIgnoreGlobs(rootPath)...)
+	ignoreNames = dedupeStrings(ignoreNames)
+	ignoreGlobs = dedupeStrings(ignoreGlobs)
+
+	var onStep func(contractworkflow.StepResultV1)
+	if showSummary {
+		log.Info("workflow: " + workflowPath)
+		onStep = func(st contractworkflow.StepResultV1) {
+			line := "step " + st.ID + " [" + string(st.Type) + "]: " + string(st.Status)
+			switch st.Status {
+			case contractworkflow.StepSkipped:
+				log.Info(line + " (" + st.SkipReason + ")")
+			case contractworkflow.StepFailed:
+				log.Error(line + ": " + st.Error)
+			default:
+				if st.Artifact != "" {
+					line += " -> " + st.Artifact
+				}
+				log.Info(line)
+			}
+		}
+	}
+
+	res, err := ctr.Workflow.Run(workflowapp.RunRequest{
+		RootPath:        rootPath,
+		ArtifactDir:     artifactRoot,
+		ChatArtifactDir: computeArtifactDir(globalArtifactDir, ""),
+		Workflow:        data,
+		WorkflowFile:    workflowPath,
+		WorkflowDir:     workflowDir,
+		IgnoreNames:     ignoreNames,
+		IgnoreGlobs:     ignoreGlobs,
+		NetEnabled:      pol.NetEnabled,
+		AllowDomains:    pol.AllowDomains,
+		AllowDelete:     pol.AllowDelete,
+		Args:            nil,
+		OnStep:          onStep,
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return exitError
+	}
+
+	if _, err := io.WriteString(stdout, res.ReportXML+"\n"); err != nil {
+		log.Error(fmt.Sprintf("failed writing to stdout: %v", err))
+		return exitError
+	}
+
+	if jsonOut != "" {
+		if err := os.WriteFile(jsonOut, []byte(res.ReportJSON+"\n"), 0o644); err != nil {
+			log.Error(fmt.Sprintf("failed writing --json-out: %v", err))
+			return exitError
+		}
+	}
+	if promptOut != "" {
+		if err := os.WriteFile(promptOut, []byte(res.AgentPrompt+"\n"), 0o644); err != nil {
+			log.Error(fmt.Sprintf("failed writing --prompt-out: %v", err))
+			return exitError
+		}
+	}
+
+	if showSummary {
+		s := res.Report.Summary
+		log.Info("mode: make")
+		log.Info("root: " + res.Report.RootPath)
+		log.Info("artifact: " + res.ArtifactPath)
+		log.Info("latest pointer: " + res.LatestPath)
+		log.Info("steps: " + itoa(s.Total) + " (ok: " + itoa(s.OK) + ", failed: " + itoa(s.Failed) + ", skipped: " + itoa(s.Skipped) + ")")
+		for _, w := range res.Report.Warnings {
+			log.Warn(w)
+		}
+	}
+
+	if res.Report.Status != contractworkflow.WorkflowOK {
+		log.Error("make: workflow failed")
+		return exitError
+	}
+	return exitOK
+}
+
+// findDefaultWorkflow returns the first default workflow file present in dir, or "".
+func findDefaultWorkflow(dir string) string {
+	for _, name := range workflowdomain.DefaultWorkflowFiles {
+		p := filepath.Join(dir, name)
+		if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+			return p
+		}
+	}
+	return ""
+}
+
+func writeMakeHelp(w io.Writer) {
+	help := strings.TrimSpace(`
+megamake make [workflow-file] [flags]
+megamake make [flags] [workflow-file]
+
+Runs a declarative workflow: a list of megamake steps (prompt, doc, diagnose, test,
+secure, chat, patch) with conditions, retries and outputs passed between steps.
+Writes a MEGAMAKE summary artifact; each step also writes its own artifact.
+
+If no file is given, looks for megamake.workflow.yaml, megamake.workflow.yml or
+megamake.workflow.json in the current directory.
+
+Flags:
+  --root DIR                  Project root for all steps (default: workflow "root", else the workflow's directory).
+  --dry-run                   Validate the workflow and list its steps without running them.
+  --ignore X / -I X           Ignore directory name OR path/glob in every step (repeatable).
+  --json-out PATH
+  --prompt-out PATH
+  --show-summary=true|false
+
+Workflow file (YAML subset or JSON):
+  version: 1
+  name: check
+  steps:
+    - id: diag
+      type: diagnose
+      with:
+        timeoutSeconds: 300
+    - id: fix
+      type: chat
+      if: steps.diag.errors > 0
+      retries: 2
+      with:
+        message: "{{steps.diag.prompt}}"
+    - id: scan
+      type: secure
+      continueOnError: true
+
+Step fields:
+  id, type, if, retries, retryDelaySeconds, continueOnError, with
+  - "if" supports ==, !=, <, <=, >, >=, &&, ||, ! and parentheses over step outputs.
+  - Strings in "with" may reference earlier steps: {{steps.<id>.<output>}}.
+  - Every step exposes: status, error, artifact, attempts.
+
+Notes:
+  - Network (--net/--allow-domain) and delete (--allow-delete) consent apply to all steps.
+  - A failed step stops the run unless it sets continueOnError; later steps are skipped.
+  - Exit code is 1 if the workflow failed.
+`)
+	_, _ = io.WriteString(w, help+"\n")
+}
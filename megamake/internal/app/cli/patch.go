@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/megamake/megamake/internal/app/wiring"
+	"github.com/megamake/megamake/internal/platform/console"
+	"github.com/megamake/megamake/internal/platform/policy"
+
+	contractpatch "github.com/megamake/megamake/internal/contracts/v1/patch"
+	patchapp "github.com/megamake/megamake/internal/domains/patch/app"
+)
+
+// runPatch implements:
+//
+//	megamake patch [script|-] [--root DIR] [--dry-run]
+//
+// The script is read from the given path, or from stdin when the path is "-" or omitted.
+// File deletes additionally require the global --allow-delete consent flag.
+func runPatch(ctr wiring.Container, pol policy.Policy, globalArtifactDir string, argv []string, stdout io.Writer, stderr io.Writer) int {
+	log := console.New(stderr)
+
+	leadingPos, flagArgs := splitLeadingPositionals(argv)
+
+	fs := flag.NewFlagSet("patch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var rootPath string
+	var dryRun bool
+	var jsonOut string
+	var promptOut string
+	var showSummary bool
+
+	fs.StringVar(&rootPath, "root", ".", "Project root the patch paths are relative to.")
+	fs.BoolVar(&dryRun, "dry-run", false, "Parse and preview diffs without writing any files.")
+	fs.StringVar(&jsonOut, "json-out", "", "Write JSON report to this path (optional).")
+	fs.StringVar(&promptOut, "prompt-out", "", "Write agent prompt text to this path (optional).")
+	fs.BoolVar(&showSummary, "show-summary", true, "Print a brief summary to stderr.")
+	fs.Usage = func() { writePatchHelp(stderr) }
+
+	argsToParse := argv
+	if len(leadingPos) > 0 {
+		argsToParse = flagArgs
+	}
+	if err := fs.Parse(argsToParse); err != nil {
+		writePatchHelp(stderr)
+		log.Error(fmt.Sprintf("failed to parse patch flags: %v", err))
+		return exitUsage
+	}
+
+	scriptPath := "-"
+	if len(leadingPos) > 0 {
+		if len(leadingPos) != 1 {
+			log.Error("patch: expected at most one positional script path")
+			writePatchHelp(stderr)
+			return exitUsage
+		}
+		scriptPath = leadingPos[0]
+	} else {
+		rest := fs.Args()
+		if len(rest) >= 1 {
+			scriptPath = rest[0]
+			rest = rest[1:]
+		}
+		if len(rest) > 0 {
+			log.Error("patch: unexpected extra arguments: " + strings.Join(rest, " "))
+			writePatchHelp(stderr)
+			return exitUsage
+		}
+	}
+
+	var script []byte
+	var err error
+	source := "stdin"
+	if scriptPath == "-" {
+		script, err = io.ReadAll(os.Stdin)
+	} else {
+		source = resolveRootPathFromInvocation(scriptPath)
+		script, err = os.ReadFile(source)
+	}
+	if err != nil {
+		log.Error(fmt.Sprintf("patch: failed to read script: %v", err))
+		return exitError
+	}
+
+	rootPath = resolveRootPathFromInvocation(rootPath)
+	artifactRoot := artifactDirForLocalTools(globalArtifactDir, log)
+
+	res, err := ctr.Patch.Apply(patchapp.ApplyRequest{
+		RootPath:     rootPath,
+		ArtifactDir:  artifactRoot,
+		Script:       string(script),
+		Source:       source,
+		DryRun:       dryRun,
+		AllowDelete:  pol.AllowDelete,
+		NetEnabled:   pol.NetEnabled,
+		AllowDomains: pol.AllowDomains,
+		Args:         nil,
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return exitError
+	}
+
+	if _, err := io.WriteString(stdout, res.ReportXML+"\n"); err != nil {
+		log.Error(fmt.Sprintf("failed writing to stdout: %v", err))
+		return exitError
+	}
+
+	if jsonOut != "" {
+		if err := os.WriteFile(jsonOut, []byte(res.ReportJSON+"\n"), 0o644); err != nil {
+			log.Error(fmt.Sprintf("failed writing --json-out: %v", err))
+			return exitError
+		}
+	}
+	if promptOut != "" {
+		if err := os.WriteFile(promptOut, []byte(res.AgentPrompt+"\n"), 0o644); err != nil {
+			log.Error(fmt.Sprintf("failed writing --prompt-out: %v", err))
+			return exitError
+		}
+	}
+
+	if showSummary {
+		s := res.Report.Summary
+		log.Info("mode: patch")
+		log.Info("root: " + rootPath)
+		log.Info("source: " + source)
+		log.Info("status: " + string(res.Report.Status))
+		log.Info("operations: " + itoa(s.Operations) + " (created: " + itoa(s.Created) + ", modified: " + itoa(s.Modified) + ", deleted: " + itoa(s.Deleted) + ", unchanged: " + itoa(s.Unchanged) + ")")
+		log.Info("artifact: " + res.ArtifactPath)
+		log.Info("latest pointer: " + res.LatestPath)
+		if len(res.Report.Warnings) > 0 {
+			log.Warn("warnings: " + itoa(len(res.Report.Warnings)) + " (see artifact for details)")
+		}
+	}
+
+	switch res.Report.Status {
+	case contractpatch.PatchStatusBlocked, contractpatch.PatchStatusRolledBack:
+		log.Error("patch: " + res.Report.Error)
+		return exitError
+	}
+	return exitOK
+}
+
+func writePatchHelp(w io.Writer) {
+	help := strings.TrimSpace(`
+megamake patch [script|-] [flags]
+megamake patch [flags] [script|-]
+
+Applies a MegaPatch v1 script (reads stdin when the script is "-" or omitted).
+All writes are applied atomically: if any file fails, every touched file is restored.
+
+Flags:
+  --root DIR                  Project root the patch paths are relative to (default: .).
+  --dry-run                   Parse and print unified diff previews; write nothing.
+  --json-out PATH             Write JSON report to PATH (optional).
+  --prompt-out PATH           Write agent prompt text to PATH (optional).
+  --show-summary=true|false   Print a brief summary to stderr (default: true).
+
+Consent:
+  - "delete" statements are blocked unless the global --allow-delete flag is set:
+      megamake --allow-delete patch fix.megapatch
+  - Directory deletes are never allowed.
+
+` + contractpatch.SyntaxV1 + `
+
+Defaults:
+  - Artifact output directory: current working directory (MEGAPATCH_*.txt).
+`)
+	_, _ = io.WriteString(w, help+"\n")
+}
@@ -15,6 +15,7 @@ import (
 	diagapp "github.com/megamake/megamake/internal/domains/diagnose/app"
 	docapp "github.com/megamake/megamake/internal/domains/doc/app"
 	promptapp "github.com/megamake/megamake/internal/domains/prompt/app"
+	promptdomain "github.com/megamake/megamake/internal/domains/prompt/domain"
 	tpapp "github.com/megamake/megamake/internal/domains/testplan/app"
 )
 
@@ -44,6 +45,19 @@ func (s *stringListFlag) Set(v string) error {
 	return nil
 }
 
+// includeRuleFlags are the scan include/exclude overrides shared by the scanning commands.
+type includeRuleFlags struct {
+	exts        stringListFlag
+	globs       stringListFlag
+	excludeExts stringListFlag
+}
+
+func (f *includeRuleFlags) register(fs *flag.FlagSet) {
+	fs.Var(&f.exts, "include-ext", "Also include files with this extension, e.g. vue or .proto (repeatable).")
+	fs.Var(&f.globs, "include-glob", "Always include files whose relpath matches this glob (repeatable).")
+	fs.Var(&f.excludeExts, "exclude-ext", "Exclude files with this extension, even if included by default (repeatable).")
+}
+
 func Run(argv []string) int {
 	stdout := os.Stdout
 	stderr := os.Stderr
@@ -57,9 +71,11 @@ func Run(argv []string) int {
 
 	var artifactDir string
 	var netEnabled bool
+	var allowDelete bool
 	global.StringVar(&artifactDir, "artifact-dir", "", "Directory where MEGA* artifacts and *_latest pointer files are written.")
 	global.BoolVar(&netEnabled, "net", false, "Enable network access (deny-by-default otherwise).")
 	global.Var(&allowDomains, "allow-domain", "Allowed domain when --net is set (repeatable). If none provided, all domains allowed when --net is set.")
+	global.BoolVar(&allowDelete, "allow-delete", false, "Consent to file deletes requested by tools (e.g., MegaPatch delete statements).")
 	global.Usage = func() { writeRootHelp(stderr) }
 
 	if err := global.Parse(argv[1:]); err != nil {
@@ -83,6 +99,7 @@ func Run(argv []string) int {
 	pol := policy.Policy{
 		NetEnabled:   netEnabled,
 		AllowDomains: allowDomains.values,
+		AllowDelete:  allowDelete,
 	}
 
 	cmd := rest[0]
@@ -100,11 +117,18 @@ func Run(argv []string) int {
 		return runDiagnose(ctr, pol, artifactDir, args, stdout, stderr)
 	case "test":
 		return runTest(ctr, pol, artifactDir, args, stdout, stderr)
-	case "secure", "patch", "make":
-		log.Warn(cmd + ": not implemented yet")
-		return exitUsage
+	case "patch":
+		return runPatch(ctr, pol, artifactDir, args, stdout, stderr)
+	case "secure":
+		return runSecure(ctr, pol, artifactDir, args, stdout, stderr)
+	case "make":
+		return runMake(ctr, pol, artifactDir, args, stdout, stderr)
 	case "chat":
 		return runChat(ctr, pol, artifactDir, args, stdout, stderr)
+	case "config":
+		return runConfig(args, stdout, stderr)
+	case "artifact":
+		return runArtifact(ctr, artifactDir, args, stdout, stderr)
 	default:
 		log.Error("unknown command: " + cmd)
 		writeRootHelp(stderr)
@@ -126,14 +150,46 @@ func runPrompt(ctr wiring.Container, pol policy.Policy, globalArtifactDir string
 	var maxFileBytes int64
 	var copyToClipboard bool
 	var showSummary bool
+	var maxTokens int
+	var focus string
+	var focusDepth int
+	var focusDependents bool
+	var sinceRef string
+	var rangeSpec string
+	var format string
+	var noRedact bool
+	var explainIgnores bool
+	var skeleton bool
+	var skeletonExcept stringListFlag
+	var splitBytes int
+	var splitTokens int
+	var workers int
+	var pkg string
 
 	var ignores stringListFlag
+	var include includeRuleFlags
 	fs.Var(&ignores, "ignore", "Directory names or glob paths to ignore (repeatable). Use quotes in zsh: --ignore 'megamake/artifacts/**'")
 	fs.Var(&ignores, "I", "Alias for --ignore (repeatable).")
+	include.register(fs)
 	fs.StringVar(&jsonOut, "json-out", "", "Write JSON report to this path (optional).")
 	fs.StringVar(&promptOut, "prompt-out", "", "Write agent prompt text to this path (optional).")
 	fs.BoolVar(&force, "force", false, "Force run even if the directory does not look like a code project.")
 	fs.Int64Var(&maxFileBytes, "max-file-bytes", 1_500_000, "Skip files larger than this many bytes during scanning.")
+	fs.IntVar(&workers, "workers", 0, "Read at most this many files concurrently (0 = based on CPU count).")
+	fs.StringVar(&pkg, "package", "", "In a monorepo workspace: run on this member package only (name or relpath).")
+	fs.BoolVar(&explainIgnores, "explain-ignores", false, "List paths excluded by .gitignore/.megamakeignore and the rule that excluded each.")
+	fs.StringVar(&focus, "focus", "", "Only include this file, directory or symbol plus its import neighborhood.")
+	fs.IntVar(&focusDepth, "depth", 1, "With --focus: how many import edges to follow (0 = target only).")
+	fs.BoolVar(&focusDependents, "dependents", false, "With --focus: also follow reverse edges (files importing the target).")
+	fs.StringVar(&sinceRef, "since", "", "Only include files changed in <ref>..HEAD (plus direct import neighbors) and add a <diff> section.")
+	fs.StringVar(&rangeSpec, "range", "", "Like --since, for an explicit git range such as A..B.")
+	fs.StringVar(&format, "format", "pseudo-xml", "Context blob format: pseudo-xml|xml|markdown|json.")
+	fs.IntVar(&maxTokens, "max-tokens", 0, "Pack the most important files into this token budget and drop the rest (0 = no limit).")
+	fs.BoolVar(&skeleton, "skeleton", false, "Replace function bodies with an elision marker, keeping declarations and signatures.")
+	fs.Var(&skeletonExcept, "skeleton-except", "With --skeleton: relpath glob whose files keep full bodies (repeatable).")
+	fs.IntVar(&splitBytes, "split-bytes", 0, "Write the context as numbered part files of at most N bytes each (0 = no split).")
+	fs.IntVar(&splitTokens, "split-tokens", 0, "Write the context as numbered part files of at most ~N tokens each (0 = no split).")
+	fs.BoolVar(&noRedact, "no-redact", false, "Do not replace detected secrets (keys, tokens, private keys) with placeholders.")
 	fs.BoolVar(&copyToClipboard, "copy", false, "Best-effort: also copy the generated <context> blob to clipboard.")
 	fs.BoolVar(&showSummary, "show-summary", true, "Print a brief summary to stderr.")
 	fs.Usage = func() { writePromptHelp(stderr) }
@@ -172,6 +228,20 @@ func runPrompt(ctr wiring.Container, pol policy.Policy, globalArtifactDir string
 	// Resolve root relative to invocation directory (supports cd’ing aliases via MEGAMAKE_CALLER_PWD).
 	rootPath = resolveRootPathFromInvocation(rootPath)
 
+	if err := applyProjectConfig(fs, "prompt", rootPath, log); err != nil {
+		log.Error(err.Error())
+		return exitUsage
+	}
+
+	if pkg != "" {
+		memberRoot, err := resolvePackageRoot(ctr, rootPath, pkg)
+		if err != nil {
+			log.Error("prompt: " + err.Error())
+			return exitUsage
+		}
+		rootPath = memberRoot
+	}
+
 	artifactRoot := artifactDirForLocalTools(globalArtifactDir, log)
 
 	ignoreNames, ignoreGlobs := splitIgnores(ignores.values)
@@ -186,18 +256,31 @@ func runPrompt(ctr wiring.Container, pol policy.Policy, globalArtifactDir string
 		MaxFileBytes:    maxFileBytes,
 		IgnoreNames:     ignoreNames,
 		IgnoreGlobs:     ignoreGlobs,
+		IncludeExts:     include.exts.values,
+		IncludeGlobs:    include.globs.values,
+		ExcludeExts:     include.excludeExts.values,
+		ExplainIgnores:  explainIgnores,
+		Focus:           focus,
+		FocusDepth:      focusDepth,
+		FocusDependents: focusDependents,
+		SinceRef:        sinceRef,
+		Range:           rangeSpec,
+		Format:          promptdomain.ContextFormat(format),
+		MaxTokens:       maxTokens,
+		Skeleton:        skeleton,
+		SkeletonExcept:  skeletonExcept.values,
+		NoRedact:        noRedact,
+		SplitBytes:      splitBytes,
+		SplitTokens:     splitTokens,
 		CopyToClipboard: copyToClipboard,
+		Workers:         workers,
+		ContextOut:      stdout,
 	})
 	if err != nil {
 		log.Error(err.Error())
 		return exitError
 	}
 
-	if _, err := io.WriteString(stdout, res.ContextXML); err != nil {
-		log.Error(fmt.Sprintf("failed writing to stdout: %v", err))
-		return exitError
-	}
-
 	if jsonOut != "" {
 		if err := os.WriteFile(jsonOut, []byte(res.ReportJSON+"\n"), 0o644); err != nil {
 			log.Error(fmt.Sprintf("failed writing --json-out: %v", err))
@@ -220,6 +303,34 @@ func runPrompt(ctr wiring.Container, pol policy.Policy, globalArtifactDir string
 		log.Info("root: " + rootPath)
 		log.Info("artifact dir: " + artifactRoot)
 		log.Info("files scanned: " + itoa(res.Report.FilesScanned) + ", included: " + itoa(res.Report.FilesIncluded))
+		log.Info("format: " + res.Report.Format)
+		if explainIgnores {
+			log.Info("ignored by ignore files: " + itoa(len(res.Report.Ignored)))
+			for _, ig := range res.Report.Ignored {
+				log.Info("  " + ig.RelPath + " (" + ig.Source + ":" + itoa(ig.Line) + " " + ig.Pattern + ")")
+			}
+		}
+		if skeleton {
+			log.Info("skeleton: " + itoa(len(res.Report.Skeletonized)) + " file(s) with bodies elided")
+		}
+		if res.Report.Focus != nil {
+			log.Info("focus: " + res.Report.Focus.Target + " (" + res.Report.Focus.TargetKind + ", depth " + itoa(res.Report.Focus.Depth) + ")")
+			for _, line := range strings.Split(res.Report.Focus.Tree, "\n") {
+				log.Info("  " + line)
+			}
+		}
+		if d := res.Report.Diff; d != nil {
+			log.Info("diff: " + d.Mode + " " + d.Ref + " (changed: " + itoa(len(d.ChangedFiles)) + ", neighbors: " + itoa(len(d.Neighbors)) + ", not in context: " + itoa(len(d.Missing)) + ", diff bytes: " + itoa(d.DiffBytes) + ")")
+		}
+		if maxTokens > 0 {
+			log.Info("tokens (estimated): " + itoa(res.Report.EstimatedTokens) + " / " + itoa(maxTokens) + ", dropped files: " + itoa(len(res.Report.Dropped)))
+		}
+		if sp := res.Report.Split; sp != nil {
+			log.Info("split: " + itoa(len(sp.Parts)) + " part(s), limit " + itoa(sp.Limit) + " " + sp.Unit)
+			for _, p := range res.PartPaths {
+				log.Info("  part: " + p)
+			}
+		}
 		log.Info("artifact: " + res.ArtifactPath)
 		log.Info("latest pointer: " + res.LatestPath)
 		if copyToClipboard {
@@ -269,9 +380,13 @@ func runDoc(ctr wiring.Container, pol policy.Policy, globalArtifactDir string, a
 		var umlIncludeEndpoints bool
 
 		var ignores stringListFlag
+		var include includeRuleFlags
+		var pkg string
 
 		fs.Var(&ignores, "ignore", "Directory names or glob paths to ignore (repeatable). Use quotes in zsh: --ignore 'megamake/artifacts/**'")
 		fs.Var(&ignores, "I", "Alias for --ignore (repeatable).")
+		include.register(fs)
+		fs.StringVar(&pkg, "package", "", "In a monorepo workspace: run on this member package only (name or relpath).")
 		fs.BoolVar(&force, "force", false, "Force run even if directory does not look like a code project.")
 		fs.BoolVar(&showSummary, "show-summary", true, "Print a brief summary to stderr.")
 		fs.Int64Var(&maxFileBytes, "max-file-bytes", 1_500_000, "Skip files larger than this many bytes during scanning.")
@@ -320,6 +435,20 @@ func runDoc(ctr wiring.Container, pol policy.Policy, globalArtifactDir string, a
 			}
 		}
 
+		if err := applyProjectConfig(fs, "doc", rootPath, log); err != nil {
+			log.Error(err.Error())
+			return exitUsage
+		}
+
+		if pkg != "" {
+			memberRoot, err := resolvePackageRoot(ctr, rootPath, pkg)
+			if err != nil {
+				log.Error("doc create: " + err.Error())
+				return exitUsage
+			}
+			rootPath = memberRoot
+		}
+
 		artifactRoot := artifactDirForLocalTools(globalArtifactDir, log)
 
 		ignoreNames, ignoreGlobs := splitIgnores(ignores.values)
@@ -341,6 +470,9 @@ func runDoc(ctr wiring.Container, pol policy.Policy, globalArtifactDir string, a
 			UMLIncludeEndpoints: umlIncludeEndpoints,
 			IgnoreNames:         ignoreNames,
 			IgnoreGlobs:         ignoreGlobs,
+			IncludeExts:         include.exts.values,
+			IncludeGlobs:        include.globs.values,
+			ExcludeExts:         include.excludeExts.values,
 			NetEnabled:          pol.NetEnabled,
 			AllowDomains:        pol.AllowDomains,
 			Args:                nil,
@@ -396,6 +528,9 @@ func runDoc(ctr wiring.Container, pol policy.Policy, globalArtifactDir string, a
 			if len(res.Report.Languages) > 0 {
 				log.Info("languages: " + strings.Join(res.Report.Languages, ", "))
 			}
+			for _, p := range res.Report.Packages {
+				log.Info("package: " + p.Name + " (" + p.Kind + ", " + p.RelPath + ")")
+			}
 			log.Info("imports: " + itoa(len(res.Report.Imports)) + ", external deps: " + itoa(len(res.Report.ExternalDependencies)))
 			if strings.TrimSpace(res.Report.UMLASCII) != "" {
 				log.Info("uml: ascii included")
@@ -535,8 +670,10 @@ func runDiagnose(ctr wiring.Container, pol policy.Policy, globalArtifactDir stri
 	var showSummary bool
 	var maxFileBytes int64
 	var ignores stringListFlag
+	var pkg string
 
 	fs.BoolVar(&force, "force", false, "Force run even if directory does not look like a code project.")
+	fs.StringVar(&pkg, "package", "", "In a monorepo workspace: run on this member package only (name or relpath).")
 	fs.BoolVar(&includeTests, "include-tests", false, "Also compile/analyze tests for diagnostics without running them.")
 	fs.IntVar(&timeoutSeconds, "timeout-seconds", 120, "Timeout in seconds per tool invocation.")
 	fs.Int64Var(&maxFileBytes, "max-file-bytes", 1_500_000, "Skip files larger than this many bytes during scanning.")
@@ -580,6 +717,20 @@ func runDiagnose(ctr wiring.Container, pol policy.Policy, globalArtifactDir stri
 		}
 	}
 
+	if err := applyProjectConfig(fs, "diagnose", rootPath, log); err != nil {
+		log.Error(err.Error())
+		return exitUsage
+	}
+
+	if pkg != "" {
+		memberRoot, err := resolvePackageRoot(ctr, rootPath, pkg)
+		if err != nil {
+			log.Error("diagnose: " + err.Error())
+			return exitUsage
+		}
+		rootPath = memberRoot
+	}
+
 	artifactRoot := artifactDirForLocalTools(globalArtifactDir, log)
 	ignoreNames, ignoreGlobs := splitIgnores(ignores.values)
 	ignoreGlobs = append(ignoreGlobs, defaultLocalArtifactsIgnoreGlobs(rootPath)...)
@@ -649,6 +800,14 @@ func runDiagnose(ctr wiring.Container, pol policy.Policy, globalArtifactDir stri
 			}
 		}
 		log.Info("issues: " + itoa(totalIssues) + " (errors: " + itoa(totalErrs) + ", warnings: " + itoa(totalWarns) + ")")
+		for _, ld := range res.Report.Languages {
+			if ld.Package != "" {
+				log.Info("  " + ld.Package + " [" + ld.Name + "]: " + itoa(len(ld.Issues)) + " issue(s)")
+			}
+		}
+		if o := res.Report.Offline; o != nil {
+			log.Info("offline: " + itoa(len(o.Forced)) + " tool(s) forced offline, " + itoa(len(o.Skipped)) + " skipped (pass --net to allow downloads)")
+		}
 		if len(res.Report.Warnings) > 0 {
 			log.Warn("warnings: " + itoa(len(res.Report.Warnings)) + " (see artifact for details)")
 		}
@@ -676,12 +835,15 @@ func runTest(ctr wiring.Container, pol policy.Policy, globalArtifactDir string,
 	var maxAnalyzeBytes int64
 
 	var ignores stringListFlag
+	var include includeRuleFlags
 
 	var regressionSince string
 	var regressionRange string
 	var noRegression bool
+	var pkg string
 
 	fs.BoolVar(&force, "force", false, "Force run even if directory does not look like a code project.")
+	fs.StringVar(&pkg, "package", "", "In a monorepo workspace: run on this member package only (name or relpath).")
 	fs.BoolVar(&showSummary, "show-summary", true, "Print a brief summary to stderr.")
 	fs.IntVar(&limitSubjects, "limit-subjects", 500, "Limit number of subjects analyzed (default: 500).")
 	fs.StringVar(&levels, "levels", "", "Comma-separated levels: smoke,unit,integration,e2e,regression (default: all).")
@@ -694,6 +856,7 @@ func runTest(ctr wiring.Container, pol policy.Policy, globalArtifactDir string,
 
 	fs.Var(&ignores, "ignore", "Directory names or glob paths to ignore (repeatable). Use quotes in zsh: --ignore 'megamake/artifacts/**'")
 	fs.Var(&ignores, "I", "Alias for --ignore (repeatable).")
+	include.register(fs)
 
 	fs.StringVar(&jsonOut, "json-out", "", "Write JSON output to this file.")
 	fs.StringVar(&promptOut, "prompt-out", "", "Write test prompt text to this file.")
@@ -731,6 +894,20 @@ func runTest(ctr wiring.Container, pol policy.Policy, globalArtifactDir string,
 		}
 	}
 
+	if err := applyProjectConfig(fs, "test", rootPath, log); err != nil {
+		log.Error(err.Error())
+		return exitUsage
+	}
+
+	if pkg != "" {
+		memberRoot, err := resolvePackageRoot(ctr, rootPath, pkg)
+		if err != nil {
+			log.Error("test: " + err.Error())
+			return exitUsage
+		}
+		rootPath = memberRoot
+	}
+
 	artifactRoot := artifactDirForLocalTools(globalArtifactDir, log)
 
 	ignoreNames, ignoreGlobs := splitIgnores(ignores.values)
@@ -758,6 +935,9 @@ func runTest(ctr wiring.Container, pol policy.Policy, globalArtifactDir string,
 		MaxAnalyzeBytes: maxAnalyzeBytes,
 		IgnoreNames:     ignoreNames,
 		IgnoreGlobs:     ignoreGlobs,
+		IncludeExts:     include.exts.values,
+		IncludeGlobs:    include.globs.values,
+		ExcludeExts:     include.excludeExts.values,
 		Regression:      regMode,
 		NetEnabled:      pol.NetEnabled,
 		AllowDomains:    pol.AllowDomains,
@@ -928,6 +1108,27 @@ func resolveRootPathFromInvocation(rootPath string) string {
 	return filepath.Clean(filepath.Join(base, rootPath))
 }
 
+// resolvePackageRoot returns the directory of the workspace member of rootPath whose name
+// or relpath is pkg.
+func resolvePackageRoot(ctr wiring.Container, rootPath string, pkg string) (string, error) {
+	profile, err := ctr.Repo.Detect(rootPath)
+	if err != nil {
+		return "", err
+	}
+	if len(profile.Members) == 0 {
+		return "", fmt.Errorf("--package %s: %s is not a workspace root (no go.work, package.json/pnpm workspaces, Cargo [workspace] or Gradle includes)", pkg, rootPath)
+	}
+	want := strings.Trim(filepath.ToSlash(strings.TrimSpace(pkg)), "/")
+	var names []string
+	for _, m := range profile.Members {
+		if m.Name == pkg || m.RelPath == want {
+			return filepath.Join(rootPath, filepath.FromSlash(m.RelPath)), nil
+		}
+		names = append(names, m.Name)
+	}
+	return "", fmt.Errorf("--package %s: no such workspace member (members: %s)", pkg, strings.Join(names, ", "))
+}
+
 func artifactDirForLocalTools(globalArtifactDir string, log console.Logger) string {
 	// Your requirement: prompt/doc/diagnose/test artifacts should be written to the directory
 	// where the command was invoked.
@@ -1014,18 +1215,26 @@ Global flags:
                            Default: current working directory (where you run the command).
   --net                    Enable network access (deny-by-default otherwise).
   --allow-domain <domain>  Allowed domain when --net is set (repeatable). If none provided, all domains allowed when --net is set.
+  --allow-delete           Consent to file deletes requested by tools (deny-by-default otherwise).
 
 Commands:
   prompt   [path] [flags]   (also accepts flags after path)
   doc      <subcommand>
   diagnose [path] [flags]   (also accepts flags after path)
   test     [path] [flags]   (also accepts flags after path)
+  patch    [script] [flags] (applies a MegaPatch v1 script; stdin if omitted)
+  secure   [path] [flags]   (local security scan; also accepts flags after path)
+  make     [file] [flags]   (runs a declarative workflow; default: megamake.workflow.yaml)
   chat     <subcommand>
+  config   show [path]      (prints the effective megamake.toml/.megamake.json settings)
+  artifact <subcommand>     (show/extract/latest/prune/diff/verify: reads back, prunes, diffs, verifies MEGA* artifacts)
 
 Notes:
-  - prompt/doc/diagnose/test automatically ignore local artifacts directories (if present):
+  - prompt/doc/diagnose/test/secure/make automatically ignore local artifacts directories (if present):
       - megamake/artifacts/**
       - artifacts/**
+  - prompt/doc/diagnose/test/secure read flag defaults from megamake.toml or .megamake.json
+    found from the target path upwards; command-line flags win (see: megamake config help).
   - If you use zsh and pass glob patterns to --ignore, quote them:
       --ignore 'megamake/artifacts/**'
 `)
@@ -1046,9 +1255,45 @@ Flags:
                                 --ignore megamake/artifacts
                                 --ignore 'docs/generated/**'
                               zsh note: quote globs or zsh may expand/raise "no matches found".
+  --include-ext EXT           Also include files with this extension (repeatable), e.g.
+                              --include-ext vue --include-ext .proto. Overrides built-in excludes.
+  --include-glob G            Always include files whose relpath matches G (repeatable),
+                              e.g. 'assets/**/*.svg'.
+  --exclude-ext EXT           Exclude files with this extension (repeatable); wins over
+                              --include-ext and the language defaults.
   --max-file-bytes N          Skip files larger than N bytes (default: 1500000).
+  --workers N                 Read at most N files concurrently (default: 0 = based on CPU count).
+                              Output order is unaffected: files are always emitted sorted.
+  --explain-ignores           List the paths .gitignore/.megamakeignore excluded, with the file,
+                              line and pattern responsible (stderr summary and report "ignored").
+  --focus X                   Only include X (file, directory or symbol such as ParseSpec or
+                              Service.Run) plus the files it imports, up to --depth edges.
+  --depth N                   With --focus: import edges to follow (default: 1; 0 = target only).
+  --dependents                With --focus: also include files that import the visited files.
+  --since REF                 Only include files changed in REF..HEAD plus their direct import
+                              neighbors, and add a <diff> section with the unified hunks.
+  --range A..B                Like --since, for an explicit git range.
+  --format F                  Context format: pseudo-xml (default; paths as tag names),
+                              xml (strict, <file path="...">), markdown (fenced blocks), json (array).
+  --max-tokens N              Fit the <context> into ~N tokens (estimated). Files are ranked
+                              manifests > entrypoints > READMEs > most-imported sources > rest;
+                              dropped files and reasons are listed in the JSON report.
+  --skeleton                  Replace function/method bodies with an elision marker, keeping imports,
+                              types, fields and signatures (Go, Python, JS/TS, Rust, Swift, Java,
+                              Kotlin). Skeletonized files are marked skeleton="true" in the context.
+  --skeleton-except GLOB      With --skeleton: keep full bodies for matching relpaths
+                              (repeatable, e.g. --skeleton-except 'internal/app/**').
+  --split-bytes N             Write the context as MEGAPROMPT_<ts>_part01.txt, part02, ... of at most
+                              N bytes each (header included). Files are never cut; the main artifact
+                              and stdout hold the manifest listing every part and its files.
+  --split-tokens N            Like --split-bytes, measured in estimated tokens.
+  --no-redact                 Keep detected secrets as-is. By default AWS/GCP/OpenAI-style keys,
+                              tokens, JWTs, private key material and random-looking assigned
+                              values become [REDACTED:<rule>:<hash>] placeholders.
+  --package NAME              In a monorepo workspace (go.work, npm/yarn/pnpm workspaces, Cargo
+                              workspace, Gradle includes): only this member (name or relpath).
   --force                     Run even if directory does not look like a code project.
-  --copy                      Best-effort: copy the generated <context> to clipboard.
+  --copy                      Best-effort: copy the generated <context> (or its first part) to clipboard.
   --json-out PATH             Write JSON report to PATH (optional).
   --prompt-out PATH           Write agent prompt text to PATH (optional).
   --show-summary=true|false   Print a brief summary to stderr (default: true).
@@ -1059,6 +1304,9 @@ Defaults:
   - Automatically ignores local artifacts directories if present:
       - megamake/artifacts/**
       - artifacts/**
+  - Honors .gitignore and .megamakeignore files in every directory (gitignore syntax:
+    negation, anchored and directory-only patterns). .megamakeignore wins over .gitignore
+    in the same directory; deeper files win over their parents.
 `)
 	_, _ = io.WriteString(w, help+"\n")
 }
@@ -1092,6 +1340,14 @@ Common flags:
                                 --ignore megamake/artifacts
                                 --ignore 'docs/generated/**'
                               zsh note: quote globs or zsh may expand/raise "no matches found".
+  --include-ext EXT           Also include files with this extension (repeatable), e.g.
+                              --include-ext vue --include-ext .proto. Overrides built-in excludes.
+  --include-glob G            Always include files whose relpath matches G (repeatable),
+                              e.g. 'assets/**/*.svg'.
+  --exclude-ext EXT           Exclude files with this extension (repeatable); wins over
+                              --include-ext and the language defaults.
+  --package NAME              Only document this workspace member (name or relpath).
+                              Without it, workspace members are listed in the report.
   --force
   --show-summary=true|false
   --max-file-bytes N
@@ -1162,6 +1418,8 @@ Runs multi-language diagnostics (best-effort) and emits a report + fix prompt.
 
 Flags:
   --force
+  --package NAME              Only diagnose this workspace member (name or relpath). Without it,
+                              a workspace root runs each member's toolchain from the member dir.
   --include-tests
   --timeout-seconds N
   --max-file-bytes N
@@ -1181,6 +1439,19 @@ Defaults:
   - Automatically ignores local artifacts directories if present:
       - megamake/artifacts/**
       - artifacts/**
+  - Go: go build, plus go vet -json and (when on PATH) staticcheck -f json; analyzer
+    names/check IDs become the issue code.
+  - Rust: cargo check --message-format=json; span labels and notes are kept as related
+    notes, and rustc's suggested replacements as suggestions (also in the fix prompt).
+  - JS/TS: tsc, plus eslint -f json when the project has an ESLint config; rule IDs become
+    the issue code and issues eslint --fix can fix are marked fixable.
+  - Python: one batched compile check, then ruff, mypy and pyright (JSON output) when
+    installed; they read pyproject.toml from the project root.
+  - Without global --net, toolchains run offline: npx --no-install, cargo --offline,
+    mvn -o, gradle --offline, go with GOPROXY=off GOTOOLCHAIN=local GOFLAGS=-mod=mod.
+    Tools that would have to download first (an npx package that is not installed,
+    unfetched Swift/Lean dependencies, an uncached Gradle wrapper) are skipped.
+    The report's <offline> section lists what was forced offline and what was skipped.
 `)
 	_, _ = io.WriteString(w, help+"\n")
 }
@@ -1194,6 +1465,7 @@ Builds a test plan (subjects + scenarios) for the repo.
 
 Flags:
   --force
+  --package NAME              Only plan tests for this workspace member (name or relpath).
   --limit-subjects N
   --levels csv               (smoke,unit,integration,e2e,regression) (default: all)
   --max-file-bytes N
@@ -1204,6 +1476,12 @@ Flags:
                                 --ignore megamake/artifacts
                                 --ignore 'docs/generated/**'
                               zsh note: quote globs or zsh may expand/raise "no matches found".
+  --include-ext EXT           Also include files with this extension (repeatable), e.g.
+                              --include-ext vue --include-ext .proto. Overrides built-in excludes.
+  --include-glob G            Always include files whose relpath matches G (repeatable),
+                              e.g. 'assets/**/*.svg'.
+  --exclude-ext EXT           Exclude files with this extension (repeatable); wins over
+                              --include-ext and the language defaults.
   --regression-since REF
   --regression-range A..B
   --no-regression
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/megamake/megamake/internal/app/wiring"
+	"github.com/megamake/megamake/internal/platform/console"
+	"github.com/megamake/megamake/internal/platform/policy"
+
+	contractsecure "github.com/megamake/megamake/internal/contracts/v1/secure"
+	secureapp "github.com/megamake/megamake/internal/domains/secure/app"
+)
+
+// runSecure implements:
+//
+//	megamake secure [path] [--min-severity S] [--fail-on S]
+//
+// It scans the project for hardcoded secrets, private keys, weak crypto and unsafe
+// exec/SQL construction, and writes a MEGASECURE artifact with a remediation prompt.
+func runSecure(ctr wiring.Container, pol policy.Policy, globalArtifactDir string, argv []string, stdout io.Writer, stderr io.Writer) int {
+	log := console.New(stderr)
+
+	leadingPos, flagArgs := splitLeadingPositionals(argv)
+
+	fs := flag.NewFlagSet("secure", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var jsonOut string
+	var promptOut string
+	var force bool
+	var includeTests bool
+	var showSummary bool
+	var maxFileBytes int64
+	var minSeverity string
+	var failOn string
+	var ignores stringListFlag
+	var include includeRuleFlags
+
+	fs.BoolVar(&force, "force", false, "Force run even if directory does not look like a code project.")
+	fs.BoolVar(&includeTests, "include-tests", false, "Also run code-pattern rules on test files (secret rules always run).")
+	fs.Int64Var(&maxFileBytes, "max-file-bytes", 1_500_000, "Skip files larger than this many bytes during scanning.")
+	fs.StringVar(&minSeverity, "min-severity", "low", "Drop findings below this severity: critical|high|medium|low.")
+	fs.StringVar(&failOn, "fail-on", "", "Exit non-zero if any finding is at or above this severity (optional).")
+	fs.BoolVar(&showSummary, "show-summary", true, "Print a brief summary to stderr.")
+	fs.Var(&ignores, "ignore", "Directory names or glob paths to ignore (repeatable). Use quotes in zsh: --ignore 'megamake/artifacts/**'")
+	fs.Var(&ignores, "I", "Alias for --ignore (repeatable).")
+	include.register(fs)
+
+	fs.StringVar(&jsonOut, "json-out", "", "Write JSON output to this file.")
+	fs.StringVar(&promptOut, "prompt-out", "", "Write remediation prompt text to this file.")
+
+	fs.Usage = func() { writeSecureHelp(stderr) }
+
+	argsToParse := argv
+	if len(leadingPos) > 0 {
+		argsToParse = flagArgs
+	}
+	if err := fs.Parse(argsToParse); err != nil {
+		writeSecureHelp(stderr)
+		log.Error(fmt.Sprintf("failed to parse secure flags: %v", err))
+		return exitUsage
+	}
+
+	rootPath := "."
+	if len(leadingPos) > 0 {
+		if len(leadingPos) != 1 {
+			log.Error("secure: expected at most one positional path")
+			writeSecureHelp(stderr)
+			return exitUsage
+		}
+		rootPath = leadingPos[0]
+	} else {
+		rest := fs.Args()
+		if len(rest) >= 1 {
+			rootPath = rest[0]
+			rest = rest[1:]
+		}
+		if len(rest) > 0 {
+			log.Error("secure: unexpected extra arguments: " + strings.Join(rest, " "))
+			writeSecureHelp(stderr)
+			return exitUsage
+		}
+	}
+
+	rootPath = resolveRootPathFromInvocation(rootPath)
+
+	if err := applyProjectConfig(fs, "secure", rootPath, log); err != nil {
+		log.Error(err.Error())
+		return exitUsage
+	}
+
+	minSev, err := secureapp.ParseSeverity(minSeverity)
+	if err != nil {
+		log.Error("secure: --min-severity: " + err.Error())
+		return exitUsage
+	}
+	var failSev contractsecure.SeverityV1
+	if strings.TrimSpace(failOn) != "" {
+		failSev, err = secureapp.ParseSeverity(failOn)
+		if err != nil {
+			log.Error("secure: --fail-on: " + err.Error())
+			return exitUsage
+		}
+	}
+
+	artifactRoot := artifactDirForLocalTools(globalArtifactDir, log)
+	ignoreNames, ignoreGlobs := splitIgnores(ignores.values)
+	ignoreGlobs = append(ignoreGlobs, defaultLocalArtifactsIgnoreGlobs(rootPath)...)
+	ignoreNames = dedupeStrings(ignoreNames)
+	ignoreGlobs = dedupeStrings(ignoreGlobs)
+
+	res, err := ctr.Secure.Scan(secureapp.ScanRequest{
+		RootPath:     rootPath,
+		ArtifactDir:  artifactRoot,
+		Force:        force,
+		IncludeTests: includeTests,
+		MinSeverity:  minSev,
+		MaxFileBytes: maxFileBytes,
+		IgnoreNames:  ignoreNames,
+		IgnoreGlobs:  ignoreGlobs,
+		IncludeExts:  include.exts.values,
+		IncludeGlobs: include.globs.values,
+		ExcludeExts:  include.excludeExts.values,
+		NetEnabled:   pol.NetEnabled,
+		AllowDomains: pol.AllowDomains,
+		Args:         nil,
+	})
+	if err != nil {
+		log.Error(err.Error())
+		return exitError
+	}
+
+	if _, err := io.WriteString(stdout, res.ReportXML+"\n"); err != nil {
+		log.Error(fmt.Sprintf("failed writing to stdout: %v", err))
+		return exitError
+	}
+
+	if jsonOut != "" {
+		if err := os.WriteFile(jsonOut, []byte(res.ReportJSON+"\n"), 0o644); err != nil {
+			log.Error(fmt.Sprintf("failed writing --json-out: %v", err))
+			return exitError
+		}
+	}
+	if promptOut != "" {
+		if err := os.WriteFile(promptOut, []byte(res.RemediationPrompt+"\n"), 0o644); err != nil {
+			log.Error(fmt.Sprintf("failed writing --prompt-out: %v", err))
+			return exitError
+		}
+	}
+
+	s := res.Report.Summary
+	if showSummary {
+		log.Info("mode: secure")
+		log.Info("root: " + rootPath)
+		log.Info("artifact: " + res.ArtifactPath)
+		log.Info("latest pointer: " + res.LatestPath)
+		log.Info("files scanned: " + itoa(res.Report.FilesScanned) + ", analyzed: " + itoa(res.Report.FilesAnalyzed))
+		if len(ignoreNames) > 0 {
+			log.Info("ignore names: " + strings.Join(ignoreNames, ", "))
+		}
+		if len(ignoreGlobs) > 0 {
+			log.Info("ignore globs: " + strings.Join(ignoreGlobs, ", "))
+		}
+		log.Info("findings: " + itoa(s.Total) + " (critical: " + itoa(s.Critical) + ", high: " + itoa(s.High) + ", medium: " + itoa(s.Medium) + ", low: " + itoa(s.Low) + ", suppressed: " + itoa(s.Suppressed) + ")")
+		if len(res.Report.Warnings) > 0 {
+			log.Warn("warnings: " + itoa(len(res.Report.Warnings)) + " (see artifact for details)")
+		}
+	}
+
+	if failSev != "" {
+		for _, f := range res.Report.Findings {
+			if contractsecure.SeverityRank(f.Severity) <= contractsecure.SeverityRank(failSev) {
+				log.Error("secure: findings at or above " + string(failSev) + " severity")
+				return exitError
+			}
+		}
+	}
+
+	return exitOK
+}
+
+func writeSecureHelp(w io.Writer) {
+	help := strings.TrimSpace(`
+megamake secure [path] [flags]
+megamake secure [flags] [path]
+
+Scans the project for hardcoded secrets, private keys, weak crypto and unsafe
+exec/SQL string building, and emits a report + remediation prompt (MEGASECURE).
+
+Flags:
+  --force
+  --include-tests             Also run code-pattern rules on test files (secret rules always run).
+  --min-severity S            Drop findings below S: critical|high|medium|low (default: low).
+  --fail-on S                 Exit 1 if any finding is at or above S (optional; useful in CI).
+  --max-file-bytes N
+  --ignore X / -I X           Ignore directory name OR path/glob (repeatable).
+                              zsh note: quote globs or zsh may expand/raise "no matches found".
+  --include-ext EXT           Also include files with this extension (repeatable), e.g.
+                              --include-ext vue --include-ext .proto. Overrides built-in excludes.
+  --include-glob G            Always include files whose relpath matches G (repeatable),
+                              e.g. 'assets/**/*.svg'.
+  --exclude-ext EXT           Exclude files with this extension (repeatable); wins over
+                              --include-ext and the language defaults.
+  --json-out PATH
+  --prompt-out PATH
+  --show-summary=true|false
+
+Suppressing a false positive:
+  - Add a "nosec" (or "megamake:allow") comment on the offending line.
+
+Notes:
+  - Snippets in the report never contain the full secret value.
+  - Files the scanner never reads (.env*, binaries, lockfiles) are not checked.
+
+Defaults:
+  - Artifact output directory: current working directory.
+  - Automatically ignores local artifacts directories if present:
+      - megamake/artifacts/**
+      - artifacts/**
+`)
+	_, _ = io.WriteString(w, help+"\n")
+}
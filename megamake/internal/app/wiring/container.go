@@ -16,9 +16,21 @@ import (
 	tpadapters "github.com/megamake/megamake/internal/domains/testplan/adapters"
 	tpapi "github.com/megamake/megamake/internal/domains/testplan/api"
 
+	patchadapters "github.com/megamake/megamake/internal/domains/patch/adapters"
+	patchapi "github.com/megamake/megamake/internal/domains/patch/api"
+
+	secureadapters "github.com/megamake/megamake/internal/domains/secure/adapters"
+	secureapi "github.com/megamake/megamake/internal/domains/secure/api"
+
 	chatadapters "github.com/megamake/megamake/internal/domains/chat/adapters"
 	chatapi "github.com/megamake/megamake/internal/domains/chat/api"
 
+	workflowadapters "github.com/megamake/megamake/internal/domains/workflow/adapters"
+	workflowapi "github.com/megamake/megamake/internal/domains/workflow/api"
+
+	artifactadapters "github.com/megamake/megamake/internal/domains/artifact/adapters"
+	artifactapi "github.com/megamake/megamake/internal/domains/artifact/api"
+
 	artifactwriter "github.com/megamake/megamake/internal/platform/artifact"
 	"github.com/megamake/megamake/internal/platform/clock"
 )
@@ -33,8 +45,14 @@ type Container struct {
 	Doc      docapi.API
 	Diagnose diagapi.API
 	TestPlan tpapi.API
+	Patch    patchapi.API
+	Secure   secureapi.API
 
 	Chat chatapi.API
+
+	Workflow workflowapi.API
+
+	Artifact artifactapi.API
 }
 
 func New() Container {
@@ -52,7 +70,7 @@ func New() Container {
 	})
 
 	// Shared platform artifact writer
-	aw := artifactwriter.Writer{Clock: clk}
+	aw := artifactwriter.Writer{Clock: clk, Retention: artifactwriter.ConfigRetention{}}
 
 	// Prompt
 	promptArtifact := promptadapters.NewPlatformArtifactWriter(aw)
@@ -62,6 +80,8 @@ func New() Container {
 		Repo:           repo,
 		ArtifactWriter: promptArtifact,
 		Clipboard:      clipboard,
+		Git:            promptadapters.NewPlatformGit(),
+		TokenCounter:   chatadapters.NewHeuristicTokenCounter(),
 	})
 
 	// Doc
@@ -92,6 +112,22 @@ func New() Container {
 		Git:            tpGit,
 	})
 
+	// Patch
+	patchArtifact := patchadapters.NewPlatformArtifactWriter(aw)
+	patch := patchapi.New(patchapi.Dependencies{
+		Clock:          clk,
+		FS:             patchadapters.NewOSFileSystem(),
+		ArtifactWriter: patchArtifact,
+	})
+
+	// Secure
+	secureArtifact := secureadapters.NewPlatformArtifactWriter(aw)
+	secure := secureapi.New(secureapi.Dependencies{
+		Clock:          clk,
+		Repo:           repo,
+		ArtifactWriter: secureArtifact,
+	})
+
 	// Chat
 	chatFS := chatadapters.NewFSAdapters()
 	chat := chatapi.New(chatapi.Dependencies{
@@ -106,6 +142,31 @@ func New() Container {
 		RunSettings:  chatFS.RunSettings,
 	})
 
+	// Workflow (runs the domains above in-process)
+	workflowArtifact := workflowadapters.NewPlatformArtifactWriter(aw)
+	workflow := workflowapi.New(workflowapi.Dependencies{
+		Clock: clk,
+		Steps: workflowadapters.DomainStepRunner{
+			Prompt:   prompt,
+			Doc:      doc,
+			Diagnose: diagnose,
+			TestPlan: testPlan,
+			Secure:   secure,
+			Patch:    patch,
+			Chat:     chat,
+		},
+		ArtifactWriter: workflowArtifact,
+	})
+
+	// Artifact (reads, prunes and diffs MEGA* artifacts)
+	artifacts := artifactapi.New(artifactapi.Dependencies{
+		Clock:          clk,
+		Store:          artifactadapters.NewOSStore(),
+		Pruner:         artifactadapters.NewPlatformPruner(aw),
+		ArtifactWriter: artifactadapters.NewPlatformArtifactWriter(aw),
+		Git:            artifactadapters.NewPlatformGit(),
+	})
+
 	return Container{
 		Clock:          clk,
 		ArtifactWriter: aw,
@@ -114,6 +175,10 @@ func New() Container {
 		Doc:            doc,
 		Diagnose:       diagnose,
 		TestPlan:       testPlan,
+		Patch:          patch,
+		Secure:         secure,
 		Chat:           chat,
+		Workflow:       workflow,
+		Artifact:       artifacts,
 	}
 }
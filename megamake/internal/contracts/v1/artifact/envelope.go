@@ -1,66 +1,140 @@
 package artifact
 
-import "strings"
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+)
 
 // ArtifactEnvelopeV1 is the unified artifact envelope stored in MEGA* .txt files.
 // It embeds three human- and machine-consumable views:
 // - XML: pseudo-XML report (canonical/human)
 // - JSON: machine JSON report
 // - Prompt: agent-facing instruction text
+//
+// Manifest, if set, is written as a fourth JSON block after the prompt.
 type ArtifactEnvelopeV1 struct {
-	Meta   ArtifactMetaV1
-	XML    string
-	JSON   string
-	Prompt string
+	Meta     ArtifactMetaV1
+	XML      string
+	JSON     string
+	Prompt   string
+	Manifest *ManifestV1
 }
 
 // Render returns a pseudo-XML-ish envelope suitable for storing in a .txt artifact.
 // This is intentionally not strict XML, but is structured to be readable and robust.
 func (e ArtifactEnvelopeV1) Render() string {
 	var b strings.Builder
+	_ = e.WriteStreaming(&b, nil)
+	return b.String()
+}
+
+// WriteTo writes the rendered envelope to w without building it in memory first.
+func (e ArtifactEnvelopeV1) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := e.WriteStreaming(cw, nil)
+	return cw.n, err
+}
+
+// WriteStreaming writes the rendered envelope to w. If xml is non-nil it produces the XML
+// block in place of e.XML, so a payload too large to hold in memory (a big prompt context)
+// can be streamed from its source straight to disk. The JSON, prompt and manifest blocks
+// follow the XML block, so xml may still set e.JSON, e.Prompt and e.Manifest (e.g. a report
+// summarizing what it streamed).
+func (e *ArtifactEnvelopeV1) WriteStreaming(w io.Writer, xml func(w io.Writer) error) error {
+	bw := bufio.NewWriterSize(w, 64*1024)
 
 	tool := EscapeAttr(e.Meta.Tool)
 	contract := EscapeAttr(e.Meta.Contract)
 	generatedAt := EscapeAttr(e.Meta.GeneratedAt)
 
-	b.WriteString("<megamake_artifact tool=\"")
-	b.WriteString(tool)
-	b.WriteString("\" contract=\"")
-	b.WriteString(contract)
-	b.WriteString("\" generatedAt=\"")
-	b.WriteString(generatedAt)
-	b.WriteString("\">\n")
+	bw.WriteString("<megamake_artifact tool=\"")
+	bw.WriteString(tool)
+	bw.WriteString("\" contract=\"")
+	bw.WriteString(contract)
+	bw.WriteString("\" generatedAt=\"")
+	bw.WriteString(generatedAt)
+	if e.Meta.Format != "" {
+		bw.WriteString("\" format=\"")
+		bw.WriteString(EscapeAttr(e.Meta.Format))
+	}
+	bw.WriteString("\">\n")
 
 	// XML block
-	b.WriteString("  <xml><![CDATA[\n")
-	if e.XML != "" {
-		b.WriteString(e.XML)
-		if !strings.HasSuffix(e.XML, "\n") {
-			b.WriteString("\n")
+	bw.WriteString("  <xml><![CDATA[\n")
+	if xml != nil {
+		tw := &tailWriter{w: bw}
+		if err := xml(tw); err != nil {
+			return err
 		}
+		if tw.n > 0 && tw.last != '\n' {
+			bw.WriteString("\n")
+		}
+	} else {
+		writeBlock(bw, e.XML)
 	}
-	b.WriteString("  ]]></xml>\n\n")
+	bw.WriteString("  ]]></xml>\n\n")
 
 	// JSON block
-	b.WriteString("  <json><![CDATA[\n")
-	if e.JSON != "" {
-		b.WriteString(e.JSON)
-		if !strings.HasSuffix(e.JSON, "\n") {
-			b.WriteString("\n")
-		}
-	}
-	b.WriteString("  ]]></json>\n\n")
+	bw.WriteString("  <json><![CDATA[\n")
+	writeBlock(bw, e.JSON)
+	bw.WriteString("  ]]></json>\n\n")
 
 	// Prompt block
-	b.WriteString("  <prompt><![CDATA[\n")
-	if e.Prompt != "" {
-		b.WriteString(e.Prompt)
-		if !strings.HasSuffix(e.Prompt, "\n") {
-			b.WriteString("\n")
+	bw.WriteString("  <prompt><![CDATA[\n")
+	writeBlock(bw, e.Prompt)
+	bw.WriteString("  ]]></prompt>\n")
+
+	// Manifest block
+	if e.Manifest != nil {
+		b, err := json.MarshalIndent(e.Manifest, "", "  ")
+		if err != nil {
+			return err
 		}
+		bw.WriteString("\n  <manifest><![CDATA[\n")
+		bw.Write(b)
+		bw.WriteString("\n  ]]></manifest>\n")
 	}
-	b.WriteString("  ]]></prompt>\n")
 
-	b.WriteString("</megamake_artifact>\n")
-	return b.String()
+	bw.WriteString("</megamake_artifact>\n")
+	return bw.Flush()
+}
+
+// writeBlock writes s followed by a newline unless it is empty or already ends with one.
+func writeBlock(bw *bufio.Writer, s string) {
+	if s == "" {
+		return
+	}
+	bw.WriteString(s)
+	if !strings.HasSuffix(s, "\n") {
+		bw.WriteString("\n")
+	}
+}
+
+// tailWriter remembers the last byte written, so a streamed block can be newline-terminated.
+type tailWriter struct {
+	w    io.Writer
+	n    int64
+	last byte
+}
+
+func (t *tailWriter) Write(p []byte) (int, error) {
+	n, err := t.w.Write(p)
+	if n > 0 {
+		t.n += int64(n)
+		t.last = p[n-1]
+	}
+	return n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
 }
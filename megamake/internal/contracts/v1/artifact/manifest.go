@@ -0,0 +1,52 @@
+package artifact
+
+import "sort"
+
+// ManifestV1 records the provenance of an artifact: which file contents went into it, the
+// state of the work tree they were read from, and the megamake build that read them.
+// Tools list the files (NewManifest) and record the hash of each file as they read it
+// (SetHash); the artifact writer fills in git state, version and the hash of any listed file
+// the tool did not read itself.
+type ManifestV1 struct {
+	MegamakeVersion string       `json:"megamakeVersion"`
+	RootPath        string       `json:"rootPath"`
+	Git             *GitStateV1  `json:"git,omitempty"` // nil outside a git work tree
+	Files           []FileHashV1 `json:"files"`
+}
+
+// GitStateV1 is the work tree state at generation time.
+type GitStateV1 struct {
+	Head  string `json:"head"`  // commit hash HEAD pointed at
+	Dirty bool   `json:"dirty"` // uncommitted changes under the root (megamake artifacts excluded)
+}
+
+// FileHashV1 is the SHA-256 of a file's bytes on disk (before any transcoding or redaction).
+type FileHashV1 struct {
+	Path   string `json:"path"` // POSIX relpath under RootPath
+	SHA256 string `json:"sha256"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// NewManifest starts a manifest for the given root-relative files, sorted and deduplicated.
+func NewManifest(rootPath string, relPaths []string) *ManifestV1 {
+	m := &ManifestV1{RootPath: rootPath, Files: []FileHashV1{}}
+	seen := map[string]bool{}
+	for _, p := range relPaths {
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		m.Files = append(m.Files, FileHashV1{Path: p})
+	}
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	return m
+}
+
+// SetHash records the hash of the bytes a tool read for a listed file. Paths not in the
+// manifest are ignored.
+func (m *ManifestV1) SetHash(path string, sha256 string, bytes int64) {
+	i := sort.Search(len(m.Files), func(i int) bool { return m.Files[i].Path >= path })
+	if i < len(m.Files) && m.Files[i].Path == path {
+		m.Files[i].SHA256, m.Files[i].Bytes = sha256, bytes
+	}
+}
@@ -1,6 +1,7 @@
 package artifact
 
 import (
+	"strconv"
 	"strings"
 	"time"
 )
@@ -15,12 +16,34 @@ type ArtifactMetaV1 struct {
 	NetEnabled   bool     `json:"netEnabled"`
 	AllowDomains []string `json:"allowDomains,omitempty"`
 	Warnings     []string `json:"warnings,omitempty"`
+
+	// Format is the primary payload format when a tool supports several (e.g. prompt --format).
+	Format string `json:"format,omitempty"`
 }
 
 func FormatRFC3339NanoUTC(t time.Time) string {
 	return t.UTC().Format(time.RFC3339Nano)
 }
 
+// FilenameTimestamp formats t the way artifact filenames embed it (e.g. 20260120_154233Z).
+func FilenameTimestamp(t time.Time) string {
+	return t.UTC().Format("20060102_150405Z")
+}
+
+// PartFilename names part k (1-based) of n of a split artifact:
+// <ToolPrefix>_YYYYMMDD_HHMMSSZ_partNN.txt, zero-padded to at least two digits.
+func PartFilename(toolPrefix string, t time.Time, k int, n int) string {
+	width := len(strconv.Itoa(n))
+	if width < 2 {
+		width = 2
+	}
+	num := strconv.Itoa(k)
+	if len(num) < width {
+		num = strings.Repeat("0", width-len(num)) + num
+	}
+	return toolPrefix + "_" + FilenameTimestamp(t) + "_part" + num + ".txt"
+}
+
 // EscapeAttr escapes a string for safe use inside pseudo-XML attributes.
 func EscapeAttr(s string) string {
 	repl := strings.NewReplacer(
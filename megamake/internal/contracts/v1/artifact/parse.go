@@ -0,0 +1,146 @@
+package artifact
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Block delimiters as written by ArtifactEnvelopeV1.WriteStreaming.
+const (
+	xmlOpen      = "  <xml><![CDATA[\n"
+	xmlToJSON    = "  ]]></xml>\n\n  <json><![CDATA[\n"
+	jsonToPrompt = "  ]]></json>\n\n  <prompt><![CDATA[\n"
+	promptClose  = "  ]]></prompt>\n</megamake_artifact>"
+
+	promptToManifest = "  ]]></prompt>\n\n  <manifest><![CDATA[\n"
+	manifestClose    = "\n  ]]></manifest>\n</megamake_artifact>"
+)
+
+var headerAttrRe = regexp.MustCompile(`([A-Za-z]+)="([^"]*)"`)
+
+// ParseEnvelope reads back an envelope written by Render/WriteStreaming. Meta holds the
+// header attributes (tool, contract, generatedAt, format); the XML, JSON and prompt views
+// are returned as written, i.e. newline-terminated unless empty. Manifest is nil for
+// artifacts written without one.
+//
+// The blocks are raw CDATA, so the XML and prompt views may themselves contain the
+// delimiters (a context that includes an artifact). The JSON view cannot (encoded JSON
+// has no raw newlines inside strings), so the boundaries are found around it: the prompt
+// block ends at the last closing delimiter and the JSON block is the last one that parses.
+func ParseEnvelope(text string) (ArtifactEnvelopeV1, error) {
+	text = strings.TrimPrefix(text, "\uFEFF")
+	if !strings.HasPrefix(text, "<megamake_artifact ") {
+		return ArtifactEnvelopeV1{}, fmt.Errorf("not a megamake artifact (missing <megamake_artifact> header)")
+	}
+	nl := strings.IndexByte(text, '\n')
+	if nl < 0 {
+		return ArtifactEnvelopeV1{}, fmt.Errorf("truncated artifact: header only")
+	}
+	header := text[:nl]
+	body := text[nl+1:]
+
+	var e ArtifactEnvelopeV1
+	for _, m := range headerAttrRe.FindAllStringSubmatch(header, -1) {
+		v := UnescapeAttr(m[2])
+		switch m[1] {
+		case "tool":
+			e.Meta.Tool = v
+		case "contract":
+			e.Meta.Contract = v
+		case "generatedAt":
+			e.Meta.GeneratedAt = v
+		case "format":
+			e.Meta.Format = v
+		}
+	}
+
+	if !strings.HasPrefix(body, xmlOpen) {
+		return ArtifactEnvelopeV1{}, fmt.Errorf("malformed artifact: missing <xml> block")
+	}
+	body = body[len(xmlOpen):]
+
+	if m, rest, ok := cutManifest(body); ok {
+		e.Manifest = m
+		body = rest
+	} else {
+		end := strings.LastIndex(body, promptClose)
+		if end < 0 {
+			return ArtifactEnvelopeV1{}, fmt.Errorf("truncated artifact: missing closing </megamake_artifact>")
+		}
+		body = body[:end]
+	}
+
+	// Try xml/json delimiters from the last one back until the block after it is valid JSON
+	// (or empty) and followed by the json/prompt delimiter.
+	for x := strings.LastIndex(body, xmlToJSON); x >= 0; x = strings.LastIndex(body[:x], xmlToJSON) {
+		rest := body[x+len(xmlToJSON):]
+		j := strings.Index(rest, jsonToPrompt)
+		if j < 0 {
+			continue
+		}
+		js := rest[:j]
+		if strings.TrimSpace(js) != "" && !json.Valid([]byte(js)) {
+			continue
+		}
+		e.XML = body[:x]
+		e.JSON = js
+		e.Prompt = rest[j+len(jsonToPrompt):]
+		return e, nil
+	}
+	return ArtifactEnvelopeV1{}, fmt.Errorf("malformed artifact: missing or invalid <json> block")
+}
+
+// cutManifest splits a trailing manifest block off body (everything after the <xml> opener)
+// and returns body up to the end of the prompt block.
+func cutManifest(body string) (*ManifestV1, string, bool) {
+	end := strings.LastIndex(body, manifestClose)
+	if end < 0 || strings.TrimSpace(body[end+len(manifestClose):]) != "" {
+		return nil, "", false
+	}
+	head := body[:end]
+	for m := strings.LastIndex(head, promptToManifest); m >= 0; m = strings.LastIndex(head[:m], promptToManifest) {
+		var man ManifestV1
+		if err := json.Unmarshal([]byte(head[m+len(promptToManifest):]), &man); err != nil {
+			continue
+		}
+		return &man, head[:m], true
+	}
+	return nil, "", false
+}
+
+// Part returns one view of the envelope by name: xml, json, prompt or manifest (as JSON,
+// empty if the artifact has none).
+func (e ArtifactEnvelopeV1) Part(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "xml":
+		return e.XML, nil
+	case "json":
+		return e.JSON, nil
+	case "prompt":
+		return e.Prompt, nil
+	case "manifest":
+		if e.Manifest == nil {
+			return "", nil
+		}
+		b, err := json.MarshalIndent(e.Manifest, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(b) + "\n", nil
+	default:
+		return "", fmt.Errorf("unknown part %q (expected xml|json|prompt|manifest)", name)
+	}
+}
+
+// UnescapeAttr reverses EscapeAttr.
+func UnescapeAttr(s string) string {
+	repl := strings.NewReplacer(
+		"&quot;", "\"",
+		"&lt;", "<",
+		"&gt;", ">",
+		"&amp;", "&",
+	)
+	return repl.Replace(s)
+}
@@ -0,0 +1,193 @@
+package artifactdiff
+
+import (
+	"strings"
+
+	contractartifact "github.com/megamake/megamake/internal/contracts/v1/artifact"
+	diagnose "github.com/megamake/megamake/internal/contracts/v1/diagnose"
+	project "github.com/megamake/megamake/internal/contracts/v1/project"
+	testplan "github.com/megamake/megamake/internal/contracts/v1/testplan"
+)
+
+// ArtifactDiffReportV1 is the v1 contract for MEGADIFF output: the delta between two
+// artifacts of the same tool. Exactly one of Diagnostics, TestPlan and Prompt is set,
+// depending on ToolPrefix.
+type ArtifactDiffReportV1 struct {
+	GeneratedAt string        `json:"generatedAt"` // RFC3339Nano UTC
+	ToolPrefix  string        `json:"toolPrefix"`  // MEGADIAG|MEGATEST|MEGAPROMPT
+	Base        ArtifactRefV1 `json:"base"`
+	Head        ArtifactRefV1 `json:"head"`
+	Summary     DiffSummaryV1 `json:"summary"`
+	Warnings    []string      `json:"warnings,omitempty"`
+
+	Diagnostics *DiagnosticsDeltaV1 `json:"diagnostics,omitempty"`
+	TestPlan    *TestPlanDeltaV1    `json:"testPlan,omitempty"`
+	Prompt      *PromptDeltaV1      `json:"prompt,omitempty"`
+}
+
+// ArtifactRefV1 identifies one side of the comparison.
+type ArtifactRefV1 struct {
+	Path        string `json:"path"`
+	GeneratedAt string `json:"generatedAt"`
+	RootPath    string `json:"rootPath,omitempty"`
+}
+
+// DiffSummaryV1 counts entries that appeared, disappeared or stayed. What an entry is depends
+// on the tool: a diagnostic, an uncovered test subject, or a context file.
+type DiffSummaryV1 struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Changed   int `json:"changed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// DiagnosticsDeltaV1 compares two MEGADIAG reports. Diagnostics are matched on tool, file,
+// code, severity and message, not on line/column, so issues that only moved are unchanged.
+type DiagnosticsDeltaV1 struct {
+	New       []diagnose.DiagnosticV1 `json:"new"`
+	Fixed     []diagnose.DiagnosticV1 `json:"fixed"`
+	Unchanged int                     `json:"unchanged"`
+}
+
+// TestPlanDeltaV1 compares two MEGATEST reports by subject ID. A subject is uncovered when
+// its coverage status is MISSING (flag red).
+type TestPlanDeltaV1 struct {
+	NewUncovered    []SubjectDeltaV1 `json:"newUncovered"`    // uncovered in head, but absent or covered in base
+	NowCovered      []SubjectDeltaV1 `json:"nowCovered"`      // uncovered in base, covered in head
+	AddedSubjects   []SubjectDeltaV1 `json:"addedSubjects"`   // every subject only in head
+	RemovedSubjects []SubjectDeltaV1 `json:"removedSubjects"` // every subject only in base
+	StillUncovered  int              `json:"stillUncovered"`
+}
+
+// SubjectDeltaV1 is a test subject with its coverage on each side ("" when absent).
+type SubjectDeltaV1 struct {
+	ID           string                 `json:"id"`
+	Kind         testplan.SubjectKindV1 `json:"kind"`
+	Language     string                 `json:"language"`
+	Name         string                 `json:"name"`
+	Path         string                 `json:"path"`
+	RiskScore    int                    `json:"riskScore"`
+	BaseCoverage string                 `json:"baseCoverage,omitempty"` // DONE|PARTIAL|MISSING
+	HeadCoverage string                 `json:"headCoverage,omitempty"`
+}
+
+// PromptDeltaV1 compares the file inventories of two MEGAPROMPT reports.
+type PromptDeltaV1 struct {
+	// Comparison is how files present on both sides were compared: "sha256" when both
+	// artifacts carry a manifest, otherwise "size", which misses same-size edits.
+	Comparison string `json:"comparison"`
+
+	Added     []project.FileRefV1 `json:"added"`
+	Removed   []project.FileRefV1 `json:"removed"`
+	Changed   []FileChangeV1      `json:"changed"` // same path, different content (or size)
+	Unchanged int                 `json:"unchanged"`
+}
+
+const (
+	ComparisonSHA256 = "sha256"
+	ComparisonSize   = "size"
+)
+
+type FileChangeV1 struct {
+	Path       string `json:"path"`
+	BaseBytes  int64  `json:"baseBytes"`
+	HeadBytes  int64  `json:"headBytes"`
+	BaseSHA256 string `json:"baseSha256,omitempty"`
+	HeadSHA256 string `json:"headSha256,omitempty"`
+}
+
+// ToXML renders the delta as pseudo-XML and embeds the prompt text.
+func (r ArtifactDiffReportV1) ToXML(prompt string) string {
+	esc := contractartifact.EscapeAttr
+	var parts []string
+	parts = append(parts, "<artifact_diff generatedAt=\""+esc(r.GeneratedAt)+"\" tool=\""+esc(r.ToolPrefix)+"\">")
+	parts = append(parts, "  <base path=\""+esc(r.Base.Path)+"\" generatedAt=\""+esc(r.Base.GeneratedAt)+"\" />")
+	parts = append(parts, "  <head path=\""+esc(r.Head.Path)+"\" generatedAt=\""+esc(r.Head.GeneratedAt)+"\" />")
+
+	if d := r.Diagnostics; d != nil {
+		writeIssues := func(tag string, issues []diagnose.DiagnosticV1) {
+			parts = append(parts, "  <"+tag+" count=\""+itoa(len(issues))+"\">")
+			for _, x := range issues {
+				line := ""
+				if x.Line != nil {
+					line = itoa(*x.Line)
+				}
+				parts = append(parts, "    <issue tool=\""+esc(x.Tool)+"\" file=\""+esc(x.File)+"\" line=\""+esc(line)+"\" severity=\""+esc(string(x.Severity))+"\" code=\""+esc(x.Code)+"\"><![CDATA["+x.Message+"]]></issue>")
+			}
+			parts = append(parts, "  </"+tag+">")
+		}
+		writeIssues("new", d.New)
+		writeIssues("fixed", d.Fixed)
+	}
+
+	if t := r.TestPlan; t != nil {
+		writeSubjects := func(tag string, subjects []SubjectDeltaV1) {
+			parts = append(parts, "  <"+tag+" count=\""+itoa(len(subjects))+"\">")
+			for _, s := range subjects {
+				parts = append(parts, "    <subject id=\""+esc(s.ID)+"\" kind=\""+esc(string(s.Kind))+"\" path=\""+esc(s.Path)+"\" risk=\""+itoa(s.RiskScore)+"\" base=\""+esc(s.BaseCoverage)+"\" head=\""+esc(s.HeadCoverage)+"\" />")
+			}
+			parts = append(parts, "  </"+tag+">")
+		}
+		writeSubjects("new_uncovered", t.NewUncovered)
+		writeSubjects("now_covered", t.NowCovered)
+		writeSubjects("added_subjects", t.AddedSubjects)
+		writeSubjects("removed_subjects", t.RemovedSubjects)
+	}
+
+	if p := r.Prompt; p != nil {
+		writeFiles := func(tag string, files []project.FileRefV1) {
+			parts = append(parts, "  <"+tag+" count=\""+itoa(len(files))+"\">")
+			for _, f := range files {
+				parts = append(parts, "    <file path=\""+esc(f.RelPath)+"\" bytes=\""+itoa(int(f.SizeBytes))+"\" />")
+			}
+			parts = append(parts, "  </"+tag+">")
+		}
+		writeFiles("added", p.Added)
+		writeFiles("removed", p.Removed)
+		parts = append(parts, "  <changed count=\""+itoa(len(p.Changed))+"\" comparison=\""+esc(p.Comparison)+"\">")
+		for _, c := range p.Changed {
+			hashes := ""
+			if c.BaseSHA256 != "" || c.HeadSHA256 != "" {
+				hashes = " baseSha256=\"" + esc(c.BaseSHA256) + "\" headSha256=\"" + esc(c.HeadSHA256) + "\""
+			}
+			parts = append(parts, "    <file path=\""+esc(c.Path)+"\" baseBytes=\""+itoa(int(c.BaseBytes))+"\" headBytes=\""+itoa(int(c.HeadBytes))+"\""+hashes+" />")
+		}
+		parts = append(parts, "  </changed>")
+	}
+
+	s := r.Summary
+	parts = append(parts, "  <summary added=\""+itoa(s.Added)+"\" removed=\""+itoa(s.Removed)+"\" changed=\""+itoa(s.Changed)+"\" unchanged=\""+itoa(s.Unchanged)+"\" />")
+
+	if len(r.Warnings) > 0 {
+		parts = append(parts, "  <warnings>")
+		for _, w := range r.Warnings {
+			parts = append(parts, "    <warning><![CDATA["+w+"]]></warning>")
+		}
+		parts = append(parts, "  </warnings>")
+	}
+
+	parts = append(parts, "  <prompt>")
+	parts = append(parts, "    <![CDATA["+prompt+"]]>")
+	parts = append(parts, "  </prompt>")
+	parts = append(parts, "</artifact_diff>")
+	return strings.Join(parts, "\n")
+}
+
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	var buf [32]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = byte('0' + (n % 10))
+		n /= 10
+	}
+	return sign + string(buf[i:])
+}
@@ -16,26 +16,65 @@ const (
 )
 
 type DiagnosticV1 struct {
-	Tool     string     `json:"tool"`
-	Language string     `json:"language"`
-	File     string     `json:"file"`
-	Line     *int       `json:"line,omitempty"`
-	Column   *int       `json:"column,omitempty"`
-	Code     string     `json:"code,omitempty"`
-	Severity SeverityV1 `json:"severity"`
-	Message  string     `json:"message"`
+	Tool      string     `json:"tool"`
+	Language  string     `json:"language"`
+	File      string     `json:"file"`
+	Line      *int       `json:"line,omitempty"`
+	Column    *int       `json:"column,omitempty"`
+	EndLine   *int       `json:"endLine,omitempty"` // end of the flagged range, when the tool reports one
+	EndColumn *int       `json:"endColumn,omitempty"`
+	Code      string     `json:"code,omitempty"` // rule/analyzer, e.g. TS2304, printf, SA4006
+	Severity  SeverityV1 `json:"severity"`
+	Message   string     `json:"message"`
+
+	// Fixable means the tool can apply its fix itself (e.g. eslint --fix).
+	Fixable bool `json:"fixable,omitempty"`
+	// Suggestions are fixes the tool itself proposes (e.g. rustc's suggested_replacement).
+	Suggestions []SuggestionV1 `json:"suggestions,omitempty"`
+	// Related are secondary locations and notes that explain the issue.
+	Related []RelatedV1 `json:"related,omitempty"`
+}
+
+// SuggestionV1 replaces the given range with Replacement (an empty Replacement deletes it).
+type SuggestionV1 struct {
+	Message       string `json:"message,omitempty"`
+	File          string `json:"file"`
+	Line          *int   `json:"line,omitempty"`
+	Column        *int   `json:"column,omitempty"`
+	EndLine       *int   `json:"endLine,omitempty"`
+	EndColumn     *int   `json:"endColumn,omitempty"`
+	Replacement   string `json:"replacement"`
+	Applicability string `json:"applicability,omitempty"` // e.g. MachineApplicable, MaybeIncorrect
+}
+
+// RelatedV1 is a note attached to a diagnostic, with a location when it has one.
+type RelatedV1 struct {
+	File      string `json:"file,omitempty"`
+	Line      *int   `json:"line,omitempty"`
+	Column    *int   `json:"column,omitempty"`
+	EndLine   *int   `json:"endLine,omitempty"`
+	EndColumn *int   `json:"endColumn,omitempty"`
+	Message   string `json:"message"`
 }
 
 type LanguageDiagnosticsV1 struct {
-	Name   string         `json:"name"`
-	Tool   string         `json:"tool"`
-	Issues []DiagnosticV1 `json:"issues"`
+	Name    string         `json:"name"`
+	Tool    string         `json:"tool"`
+	Package string         `json:"package,omitempty"` // workspace member the tool ran in (file paths stay root-relative)
+	Issues  []DiagnosticV1 `json:"issues"`
 }
 
 type DiagnosticsReportV1 struct {
 	Languages   []LanguageDiagnosticsV1 `json:"languages"`
 	GeneratedAt string                  `json:"generatedAt"` // RFC3339Nano UTC
 	Warnings    []string                `json:"warnings,omitempty"`
+	Offline     *OfflineV1              `json:"offline,omitempty"` // set when network access was disabled
+}
+
+// OfflineV1 records how toolchains were kept off the network when --net was not given.
+type OfflineV1 struct {
+	Forced  []string `json:"forced"`  // tools run with offline flags/env, e.g. "cargo check --offline"
+	Skipped []string `json:"skipped"` // tools not run because they would have had to download
 }
 
 // ToXML renders pseudo-XML diagnostics output and embeds the fix prompt text.
@@ -44,7 +83,11 @@ func (r DiagnosticsReportV1) ToXML(fixPrompt string) string {
 	parts = append(parts, "<diagnostics generatedAt=\""+contractartifact.EscapeAttr(r.GeneratedAt)+"\">")
 
 	for _, ld := range r.Languages {
-		parts = append(parts, "  <language name=\""+contractartifact.EscapeAttr(ld.Name)+"\" tool=\""+contractartifact.EscapeAttr(ld.Tool)+"\">")
+		pkgAttr := ""
+		if ld.Package != "" {
+			pkgAttr = " package=\"" + contractartifact.EscapeAttr(ld.Package) + "\""
+		}
+		parts = append(parts, "  <language name=\""+contractartifact.EscapeAttr(ld.Name)+"\" tool=\""+contractartifact.EscapeAttr(ld.Tool)+"\""+pkgAttr+">")
 		for _, d := range ld.Issues {
 			line := ""
 			col := ""
@@ -55,9 +98,25 @@ func (r DiagnosticsReportV1) ToXML(fixPrompt string) string {
 				col = itoa(*d.Column)
 			}
 			code := d.Code
+			extraAttrs := ""
+			if d.EndLine != nil {
+				extraAttrs += " endLine=\"" + itoa(*d.EndLine) + "\""
+			}
+			if d.EndColumn != nil {
+				extraAttrs += " endColumn=\"" + itoa(*d.EndColumn) + "\""
+			}
+			if d.Fixable {
+				extraAttrs += " fixable=\"true\""
+			}
 			parts = append(parts,
-				"    <issue file=\""+contractartifact.EscapeAttr(d.File)+"\" line=\""+contractartifact.EscapeAttr(line)+"\" column=\""+contractartifact.EscapeAttr(col)+"\" severity=\""+contractartifact.EscapeAttr(string(d.Severity))+"\" code=\""+contractartifact.EscapeAttr(code)+"\">")
+				"    <issue file=\""+contractartifact.EscapeAttr(d.File)+"\" line=\""+contractartifact.EscapeAttr(line)+"\" column=\""+contractartifact.EscapeAttr(col)+"\""+extraAttrs+" severity=\""+contractartifact.EscapeAttr(string(d.Severity))+"\" code=\""+contractartifact.EscapeAttr(code)+"\" tool=\""+contractartifact.EscapeAttr(d.Tool)+"\">")
 			parts = append(parts, "      <![CDATA["+d.Message+"]]>")
+			for _, rel := range d.Related {
+				parts = append(parts, "      <related"+positionAttrs(rel.File, rel.Line, rel.Column, rel.EndLine, rel.EndColumn)+"><![CDATA["+rel.Message+"]]></related>")
+			}
+			for _, s := range d.Suggestions {
+				parts = append(parts, "      <suggestion"+positionAttrs(s.File, s.Line, s.Column, s.EndLine, s.EndColumn)+" applicability=\""+contractartifact.EscapeAttr(s.Applicability)+"\" message=\""+contractartifact.EscapeAttr(s.Message)+"\"><![CDATA["+s.Replacement+"]]></suggestion>")
+			}
 			parts = append(parts, "    </issue>")
 		}
 		errs := 0
@@ -80,6 +139,17 @@ func (r DiagnosticsReportV1) ToXML(fixPrompt string) string {
 	}
 	parts = append(parts, "  <summary total_languages=\""+itoa(len(r.Languages))+"\" total_issues=\""+itoa(totalIssues)+"\" />")
 
+	if o := r.Offline; o != nil {
+		parts = append(parts, "  <offline>")
+		for _, t := range o.Forced {
+			parts = append(parts, "    <forced tool=\""+contractartifact.EscapeAttr(t)+"\" />")
+		}
+		for _, t := range o.Skipped {
+			parts = append(parts, "    <skipped tool=\""+contractartifact.EscapeAttr(t)+"\" />")
+		}
+		parts = append(parts, "  </offline>")
+	}
+
 	if len(r.Warnings) > 0 {
 		parts = append(parts, "  <warnings>")
 		for _, w := range r.Warnings {
@@ -96,6 +166,23 @@ func (r DiagnosticsReportV1) ToXML(fixPrompt string) string {
 	return strings.Join(parts, "\n")
 }
 
+// positionAttrs renders file/line/column attributes, leaving out the ones that are unset.
+func positionAttrs(file string, line, column, endLine, endColumn *int) string {
+	s := ""
+	if file != "" {
+		s += " file=\"" + contractartifact.EscapeAttr(file) + "\""
+	}
+	for _, a := range []struct {
+		name string
+		v    *int
+	}{{"line", line}, {"column", column}, {"endLine", endLine}, {"endColumn", endColumn}} {
+		if a.v != nil {
+			s += " " + a.name + "=\"" + itoa(*a.v) + "\""
+		}
+	}
+	return s
+}
+
 func itoa(n int) string {
 	if n == 0 {
 		return "0"
@@ -22,6 +22,14 @@ type DocImportV1 struct {
 	ResolvedPath string `json:"resolvedPath,omitempty"` // POSIX relpath if internal resolution succeeded
 }
 
+// DocPackageV1 is a monorepo workspace member of the documented root.
+type DocPackageV1 struct {
+	Name      string   `json:"name"`
+	RelPath   string   `json:"relPath"` // POSIX path from the root
+	Kind      string   `json:"kind"`    // go|npm|pnpm|cargo|gradle
+	Languages []string `json:"languages,omitempty"`
+}
+
 type FetchedDocV1 struct {
 	URI            string `json:"uri"`
 	Title          string `json:"title"`
@@ -35,6 +43,7 @@ type DocReportV1 struct {
 	Mode                 DocModeV1      `json:"mode"`
 	RootPath             string         `json:"rootPath"`
 	Languages            []string       `json:"languages"`
+	Packages             []DocPackageV1 `json:"packages,omitempty"`
 	DirectoryTree        string         `json:"directoryTree"`
 	ImportGraph          string         `json:"importGraph"`
 	Imports              []DocImportV1  `json:"imports"`
@@ -64,6 +73,14 @@ func (r DocReportV1) ToXML() string {
 		parts = append(parts, "  </languages>")
 	}
 
+	if len(r.Packages) > 0 {
+		parts = append(parts, "  <packages>")
+		for _, p := range r.Packages {
+			parts = append(parts, "    <package name=\""+contractartifact.EscapeAttr(p.Name)+"\" path=\""+contractartifact.EscapeAttr(p.RelPath)+"\" kind=\""+contractartifact.EscapeAttr(p.Kind)+"\" languages=\""+contractartifact.EscapeAttr(strings.Join(p.Languages, ","))+"\"/>")
+		}
+		parts = append(parts, "  </packages>")
+	}
+
 	parts = append(parts, "  <directory_tree><![CDATA[\n"+r.DirectoryTree+"\n]]></directory_tree>")
 	parts = append(parts, "  <import_graph><![CDATA[\n"+r.ImportGraph+"\n]]></import_graph>")
 
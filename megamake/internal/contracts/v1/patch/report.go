@@ -0,0 +1,135 @@
+package patch
+
+import (
+	"strings"
+
+	contractartifact "github.com/megamake/megamake/internal/contracts/v1/artifact"
+)
+
+// SyntaxV1 is the agent-facing description of the MegaPatch v1 script format.
+// Prompts that ask a model for a MegaPatch embed this text verbatim so the
+// parser and the instructions cannot drift apart.
+const SyntaxV1 = `MegaPatch v1 syntax:
+# MegaPatch v1
+write path/to/file.ext <<'EOF'
+...full new file content...
+EOF
+delete path/to/old_file.ext
+- Paths are POSIX relative paths under the project root (no absolute paths, no "..").
+- "write" creates or fully overwrites a file; the heredoc delimiter must be on its own line.
+- "delete" removes a single file and only runs with explicit user consent (--allow-delete).
+- Lines starting with # are comments.`
+
+type PatchStatusV1 string
+
+const (
+	PatchStatusDryRun     PatchStatusV1 = "dry-run"
+	PatchStatusApplied    PatchStatusV1 = "applied"
+	PatchStatusBlocked    PatchStatusV1 = "blocked"
+	PatchStatusRolledBack PatchStatusV1 = "rolled-back"
+)
+
+type PatchOpKindV1 string
+
+const (
+	PatchOpWrite  PatchOpKindV1 = "write"
+	PatchOpDelete PatchOpKindV1 = "delete"
+)
+
+// PatchOpV1 is one planned (or applied) operation from a MegaPatch script.
+type PatchOpV1 struct {
+	Op     PatchOpKindV1 `json:"op"`
+	Path   string        `json:"path"`   // POSIX relpath
+	Action string        `json:"action"` // create|modify|unchanged|delete|missing
+	Line   int           `json:"line"`   // 1-based line of the statement in the script
+
+	BytesBefore  int64 `json:"bytesBefore"`
+	BytesAfter   int64 `json:"bytesAfter"`
+	LinesAdded   int   `json:"linesAdded"`
+	LinesRemoved int   `json:"linesRemoved"`
+
+	Diff string `json:"diff,omitempty"` // unified diff preview
+}
+
+type PatchSummaryV1 struct {
+	Operations   int `json:"operations"`
+	Created      int `json:"created"`
+	Modified     int `json:"modified"`
+	Deleted      int `json:"deleted"`
+	Unchanged    int `json:"unchanged"`
+	LinesAdded   int `json:"linesAdded"`
+	LinesRemoved int `json:"linesRemoved"`
+}
+
+// PatchReportV1 is the v1 contract for MegaPatch output.
+type PatchReportV1 struct {
+	GeneratedAt string         `json:"generatedAt"` // RFC3339Nano UTC
+	RootPath    string         `json:"rootPath"`
+	Source      string         `json:"source"` // script path or "stdin"
+	Status      PatchStatusV1  `json:"status"`
+	DryRun      bool           `json:"dryRun"`
+	Operations  []PatchOpV1    `json:"operations"`
+	Summary     PatchSummaryV1 `json:"summary"`
+	Error       string         `json:"error,omitempty"`
+	Warnings    []string       `json:"warnings,omitempty"`
+}
+
+// ToXML renders the patch report as pseudo-XML with embedded diff previews.
+func (r PatchReportV1) ToXML() string {
+	var parts []string
+	parts = append(parts, "<patch generatedAt=\""+contractartifact.EscapeAttr(r.GeneratedAt)+"\" status=\""+contractartifact.EscapeAttr(string(r.Status))+"\" dryRun=\""+boolAttr(r.DryRun)+"\">")
+	parts = append(parts, "  <root><![CDATA["+r.RootPath+"]]></root>")
+	parts = append(parts, "  <source><![CDATA["+r.Source+"]]></source>")
+
+	for _, op := range r.Operations {
+		parts = append(parts, "  <operation op=\""+contractartifact.EscapeAttr(string(op.Op))+"\" path=\""+contractartifact.EscapeAttr(op.Path)+"\" action=\""+contractartifact.EscapeAttr(op.Action)+"\" line=\""+itoa(op.Line)+"\" added=\""+itoa(op.LinesAdded)+"\" removed=\""+itoa(op.LinesRemoved)+"\">")
+		if strings.TrimSpace(op.Diff) != "" {
+			diff := strings.ReplaceAll(op.Diff, "]]>", "]]]]><![CDATA[>")
+			parts = append(parts, "    <diff><![CDATA[\n"+strings.TrimSuffix(diff, "\n")+"\n]]></diff>")
+		}
+		parts = append(parts, "  </operation>")
+	}
+
+	s := r.Summary
+	parts = append(parts, "  <summary operations=\""+itoa(s.Operations)+"\" created=\""+itoa(s.Created)+"\" modified=\""+itoa(s.Modified)+"\" deleted=\""+itoa(s.Deleted)+"\" unchanged=\""+itoa(s.Unchanged)+"\" added=\""+itoa(s.LinesAdded)+"\" removed=\""+itoa(s.LinesRemoved)+"\"/>")
+
+	if strings.TrimSpace(r.Error) != "" {
+		parts = append(parts, "  <error><![CDATA["+r.Error+"]]></error>")
+	}
+	if len(r.Warnings) > 0 {
+		parts = append(parts, "  <warnings>")
+		for _, w := range r.Warnings {
+			parts = append(parts, "    <warning><![CDATA["+w+"]]></warning>")
+		}
+		parts = append(parts, "  </warnings>")
+	}
+
+	parts = append(parts, "</patch>")
+	return strings.Join(parts, "\n")
+}
+
+func boolAttr(v bool) string {
+	if v {
+		return "true"
+	}
+	return "false"
+}
+
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	var buf [32]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = byte('0' + (n % 10))
+		n /= 10
+	}
+	return sign + string(buf[i:])
+}
@@ -5,4 +5,22 @@ type FileRefV1 struct {
 	RelPath   string `json:"relPath"`   // POSIX style (e.g., "src/main.go")
 	SizeBytes int64  `json:"sizeBytes"` // best-effort size from filesystem
 	IsTest    bool   `json:"isTest"`
+	Encoding  string `json:"encoding,omitempty"` // source encoding when transcoded to UTF-8 (e.g. "utf-16le", "windows-1252")
+}
+
+// IgnoredPathV1 explains why the scanner skipped a path: the ignore file line that excluded it.
+type IgnoredPathV1 struct {
+	RelPath string `json:"relPath"` // POSIX relpath; directories end with "/" (their contents were not scanned)
+	Source  string `json:"source"`  // ignore file relpath, e.g. ".gitignore" or "web/.megamakeignore"
+	Line    int    `json:"line"`
+	Pattern string `json:"pattern"`
+}
+
+// RelPaths returns the relpaths of files, in order.
+func RelPaths(files []FileRefV1) []string {
+	out := make([]string, 0, len(files))
+	for _, f := range files {
+		out = append(out, f.RelPath)
+	}
+	return out
 }
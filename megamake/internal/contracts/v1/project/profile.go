@@ -9,4 +9,17 @@ type ProjectProfileV1 struct {
 	Markers       []string `json:"markers,omitempty"` // relative paths that proved existence
 	IsCodeProject bool     `json:"isCodeProject"`
 	Why           []string `json:"why,omitempty"` // human-friendly evidence lines
+
+	// Workspaces are the monorepo workspace files found at the root (e.g. "go.work", "Cargo.toml").
+	Workspaces []string `json:"workspaces,omitempty"`
+	// Members are the workspace member packages, each profiled on its own.
+	Members []WorkspaceMemberV1 `json:"members,omitempty"`
+}
+
+// WorkspaceMemberV1 is one package of a monorepo workspace.
+type WorkspaceMemberV1 struct {
+	Name    string           `json:"name"`    // declared package/module name, else the relpath
+	RelPath string           `json:"relPath"` // POSIX path from the workspace root ("." for the root itself)
+	Kind    string           `json:"kind"`    // go|npm|pnpm|cargo|gradle
+	Profile ProjectProfileV1 `json:"profile"` // RootPath is the member directory
 }
@@ -16,5 +16,82 @@ type PromptReportV1 struct {
 	TotalBytes    int64               `json:"totalBytes"`
 	Files         []project.FileRefV1 `json:"files"`
 
+	// Ignored lists paths excluded by .gitignore/.megamakeignore rules (only with --explain-ignores).
+	Ignored []project.IgnoredPathV1 `json:"ignored,omitempty"`
+
+	// Format is the context blob format: pseudo-xml|xml|markdown|json.
+	Format string `json:"format"`
+
+	// Focus is set when the context was limited to a file/symbol neighborhood (--focus).
+	Focus *FocusReportV1 `json:"focus,omitempty"`
+
+	// Diff is set when the context was limited to changed files (--since/--range).
+	Diff *DiffScopeV1 `json:"diff,omitempty"`
+
+	// Token budget (only set when --max-tokens is used).
+	// EstimatedTokens is the estimate for the final <context> blob.
+	MaxTokens       int             `json:"maxTokens,omitempty"`
+	EstimatedTokens int             `json:"estimatedTokens,omitempty"`
+	Dropped         []DroppedFileV1 `json:"dropped,omitempty"`
+
+	// Skeletonized lists files whose function bodies were elided (--skeleton).
+	Skeletonized []string `json:"skeletonized,omitempty"`
+
+	// Split is set when the context was written as numbered part files (--split-bytes/--split-tokens).
+	Split *SplitReportV1 `json:"split,omitempty"`
+
 	Warnings []string `json:"warnings,omitempty"`
 }
+
+// DroppedFileV1 is a scanned file left out of the <context> blob to stay within the token budget.
+type DroppedFileV1 struct {
+	Path     string `json:"path"`     // POSIX relpath
+	Tokens   int    `json:"tokens"`   // estimated tokens the file would have cost
+	Priority string `json:"priority"` // ranking bucket: manifest|entrypoint|readme|imported|other
+	Reason   string `json:"reason"`
+}
+
+// SplitReportV1 is the manifest of a context split into part files.
+type SplitReportV1 struct {
+	Unit  string         `json:"unit"`  // bytes|tokens
+	Limit int            `json:"limit"` // per-part limit in Unit, including the part header
+	Parts []PromptPartV1 `json:"parts"`
+}
+
+// PromptPartV1 is one part file. Files are never cut across parts.
+type PromptPartV1 struct {
+	Index int      `json:"index"` // 1-based
+	File  string   `json:"file"`  // part filename, next to the main artifact
+	Size  int      `json:"size"`  // in the split unit
+	Files []string `json:"files"` // POSIX relpaths in this part
+	Diff  bool     `json:"diff,omitempty"`
+}
+
+// DiffScopeV1 describes a git-diff-scoped context.
+type DiffScopeV1 struct {
+	Mode         string   `json:"mode"` // since|range
+	Ref          string   `json:"ref"`  // the --since ref or --range spec
+	ChangedFiles []string `json:"changedFiles"`
+	Neighbors    []string `json:"neighbors,omitempty"` // direct import neighbors pulled in for context
+	Missing      []string `json:"missing,omitempty"`   // changed but not in context (deleted, ignored, binary)
+	DiffBytes    int      `json:"diffBytes"`
+}
+
+// FocusReportV1 describes a --focus traversal over the internal import graph.
+type FocusReportV1 struct {
+	Target     string        `json:"target"`
+	TargetKind string        `json:"targetKind"` // file|dir|symbol
+	Depth      int           `json:"depth"`
+	Dependents bool          `json:"dependents"`
+	Roots      []string      `json:"roots"` // files matching the target
+	Nodes      []FocusNodeV1 `json:"nodes"` // breadth-first order, roots first
+	Tree       string        `json:"tree"`  // human-friendly traversal tree
+}
+
+// FocusNodeV1 is one file reached by the traversal.
+type FocusNodeV1 struct {
+	Path   string `json:"path"` // POSIX relpath
+	Depth  int    `json:"depth"`
+	Parent string `json:"parent,omitempty"`
+	Edge   string `json:"edge"` // focus|imports|imported-by
+}
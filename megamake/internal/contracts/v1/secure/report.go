@@ -0,0 +1,151 @@
+package secure
+
+import (
+	"strings"
+
+	contractartifact "github.com/megamake/megamake/internal/contracts/v1/artifact"
+)
+
+type SeverityV1 string
+
+const (
+	SeverityCritical SeverityV1 = "critical"
+	SeverityHigh     SeverityV1 = "high"
+	SeverityMedium   SeverityV1 = "medium"
+	SeverityLow      SeverityV1 = "low"
+)
+
+// SeverityRank orders severities from most (0) to least severe; unknown values sort last.
+func SeverityRank(s SeverityV1) int {
+	switch s {
+	case SeverityCritical:
+		return 0
+	case SeverityHigh:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityLow:
+		return 3
+	default:
+		return 4
+	}
+}
+
+type CategoryV1 string
+
+const (
+	CategorySecret     CategoryV1 = "secret"
+	CategoryPrivateKey CategoryV1 = "private-key"
+	CategoryWeakCrypto CategoryV1 = "weak-crypto"
+	CategoryUnsafeExec CategoryV1 = "unsafe-exec"
+	CategoryUnsafeSQL  CategoryV1 = "unsafe-sql"
+)
+
+// FindingV1 is one match of a security rule.
+// Snippet never contains the raw secret value: secret-like matches are redacted.
+type FindingV1 struct {
+	RuleID      string     `json:"ruleId"`
+	Category    CategoryV1 `json:"category"`
+	Severity    SeverityV1 `json:"severity"`
+	Language    string     `json:"language,omitempty"`
+	File        string     `json:"file"` // POSIX relpath
+	Line        int        `json:"line"`
+	Column      int        `json:"column"`
+	Message     string     `json:"message"`
+	Snippet     string     `json:"snippet,omitempty"`
+	Remediation string     `json:"remediation,omitempty"`
+	IsTest      bool       `json:"isTest"`
+}
+
+type SecureSummaryV1 struct {
+	Total      int `json:"total"`
+	Critical   int `json:"critical"`
+	High       int `json:"high"`
+	Medium     int `json:"medium"`
+	Low        int `json:"low"`
+	Suppressed int `json:"suppressed"` // matches silenced by an inline nosec marker
+
+	ByCategory map[string]int `json:"byCategory,omitempty"`
+}
+
+// SecureReportV1 is the v1 contract for MegaSecure output.
+type SecureReportV1 struct {
+	GeneratedAt   string          `json:"generatedAt"` // RFC3339Nano UTC
+	RootPath      string          `json:"rootPath"`
+	FilesScanned  int             `json:"filesScanned"`
+	FilesAnalyzed int             `json:"filesAnalyzed"`
+	Findings      []FindingV1     `json:"findings"`
+	Summary       SecureSummaryV1 `json:"summary"`
+	Warnings      []string        `json:"warnings,omitempty"`
+}
+
+// ToXML renders pseudo-XML security findings grouped by file.
+func (r SecureReportV1) ToXML() string {
+	var parts []string
+	parts = append(parts, "<secure generatedAt=\""+contractartifact.EscapeAttr(r.GeneratedAt)+"\" filesScanned=\""+itoa(r.FilesScanned)+"\" filesAnalyzed=\""+itoa(r.FilesAnalyzed)+"\">")
+	parts = append(parts, "  <root><![CDATA["+r.RootPath+"]]></root>")
+
+	currentFile := ""
+	for _, f := range r.Findings {
+		if f.File != currentFile {
+			if currentFile != "" {
+				parts = append(parts, "  </file>")
+			}
+			currentFile = f.File
+			parts = append(parts, "  <file path=\""+contractartifact.EscapeAttr(f.File)+"\">")
+		}
+		parts = append(parts,
+			"    <finding rule=\""+contractartifact.EscapeAttr(f.RuleID)+"\" category=\""+contractartifact.EscapeAttr(string(f.Category))+"\" severity=\""+contractartifact.EscapeAttr(string(f.Severity))+"\" line=\""+itoa(f.Line)+"\" column=\""+itoa(f.Column)+"\" test=\""+boolAttr(f.IsTest)+"\">")
+		parts = append(parts, "      <message><![CDATA["+f.Message+"]]></message>")
+		if strings.TrimSpace(f.Snippet) != "" {
+			parts = append(parts, "      <snippet><![CDATA["+strings.ReplaceAll(f.Snippet, "]]>", "]]]]><![CDATA[>")+"]]></snippet>")
+		}
+		if strings.TrimSpace(f.Remediation) != "" {
+			parts = append(parts, "      <remediation><![CDATA["+f.Remediation+"]]></remediation>")
+		}
+		parts = append(parts, "    </finding>")
+	}
+	if currentFile != "" {
+		parts = append(parts, "  </file>")
+	}
+
+	s := r.Summary
+	parts = append(parts, "  <summary total=\""+itoa(s.Total)+"\" critical=\""+itoa(s.Critical)+"\" high=\""+itoa(s.High)+"\" medium=\""+itoa(s.Medium)+"\" low=\""+itoa(s.Low)+"\" suppressed=\""+itoa(s.Suppressed)+"\" />")
+
+	if len(r.Warnings) > 0 {
+		parts = append(parts, "  <warnings>")
+		for _, w := range r.Warnings {
+			parts = append(parts, "    <warning><![CDATA["+w+"]]></warning>")
+		}
+		parts = append(parts, "  </warnings>")
+	}
+
+	parts = append(parts, "</secure>")
+	return strings.Join(parts, "\n")
+}
+
+func boolAttr(v bool) string {
+	if v {
+		return "true"
+	}
+	return "false"
+}
+
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	var buf [32]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = byte('0' + (n % 10))
+		n /= 10
+	}
+	return sign + string(buf[i:])
+}
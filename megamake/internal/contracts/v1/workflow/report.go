@@ -0,0 +1,150 @@
+package workflow
+
+import (
+	"sort"
+	"strings"
+
+	contractartifact "github.com/megamake/megamake/internal/contracts/v1/artifact"
+)
+
+type StepTypeV1 string
+
+const (
+	StepPrompt   StepTypeV1 = "prompt"
+	StepDoc      StepTypeV1 = "doc"
+	StepDiagnose StepTypeV1 = "diagnose"
+	StepTest     StepTypeV1 = "test"
+	StepSecure   StepTypeV1 = "secure"
+	StepChat     StepTypeV1 = "chat"
+	StepPatch    StepTypeV1 = "patch"
+)
+
+// KnownStepTypes lists every supported step type in documentation order.
+func KnownStepTypes() []StepTypeV1 {
+	return []StepTypeV1{StepPrompt, StepDoc, StepDiagnose, StepTest, StepSecure, StepChat, StepPatch}
+}
+
+type StepStatusV1 string
+
+const (
+	StepOK      StepStatusV1 = "ok"
+	StepFailed  StepStatusV1 = "failed"
+	StepSkipped StepStatusV1 = "skipped"
+)
+
+type WorkflowStatusV1 string
+
+const (
+	WorkflowOK     WorkflowStatusV1 = "ok"
+	WorkflowFailed WorkflowStatusV1 = "failed"
+)
+
+// StepResultV1 records how one workflow step ran.
+// Outputs holds the step's small scalar outputs (counts, paths, statuses);
+// large text outputs such as prompts are summarized by size to keep the report compact.
+type StepResultV1 struct {
+	ID         string            `json:"id"`
+	Type       StepTypeV1        `json:"type"`
+	Status     StepStatusV1      `json:"status"`
+	Condition  string            `json:"condition,omitempty"`
+	SkipReason string            `json:"skipReason,omitempty"`
+	Attempts   int               `json:"attempts"`
+	StartedAt  string            `json:"startedAt,omitempty"` // RFC3339Nano UTC
+	DurationMs int64             `json:"durationMs"`
+	Artifact   string            `json:"artifact,omitempty"`
+	Outputs    map[string]string `json:"outputs,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+type WorkflowSummaryV1 struct {
+	Total   int `json:"total"`
+	OK      int `json:"ok"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+// WorkflowReportV1 is the v1 contract for `megamake make` output.
+type WorkflowReportV1 struct {
+	GeneratedAt  string            `json:"generatedAt"` // RFC3339Nano UTC
+	RootPath     string            `json:"rootPath"`
+	WorkflowFile string            `json:"workflowFile"`
+	Name         string            `json:"name,omitempty"`
+	Status       WorkflowStatusV1  `json:"status"`
+	Steps        []StepResultV1    `json:"steps"`
+	Summary      WorkflowSummaryV1 `json:"summary"`
+	Warnings     []string          `json:"warnings,omitempty"`
+}
+
+// ToXML renders the workflow run as pseudo-XML, one <step> per workflow step.
+func (r WorkflowReportV1) ToXML() string {
+	var parts []string
+	parts = append(parts, "<workflow generatedAt=\""+contractartifact.EscapeAttr(r.GeneratedAt)+"\" name=\""+contractartifact.EscapeAttr(r.Name)+"\" status=\""+contractartifact.EscapeAttr(string(r.Status))+"\">")
+	parts = append(parts, "  <root><![CDATA["+r.RootPath+"]]></root>")
+	parts = append(parts, "  <file><![CDATA["+r.WorkflowFile+"]]></file>")
+
+	for _, st := range r.Steps {
+		parts = append(parts, "  <step id=\""+contractartifact.EscapeAttr(st.ID)+"\" type=\""+contractartifact.EscapeAttr(string(st.Type))+"\" status=\""+contractartifact.EscapeAttr(string(st.Status))+"\" attempts=\""+itoa(st.Attempts)+"\" durationMs=\""+itoa64(st.DurationMs)+"\">")
+		if strings.TrimSpace(st.Condition) != "" {
+			parts = append(parts, "    <condition><![CDATA["+st.Condition+"]]></condition>")
+		}
+		if strings.TrimSpace(st.SkipReason) != "" {
+			parts = append(parts, "    <skip_reason><![CDATA["+st.SkipReason+"]]></skip_reason>")
+		}
+		if strings.TrimSpace(st.Artifact) != "" {
+			parts = append(parts, "    <artifact><![CDATA["+st.Artifact+"]]></artifact>")
+		}
+		if len(st.Outputs) > 0 {
+			keys := make([]string, 0, len(st.Outputs))
+			for k := range st.Outputs {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			parts = append(parts, "    <outputs>")
+			for _, k := range keys {
+				parts = append(parts, "      <output name=\""+contractartifact.EscapeAttr(k)+"\"><![CDATA["+st.Outputs[k]+"]]></output>")
+			}
+			parts = append(parts, "    </outputs>")
+		}
+		if strings.TrimSpace(st.Error) != "" {
+			parts = append(parts, "    <error><![CDATA["+st.Error+"]]></error>")
+		}
+		parts = append(parts, "  </step>")
+	}
+
+	s := r.Summary
+	parts = append(parts, "  <summary total=\""+itoa(s.Total)+"\" ok=\""+itoa(s.OK)+"\" failed=\""+itoa(s.Failed)+"\" skipped=\""+itoa(s.Skipped)+"\" />")
+
+	if len(r.Warnings) > 0 {
+		parts = append(parts, "  <warnings>")
+		for _, w := range r.Warnings {
+			parts = append(parts, "    <warning><![CDATA["+w+"]]></warning>")
+		}
+		parts = append(parts, "  </warnings>")
+	}
+
+	parts = append(parts, "</workflow>")
+	return strings.Join(parts, "\n")
+}
+
+func itoa(n int) string {
+	return itoa64(int64(n))
+}
+
+func itoa64(n int64) string {
+	if n == 0 {
+		return "0"
+	}
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	var buf [32]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = byte('0' + (n % 10))
+		n /= 10
+	}
+	return sign + string(buf[i:])
+}
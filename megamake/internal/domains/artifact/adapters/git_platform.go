@@ -0,0 +1,13 @@
+package adapters
+
+import platgit "github.com/megamake/megamake/internal/platform/git"
+
+type PlatformGit struct{}
+
+func NewPlatformGit() PlatformGit {
+	return PlatformGit{}
+}
+
+func (PlatformGit) HeadState(root string) (string, bool, bool) {
+	return platgit.HeadState(root)
+}
@@ -0,0 +1,34 @@
+package adapters
+
+import (
+	artifactwriter "github.com/megamake/megamake/internal/platform/artifact"
+
+	"github.com/megamake/megamake/internal/domains/artifact/ports"
+)
+
+type PlatformPruner struct {
+	Writer artifactwriter.Writer
+}
+
+func NewPlatformPruner(w artifactwriter.Writer) PlatformPruner {
+	return PlatformPruner{Writer: w}
+}
+
+func (p PlatformPruner) Prune(req ports.PruneRequest) (ports.PruneResult, error) {
+	res, err := p.Writer.Prune(artifactwriter.PruneRequest{
+		ArtifactDir: req.ArtifactDir,
+		ToolPrefix:  req.ToolPrefix,
+		Retention:   artifactwriter.Retention{Keep: req.Keep, MaxAge: req.MaxAge},
+		DryRun:      req.DryRun,
+	})
+	out := ports.PruneResult{Kept: res.Kept}
+	for _, r := range res.Removed {
+		out.Removed = append(out.Removed, ports.PrunedArtifact{
+			ToolPrefix:  r.ToolPrefix,
+			GeneratedAt: r.GeneratedAt,
+			Files:       r.Files,
+			Bytes:       r.Bytes,
+		})
+	}
+	return out, err
+}
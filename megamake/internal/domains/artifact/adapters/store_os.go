@@ -0,0 +1,26 @@
+package adapters
+
+import (
+	"os"
+
+	artifactwriter "github.com/megamake/megamake/internal/platform/artifact"
+	"github.com/megamake/megamake/internal/platform/errors"
+)
+
+type OSStore struct{}
+
+func NewOSStore() OSStore {
+	return OSStore{}
+}
+
+func (OSStore) ReadFile(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.New(errors.KindIO, "failed to read artifact file", err)
+	}
+	return b, nil
+}
+
+func (OSStore) HashFile(path string) (string, int64, error) {
+	return artifactwriter.HashFile(path)
+}
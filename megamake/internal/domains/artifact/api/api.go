@@ -0,0 +1,64 @@
+package api
+
+import (
+	artifactapp "github.com/megamake/megamake/internal/domains/artifact/app"
+	artifactports "github.com/megamake/megamake/internal/domains/artifact/ports"
+	"github.com/megamake/megamake/internal/platform/clock"
+)
+
+type API interface {
+	Latest(dir string, tool string) (string, error)
+	Show(ref artifactapp.Ref) (artifactapp.ShowResult, error)
+	Extract(req artifactapp.ExtractRequest) (string, error)
+	Prune(req artifactapp.PruneRequest) (artifactapp.PruneResult, error)
+	Diff(req artifactapp.DiffRequest) (artifactapp.DiffResult, error)
+	Verify(req artifactapp.VerifyRequest) (artifactapp.VerifyResult, error)
+}
+
+type Dependencies struct {
+	Clock          clock.Clock
+	Store          artifactports.Store
+	Pruner         artifactports.Pruner
+	ArtifactWriter artifactports.ArtifactWriter
+	Git            artifactports.Git
+}
+
+func New(deps Dependencies) API {
+	return &artifactAPI{
+		svc: &artifactapp.Service{
+			Clock:          deps.Clock,
+			Store:          deps.Store,
+			Pruner:         deps.Pruner,
+			ArtifactWriter: deps.ArtifactWriter,
+			Git:            deps.Git,
+		},
+	}
+}
+
+type artifactAPI struct {
+	svc *artifactapp.Service
+}
+
+func (a *artifactAPI) Latest(dir string, tool string) (string, error) {
+	return a.svc.Latest(dir, tool)
+}
+
+func (a *artifactAPI) Show(ref artifactapp.Ref) (artifactapp.ShowResult, error) {
+	return a.svc.Show(ref)
+}
+
+func (a *artifactAPI) Extract(req artifactapp.ExtractRequest) (string, error) {
+	return a.svc.Extract(req)
+}
+
+func (a *artifactAPI) Prune(req artifactapp.PruneRequest) (artifactapp.PruneResult, error) {
+	return a.svc.Prune(req)
+}
+
+func (a *artifactAPI) Diff(req artifactapp.DiffRequest) (artifactapp.DiffResult, error) {
+	return a.svc.Diff(req)
+}
+
+func (a *artifactAPI) Verify(req artifactapp.VerifyRequest) (artifactapp.VerifyResult, error) {
+	return a.svc.Verify(req)
+}
@@ -0,0 +1,179 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	contractartifact "github.com/megamake/megamake/internal/contracts/v1/artifact"
+	contract "github.com/megamake/megamake/internal/contracts/v1/artifactdiff"
+	diagnose "github.com/megamake/megamake/internal/contracts/v1/diagnose"
+	prompt "github.com/megamake/megamake/internal/contracts/v1/prompt"
+	testplan "github.com/megamake/megamake/internal/contracts/v1/testplan"
+	"github.com/megamake/megamake/internal/domains/artifact/domain"
+	"github.com/megamake/megamake/internal/domains/artifact/ports"
+)
+
+type DiffRequest struct {
+	// Base is the older artifact. Head defaults to the latest artifact of Base's tool in
+	// Head.Dir when neither Head.Path nor Head.Tool is set.
+	Base Ref
+	Head Ref
+
+	ArtifactDir string
+	Args        []string
+}
+
+type DiffResult struct {
+	Report     contract.ArtifactDiffReportV1
+	ReportXML  string
+	ReportJSON string
+	Prompt     string
+
+	ArtifactPath string
+	LatestPath   string
+}
+
+// Diff compares the JSON views of two artifacts of the same tool and writes the delta as a
+// MEGADIFF artifact.
+func (s *Service) Diff(req DiffRequest) (DiffResult, error) {
+	if s.Clock == nil {
+		return DiffResult{}, fmt.Errorf("internal error: clock not configured")
+	}
+	if s.ArtifactWriter == nil {
+		return DiffResult{}, fmt.Errorf("internal error: artifact writer not configured")
+	}
+
+	basePath, baseEnv, err := s.Load(req.Base)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	prefix, err := domain.ToolPrefix(baseEnv.Meta.Tool)
+	if err != nil {
+		return DiffResult{}, fmt.Errorf("%s: %v", basePath, err)
+	}
+	supported := false
+	for _, t := range domain.DiffTools {
+		supported = supported || t == prefix
+	}
+	if !supported {
+		return DiffResult{}, fmt.Errorf("artifact diff does not support %s artifacts (supported: %s)", prefix, strings.Join(domain.DiffTools, ", "))
+	}
+
+	headRef := req.Head
+	if strings.TrimSpace(headRef.Path) == "" && strings.TrimSpace(headRef.Tool) == "" {
+		headRef.Tool = prefix
+	}
+	headPath, headEnv, err := s.Load(headRef)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	headPrefix, err := domain.ToolPrefix(headEnv.Meta.Tool)
+	if err != nil {
+		return DiffResult{}, fmt.Errorf("%s: %v", headPath, err)
+	}
+	if headPrefix != prefix {
+		return DiffResult{}, fmt.Errorf("cannot diff a %s artifact against a %s artifact", prefix, headPrefix)
+	}
+
+	now := s.Clock.NowUTC()
+	rep := contract.ArtifactDiffReportV1{
+		GeneratedAt: contractartifact.FormatRFC3339NanoUTC(now),
+		ToolPrefix:  prefix,
+		Base:        contract.ArtifactRefV1{Path: basePath, GeneratedAt: baseEnv.Meta.GeneratedAt},
+		Head:        contract.ArtifactRefV1{Path: headPath, GeneratedAt: headEnv.Meta.GeneratedAt},
+	}
+	if basePath == headPath {
+		rep.Warnings = append(rep.Warnings, "base and head are the same artifact")
+	} else if baseEnv.Meta.GeneratedAt > headEnv.Meta.GeneratedAt {
+		rep.Warnings = append(rep.Warnings, "base is newer than head; \"new\" entries are the ones that went away since")
+	}
+
+	switch prefix {
+	case "MEGADIAG":
+		var b, h diagnose.DiagnosticsReportV1
+		if err := decodeReport(basePath, baseEnv, &b); err != nil {
+			return DiffResult{}, err
+		}
+		if err := decodeReport(headPath, headEnv, &h); err != nil {
+			return DiffResult{}, err
+		}
+		d := domain.DiffDiagnostics(b, h)
+		rep.Diagnostics = &d
+	case "MEGATEST":
+		var b, h testplan.TestPlanReportV1
+		if err := decodeReport(basePath, baseEnv, &b); err != nil {
+			return DiffResult{}, err
+		}
+		if err := decodeReport(headPath, headEnv, &h); err != nil {
+			return DiffResult{}, err
+		}
+		d := domain.DiffTestPlan(b, h)
+		rep.TestPlan = &d
+	case "MEGAPROMPT":
+		var b, h prompt.PromptReportV1
+		if err := decodeReport(basePath, baseEnv, &b); err != nil {
+			return DiffResult{}, err
+		}
+		if err := decodeReport(headPath, headEnv, &h); err != nil {
+			return DiffResult{}, err
+		}
+		rep.Base.RootPath = b.RootPath
+		rep.Head.RootPath = h.RootPath
+		if b.RootPath != h.RootPath {
+			rep.Warnings = append(rep.Warnings, "the artifacts were generated for different roots: "+b.RootPath+" and "+h.RootPath)
+		}
+		d := domain.DiffPrompt(b, h, baseEnv.Manifest, headEnv.Manifest)
+		rep.Prompt = &d
+	}
+	rep.Summary = domain.Summarize(rep)
+
+	promptText := domain.GenerateDiffPrompt(rep)
+	xmlOut := rep.ToXML(promptText)
+	jsonBytes, _ := json.MarshalIndent(rep, "", "  ")
+	jsonOut := string(jsonBytes)
+
+	env := contractartifact.ArtifactEnvelopeV1{
+		Meta: contractartifact.ArtifactMetaV1{
+			Tool:        "megadiff",
+			Contract:    "v1",
+			GeneratedAt: rep.GeneratedAt,
+			Args:        req.Args,
+			Warnings:    rep.Warnings,
+		},
+		XML:    xmlOut,
+		JSON:   jsonOut,
+		Prompt: promptText,
+	}
+	artifactPath, latestPath, err := s.ArtifactWriter.WriteToolArtifact(ports.WriteArtifactRequest{
+		ArtifactDir:    req.ArtifactDir,
+		ToolPrefix:     "MEGADIFF",
+		Envelope:       env,
+		GeneratedAtUTC: timePtr(now),
+	})
+	if err != nil {
+		return DiffResult{}, err
+	}
+
+	return DiffResult{
+		Report:       rep,
+		ReportXML:    xmlOut,
+		ReportJSON:   jsonOut,
+		Prompt:       promptText,
+		ArtifactPath: artifactPath,
+		LatestPath:   latestPath,
+	}, nil
+}
+
+func decodeReport(path string, env contractartifact.ArtifactEnvelopeV1, v any) error {
+	if strings.TrimSpace(env.JSON) == "" {
+		return fmt.Errorf("%s: artifact has no JSON view", path)
+	}
+	if err := json.Unmarshal([]byte(env.JSON), v); err != nil {
+		return fmt.Errorf("%s: JSON view does not match the %s contract: %v", path, env.Meta.Contract, err)
+	}
+	return nil
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
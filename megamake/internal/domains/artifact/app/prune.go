@@ -0,0 +1,87 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/megamake/megamake/internal/domains/artifact/domain"
+	"github.com/megamake/megamake/internal/domains/artifact/ports"
+	artifactwriter "github.com/megamake/megamake/internal/platform/artifact"
+)
+
+type PruneRequest struct {
+	Dir string
+
+	// Tool limits pruning to one tool (prefix or command name); empty prunes every tool.
+	Tool string
+
+	// Keep keeps the newest Keep artifacts per tool; OlderThan ("7d", "36h") removes older
+	// ones. With both, an artifact must be outside the newest Keep and older to be removed.
+	Keep      int
+	OlderThan string
+
+	DryRun bool
+}
+
+type PruneResult struct {
+	Dir     string           `json:"dir"`
+	DryRun  bool             `json:"dryRun"`
+	Removed []PrunedArtifact `json:"removed"`
+	Kept    int              `json:"kept"`
+	Bytes   int64            `json:"bytes"`
+}
+
+type PrunedArtifact struct {
+	Tool        string   `json:"tool"`
+	GeneratedAt string   `json:"generatedAt"`
+	Files       []string `json:"files"`
+	Bytes       int64    `json:"bytes"`
+}
+
+func (s *Service) Prune(req PruneRequest) (PruneResult, error) {
+	if s.Pruner == nil {
+		return PruneResult{}, fmt.Errorf("internal error: artifact pruner not configured")
+	}
+	if req.Keep < 0 {
+		return PruneResult{}, fmt.Errorf("--keep must be >= 0")
+	}
+	var maxAge time.Duration
+	if strings.TrimSpace(req.OlderThan) != "" {
+		d, err := artifactwriter.ParseAge(req.OlderThan)
+		if err != nil {
+			return PruneResult{}, fmt.Errorf("--older-than: %v", err)
+		}
+		maxAge = d
+	}
+	if req.Keep == 0 && maxAge == 0 {
+		return PruneResult{}, fmt.Errorf("pass --keep N and/or --older-than AGE")
+	}
+	prefix := ""
+	if strings.TrimSpace(req.Tool) != "" {
+		p, err := domain.ToolPrefix(req.Tool)
+		if err != nil {
+			return PruneResult{}, err
+		}
+		prefix = p
+	}
+
+	res, err := s.Pruner.Prune(ports.PruneRequest{
+		ArtifactDir: req.Dir,
+		ToolPrefix:  prefix,
+		Keep:        req.Keep,
+		MaxAge:      maxAge,
+		DryRun:      req.DryRun,
+	})
+	out := PruneResult{Dir: req.Dir, DryRun: req.DryRun, Kept: res.Kept, Removed: []PrunedArtifact{}}
+	for _, r := range res.Removed {
+		out.Removed = append(out.Removed, PrunedArtifact{
+			Tool:        r.ToolPrefix,
+			GeneratedAt: r.GeneratedAt.UTC().Format(time.RFC3339),
+			Files:       r.Files,
+			Bytes:       r.Bytes,
+		})
+		out.Bytes += r.Bytes
+	}
+	return out, err
+}
@@ -0,0 +1,207 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	contractartifact "github.com/megamake/megamake/internal/contracts/v1/artifact"
+	"github.com/megamake/megamake/internal/domains/artifact/domain"
+	"github.com/megamake/megamake/internal/domains/artifact/ports"
+	"github.com/megamake/megamake/internal/platform/clock"
+)
+
+type Service struct {
+	Clock          clock.Clock
+	Store          ports.Store
+	Pruner         ports.Pruner
+	ArtifactWriter ports.ArtifactWriter
+	Git            ports.Git
+}
+
+// Ref names an artifact: an explicit Path, or else the latest artifact of Tool in Dir.
+type Ref struct {
+	Path string
+	Dir  string
+	Tool string
+}
+
+type PartInfo struct {
+	Name  string `json:"name"`
+	Bytes int    `json:"bytes"`
+	Lines int    `json:"lines"`
+}
+
+type ShowResult struct {
+	Path        string     `json:"path"`
+	Tool        string     `json:"tool"`
+	Contract    string     `json:"contract"`
+	GeneratedAt string     `json:"generatedAt"`
+	Format      string     `json:"format,omitempty"`
+	Parts       []PartInfo `json:"parts"`
+	JSONKeys    []string   `json:"jsonKeys,omitempty"`
+
+	Manifest *ManifestInfo `json:"manifest,omitempty"`
+}
+
+// ManifestInfo summarizes an artifact's provenance manifest.
+type ManifestInfo struct {
+	MegamakeVersion string `json:"megamakeVersion"`
+	RootPath        string `json:"rootPath"`
+	GitHead         string `json:"gitHead,omitempty"`
+	GitDirty        bool   `json:"gitDirty"`
+	Files           int    `json:"files"`
+}
+
+type ExtractRequest struct {
+	Ref
+
+	// Part is xml, json or prompt. With Select it defaults to json.
+	Part string
+
+	// Select is a jq-style path (see domain.ParseQuery) applied to the JSON view.
+	Select string
+
+	// Raw prints selected strings without JSON quoting.
+	Raw bool
+}
+
+// Resolve returns the artifact path a ref names.
+func (s *Service) Resolve(ref Ref) (string, error) {
+	if strings.TrimSpace(ref.Path) != "" {
+		return ref.Path, nil
+	}
+	if strings.TrimSpace(ref.Tool) == "" {
+		return "", fmt.Errorf("an artifact path or --tool is required")
+	}
+	return s.Latest(ref.Dir, ref.Tool)
+}
+
+// Latest resolves <dir>/<PREFIX>_latest.txt to the artifact it points at.
+func (s *Service) Latest(dir string, tool string) (string, error) {
+	if s.Store == nil {
+		return "", fmt.Errorf("internal error: artifact store not configured")
+	}
+	prefix, err := domain.ToolPrefix(tool)
+	if err != nil {
+		return "", err
+	}
+	pointer := filepath.Join(dir, domain.LatestPointerName(prefix))
+	b, err := s.Store.ReadFile(pointer)
+	if err != nil {
+		return "", fmt.Errorf("no latest %s artifact in %s: %v", prefix, dir, err)
+	}
+	name, err := domain.ParseLatestPointer(string(b))
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", pointer, err)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Load reads and parses the artifact a ref names.
+func (s *Service) Load(ref Ref) (string, contractartifact.ArtifactEnvelopeV1, error) {
+	if s.Store == nil {
+		return "", contractartifact.ArtifactEnvelopeV1{}, fmt.Errorf("internal error: artifact store not configured")
+	}
+	path, err := s.Resolve(ref)
+	if err != nil {
+		return "", contractartifact.ArtifactEnvelopeV1{}, err
+	}
+	b, err := s.Store.ReadFile(path)
+	if err != nil {
+		return "", contractartifact.ArtifactEnvelopeV1{}, err
+	}
+	env, err := contractartifact.ParseEnvelope(string(b))
+	if err != nil {
+		return "", contractartifact.ArtifactEnvelopeV1{}, fmt.Errorf("%s: %v", path, err)
+	}
+	return path, env, nil
+}
+
+func (s *Service) Show(ref Ref) (ShowResult, error) {
+	path, env, err := s.Load(ref)
+	if err != nil {
+		return ShowResult{}, err
+	}
+	res := ShowResult{
+		Path:        path,
+		Tool:        env.Meta.Tool,
+		Contract:    env.Meta.Contract,
+		GeneratedAt: env.Meta.GeneratedAt,
+		Format:      env.Meta.Format,
+	}
+	for _, name := range []string{"xml", "json", "prompt"} {
+		text, _ := env.Part(name)
+		res.Parts = append(res.Parts, PartInfo{Name: name, Bytes: len(text), Lines: strings.Count(text, "\n")})
+	}
+	if strings.TrimSpace(env.JSON) != "" {
+		if v, err := domain.DecodeJSON(env.JSON); err == nil {
+			if m, ok := v.(map[string]any); ok {
+				for k := range m {
+					res.JSONKeys = append(res.JSONKeys, k)
+				}
+				sort.Strings(res.JSONKeys)
+			}
+		}
+	}
+	if m := env.Manifest; m != nil {
+		info := &ManifestInfo{MegamakeVersion: m.MegamakeVersion, RootPath: m.RootPath, Files: len(m.Files)}
+		if m.Git != nil {
+			info.GitHead = m.Git.Head
+			info.GitDirty = m.Git.Dirty
+		}
+		res.Manifest = info
+	}
+	return res, nil
+}
+
+// Extract returns one view of an artifact, or the results of a selection over its JSON
+// view (one result per line, jq-style).
+func (s *Service) Extract(req ExtractRequest) (string, error) {
+	part := strings.ToLower(strings.TrimSpace(req.Part))
+	sel := strings.TrimSpace(req.Select)
+	if part == "" {
+		if sel == "" {
+			return "", fmt.Errorf("--part is required (xml|json|prompt|manifest)")
+		}
+		part = "json"
+	}
+	if sel != "" && part != "json" && part != "manifest" {
+		return "", fmt.Errorf("--select applies to the json and manifest parts only (got --part %s)", part)
+	}
+	q, err := domain.ParseQuery(sel)
+	if err != nil {
+		return "", err
+	}
+
+	path, env, err := s.Load(req.Ref)
+	if err != nil {
+		return "", err
+	}
+	text, err := env.Part(part)
+	if err != nil {
+		return "", err
+	}
+	if sel == "" {
+		return text, nil
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("%s: artifact has no %s view", path, part)
+	}
+	v, err := domain.DecodeJSON(text)
+	if err != nil {
+		return "", fmt.Errorf("%s: invalid %s view: %v", path, part, err)
+	}
+	results, err := q.Apply(v)
+	if err != nil {
+		return "", fmt.Errorf("select %s: %v", sel, err)
+	}
+	var b strings.Builder
+	for _, r := range results {
+		b.WriteString(domain.FormatResult(r, req.Raw))
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
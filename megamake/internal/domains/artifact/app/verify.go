@@ -0,0 +1,115 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+type VerifyRequest struct {
+	Ref
+
+	// RootPath overrides the manifest's root, e.g. to verify against another checkout.
+	RootPath string
+}
+
+// VerifyResult compares an artifact's manifest with the work tree as it is now.
+type VerifyResult struct {
+	Path            string `json:"path"`
+	Tool            string `json:"tool"`
+	GeneratedAt     string `json:"generatedAt"`
+	MegamakeVersion string `json:"megamakeVersion"`
+	RootPath        string `json:"rootPath"`
+
+	Git *GitDrift `json:"git,omitempty"`
+
+	Files     int         `json:"files"`
+	Unchanged int         `json:"unchanged"`
+	Modified  []FileDrift `json:"modified"`
+	Missing   []string    `json:"missing"`
+
+	// Drift is true when any listed file changed or disappeared. A moved HEAD alone is not
+	// drift: what matters is whether the contents the artifact was built from still match.
+	Drift bool `json:"drift"`
+}
+
+type GitDrift struct {
+	RecordedHead  string `json:"recordedHead"`
+	RecordedDirty bool   `json:"recordedDirty"`
+	CurrentHead   string `json:"currentHead,omitempty"`
+	CurrentDirty  bool   `json:"currentDirty"`
+	HeadMoved     bool   `json:"headMoved"`
+}
+
+type FileDrift struct {
+	Path          string `json:"path"`
+	RecordedHash  string `json:"recordedSha256"`
+	CurrentHash   string `json:"currentSha256"`
+	RecordedBytes int64  `json:"recordedBytes"`
+	CurrentBytes  int64  `json:"currentBytes"`
+}
+
+// Verify recomputes the hash of every file in the artifact's manifest under the root and
+// reports files that changed or disappeared since the artifact was generated.
+func (s *Service) Verify(req VerifyRequest) (VerifyResult, error) {
+	path, env, err := s.Load(req.Ref)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	m := env.Manifest
+	if m == nil {
+		return VerifyResult{}, fmt.Errorf("%s: artifact has no manifest (written by an older megamake, or by a tool that reads no files)", path)
+	}
+
+	root := m.RootPath
+	if strings.TrimSpace(req.RootPath) != "" {
+		root = req.RootPath
+	}
+	if strings.TrimSpace(root) == "" {
+		return VerifyResult{}, fmt.Errorf("%s: manifest has no root path; pass --root", path)
+	}
+
+	res := VerifyResult{
+		Path:            path,
+		Tool:            env.Meta.Tool,
+		GeneratedAt:     env.Meta.GeneratedAt,
+		MegamakeVersion: m.MegamakeVersion,
+		RootPath:        root,
+		Files:           len(m.Files),
+		Modified:        []FileDrift{},
+		Missing:         []string{},
+	}
+
+	if m.Git != nil {
+		g := &GitDrift{RecordedHead: m.Git.Head, RecordedDirty: m.Git.Dirty}
+		if s.Git != nil {
+			if head, dirty, ok := s.Git.HeadState(root); ok {
+				g.CurrentHead = head
+				g.CurrentDirty = dirty
+			}
+		}
+		g.HeadMoved = g.CurrentHead != g.RecordedHead
+		res.Git = g
+	}
+
+	for _, f := range m.Files {
+		sum, n, err := s.Store.HashFile(filepath.Join(root, filepath.FromSlash(f.Path)))
+		if err != nil {
+			res.Missing = append(res.Missing, f.Path)
+			continue
+		}
+		if sum != f.SHA256 {
+			res.Modified = append(res.Modified, FileDrift{
+				Path:          f.Path,
+				RecordedHash:  f.SHA256,
+				CurrentHash:   sum,
+				RecordedBytes: f.Bytes,
+				CurrentBytes:  n,
+			})
+			continue
+		}
+		res.Unchanged++
+	}
+	res.Drift = len(res.Modified) > 0 || len(res.Missing) > 0
+	return res, nil
+}
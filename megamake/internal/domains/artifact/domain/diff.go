@@ -0,0 +1,352 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+
+	contractartifact "github.com/megamake/megamake/internal/contracts/v1/artifact"
+	contract "github.com/megamake/megamake/internal/contracts/v1/artifactdiff"
+	diagnose "github.com/megamake/megamake/internal/contracts/v1/diagnose"
+	project "github.com/megamake/megamake/internal/contracts/v1/project"
+	prompt "github.com/megamake/megamake/internal/contracts/v1/prompt"
+	testplan "github.com/megamake/megamake/internal/contracts/v1/testplan"
+)
+
+// DiffTools are the tool prefixes artifact diff understands.
+var DiffTools = []string{"MEGADIAG", "MEGATEST", "MEGAPROMPT"}
+
+// DiffDiagnostics matches issues as a multiset on everything but position: an issue reported
+// twice in base and three times in head is one new issue.
+func DiffDiagnostics(base, head diagnose.DiagnosticsReportV1) contract.DiagnosticsDeltaV1 {
+	baseCount := map[string]int{}
+	for _, d := range flattenIssues(base) {
+		baseCount[issueKey(d)]++
+	}
+	out := contract.DiagnosticsDeltaV1{New: []diagnose.DiagnosticV1{}, Fixed: []diagnose.DiagnosticV1{}}
+	headCount := map[string]int{}
+	for _, d := range flattenIssues(head) {
+		k := issueKey(d)
+		headCount[k]++
+		if baseCount[k] > 0 {
+			baseCount[k]--
+			out.Unchanged++
+			continue
+		}
+		out.New = append(out.New, d)
+	}
+	for _, d := range flattenIssues(base) {
+		k := issueKey(d)
+		if headCount[k] > 0 {
+			headCount[k]--
+			continue
+		}
+		out.Fixed = append(out.Fixed, d)
+	}
+	return out
+}
+
+func flattenIssues(r diagnose.DiagnosticsReportV1) []diagnose.DiagnosticV1 {
+	var out []diagnose.DiagnosticV1
+	for _, ld := range r.Languages {
+		out = append(out, ld.Issues...)
+	}
+	return out
+}
+
+func issueKey(d diagnose.DiagnosticV1) string {
+	return strings.Join([]string{
+		d.Tool,
+		d.File,
+		d.Code,
+		string(d.Severity),
+		strings.Join(strings.Fields(d.Message), " "),
+	}, "\x00")
+}
+
+// DiffTestPlan compares subjects by ID.
+func DiffTestPlan(base, head testplan.TestPlanReportV1) contract.TestPlanDeltaV1 {
+	baseByID := subjectsByID(base)
+	headByID := subjectsByID(head)
+	out := contract.TestPlanDeltaV1{
+		NewUncovered:    []contract.SubjectDeltaV1{},
+		NowCovered:      []contract.SubjectDeltaV1{},
+		AddedSubjects:   []contract.SubjectDeltaV1{},
+		RemovedSubjects: []contract.SubjectDeltaV1{},
+	}
+
+	for _, id := range sortedSubjectIDs(headByID) {
+		h := headByID[id]
+		b, inBase := baseByID[id]
+		d := subjectDelta(h)
+		d.HeadCoverage = h.Coverage.Status
+		if inBase {
+			d.BaseCoverage = b.Coverage.Status
+		} else {
+			out.AddedSubjects = append(out.AddedSubjects, d)
+		}
+		switch {
+		case uncovered(h) && (!inBase || !uncovered(b)):
+			out.NewUncovered = append(out.NewUncovered, d)
+		case uncovered(h):
+			out.StillUncovered++
+		case inBase && uncovered(b):
+			out.NowCovered = append(out.NowCovered, d)
+		}
+	}
+	for _, id := range sortedSubjectIDs(baseByID) {
+		if _, ok := headByID[id]; ok {
+			continue
+		}
+		b := baseByID[id]
+		d := subjectDelta(b)
+		d.BaseCoverage = b.Coverage.Status
+		out.RemovedSubjects = append(out.RemovedSubjects, d)
+	}
+
+	// Riskiest first, as the test plan itself would prioritize them.
+	sort.SliceStable(out.NewUncovered, func(i, j int) bool {
+		return out.NewUncovered[i].RiskScore > out.NewUncovered[j].RiskScore
+	})
+	return out
+}
+
+func subjectsByID(r testplan.TestPlanReportV1) map[string]testplan.SubjectPlanV1 {
+	out := map[string]testplan.SubjectPlanV1{}
+	for _, lp := range r.Languages {
+		for _, sp := range lp.Subjects {
+			out[sp.Subject.ID] = sp
+		}
+	}
+	return out
+}
+
+func subjectDelta(sp testplan.SubjectPlanV1) contract.SubjectDeltaV1 {
+	s := sp.Subject
+	return contract.SubjectDeltaV1{
+		ID:        s.ID,
+		Kind:      s.Kind,
+		Language:  s.Language,
+		Name:      s.Name,
+		Path:      s.Path,
+		RiskScore: s.RiskScore,
+	}
+}
+
+func uncovered(sp testplan.SubjectPlanV1) bool {
+	return sp.Coverage.Status == "MISSING" || sp.Coverage.Flag == testplan.CoverageRed
+}
+
+// DiffPrompt compares the included files by path. When both artifacts carry a manifest, a
+// file is changed if its SHA-256 differs (a file one manifest has no hash for falls back to
+// its size); without both manifests only sizes can be compared, and the delta says so.
+func DiffPrompt(base, head prompt.PromptReportV1, baseManifest, headManifest *contractartifact.ManifestV1) contract.PromptDeltaV1 {
+	baseByPath := map[string]project.FileRefV1{}
+	for _, f := range base.Files {
+		baseByPath[f.RelPath] = f
+	}
+	headByPath := map[string]project.FileRefV1{}
+	for _, f := range head.Files {
+		headByPath[f.RelPath] = f
+	}
+
+	out := contract.PromptDeltaV1{
+		Comparison: contract.ComparisonSize,
+		Added:      []project.FileRefV1{},
+		Removed:    []project.FileRefV1{},
+		Changed:    []contract.FileChangeV1{},
+	}
+	var baseHashes, headHashes map[string]contractartifact.FileHashV1
+	if baseManifest != nil && headManifest != nil {
+		out.Comparison = contract.ComparisonSHA256
+		baseHashes, headHashes = manifestHashes(baseManifest), manifestHashes(headManifest)
+	}
+	for _, p := range sortedFilePaths(headByPath) {
+		h := headByPath[p]
+		b, ok := baseByPath[p]
+		if !ok {
+			out.Added = append(out.Added, h)
+			continue
+		}
+		change := contract.FileChangeV1{Path: p, BaseBytes: b.SizeBytes, HeadBytes: h.SizeBytes}
+		changed := b.SizeBytes != h.SizeBytes
+		bh, bok := baseHashes[p]
+		hh, hok := headHashes[p]
+		if bok && hok && bh.SHA256 != "" && hh.SHA256 != "" {
+			change.BaseSHA256, change.HeadSHA256 = bh.SHA256, hh.SHA256
+			change.BaseBytes, change.HeadBytes = bh.Bytes, hh.Bytes
+			changed = bh.SHA256 != hh.SHA256
+		}
+		if changed {
+			out.Changed = append(out.Changed, change)
+		} else {
+			out.Unchanged++
+		}
+	}
+	for _, p := range sortedFilePaths(baseByPath) {
+		if _, ok := headByPath[p]; !ok {
+			out.Removed = append(out.Removed, baseByPath[p])
+		}
+	}
+	return out
+}
+
+func manifestHashes(m *contractartifact.ManifestV1) map[string]contractartifact.FileHashV1 {
+	out := make(map[string]contractartifact.FileHashV1, len(m.Files))
+	for _, f := range m.Files {
+		out[f.Path] = f
+	}
+	return out
+}
+
+func sortedSubjectIDs(m map[string]testplan.SubjectPlanV1) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedFilePaths(m map[string]project.FileRefV1) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Summarize counts the delta's entries (see contract.DiffSummaryV1).
+func Summarize(r contract.ArtifactDiffReportV1) contract.DiffSummaryV1 {
+	switch {
+	case r.Diagnostics != nil:
+		d := r.Diagnostics
+		return contract.DiffSummaryV1{Added: len(d.New), Removed: len(d.Fixed), Unchanged: d.Unchanged}
+	case r.TestPlan != nil:
+		t := r.TestPlan
+		return contract.DiffSummaryV1{Added: len(t.NewUncovered), Removed: len(t.NowCovered), Unchanged: t.StillUncovered}
+	case r.Prompt != nil:
+		p := r.Prompt
+		return contract.DiffSummaryV1{Added: len(p.Added), Removed: len(p.Removed), Changed: len(p.Changed), Unchanged: p.Unchanged}
+	}
+	return contract.DiffSummaryV1{}
+}
+
+// GenerateDiffPrompt turns the delta into an agent prompt scoped to what changed.
+func GenerateDiffPrompt(r contract.ArtifactDiffReportV1) string {
+	var lines []string
+	since := "the previous run (" + r.Base.GeneratedAt + ")"
+
+	switch {
+	case r.Diagnostics != nil:
+		d := r.Diagnostics
+		if len(d.New) == 0 {
+			lines = append(lines, "No new diagnostics since "+since+".")
+		} else {
+			lines = append(lines, "You are an expert software engineer. The following diagnostics are new since "+since+"; they were most likely introduced by the changes made in between. Fix them without reintroducing the ones already resolved.")
+			lines = append(lines, "")
+			lines = append(lines, "New diagnostics ("+itoa(len(d.New))+"):")
+			for _, x := range d.New {
+				lines = append(lines, "- "+diagnosticLine(x))
+			}
+		}
+		if len(d.Fixed) > 0 {
+			lines = append(lines, "")
+			lines = append(lines, "Resolved since then ("+itoa(len(d.Fixed))+"):")
+			for _, x := range d.Fixed {
+				lines = append(lines, "- "+diagnosticLine(x))
+			}
+		}
+
+	case r.TestPlan != nil:
+		t := r.TestPlan
+		if len(t.NewUncovered) == 0 {
+			lines = append(lines, "No newly uncovered test subjects since "+since+".")
+		} else {
+			lines = append(lines, "You are an expert software engineer. The following code has no tests and is new or lost its coverage since "+since+". Write tests for it, riskiest first, following the project's existing test conventions.")
+			lines = append(lines, "")
+			for _, s := range t.NewUncovered {
+				lines = append(lines, "- "+s.Path+": "+string(s.Kind)+" "+s.Name+" (risk "+itoa(s.RiskScore)+")")
+			}
+		}
+		if len(t.NowCovered) > 0 {
+			lines = append(lines, "")
+			lines = append(lines, "Now covered ("+itoa(len(t.NowCovered))+"): "+joinSubjectNames(t.NowCovered))
+		}
+
+	case r.Prompt != nil:
+		p := r.Prompt
+		lines = append(lines, "The project context changed since "+since+":")
+		lines = append(lines, "- Added files ("+itoa(len(p.Added))+"): "+joinFilePaths(p.Added))
+		lines = append(lines, "- Removed files ("+itoa(len(p.Removed))+"): "+joinFilePaths(p.Removed))
+		var changed []string
+		for _, c := range p.Changed {
+			changed = append(changed, c.Path)
+		}
+		if p.Comparison == contract.ComparisonSize {
+			lines = append(lines, "- Changed files ("+itoa(len(p.Changed))+", compared by size only, so edits that kept a file's size are not listed): "+joinOrNone(changed))
+		} else {
+			lines = append(lines, "- Changed files ("+itoa(len(p.Changed))+"): "+joinOrNone(changed))
+		}
+		lines = append(lines, "")
+		lines = append(lines, "Take these changes into account; earlier context about removed or changed files may be stale.")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func diagnosticLine(d diagnose.DiagnosticV1) string {
+	loc := d.File
+	if d.Line != nil {
+		loc += ":" + itoa(*d.Line)
+		if d.Column != nil {
+			loc += ":" + itoa(*d.Column)
+		}
+	}
+	head := string(d.Severity)
+	if strings.TrimSpace(d.Code) != "" {
+		head += " " + d.Code
+	}
+	return loc + ": [" + d.Tool + "] " + head + ": " + strings.Join(strings.Fields(d.Message), " ")
+}
+
+func joinSubjectNames(subjects []contract.SubjectDeltaV1) string {
+	var names []string
+	for _, s := range subjects {
+		names = append(names, s.Path+"#"+s.Name)
+	}
+	return joinOrNone(names)
+}
+
+func joinFilePaths(files []project.FileRefV1) string {
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.RelPath)
+	}
+	return joinOrNone(paths)
+}
+
+func joinOrNone(xs []string) string {
+	if len(xs) == 0 {
+		return "(none)"
+	}
+	return strings.Join(xs, ", ")
+}
+
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	var buf [32]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = byte('0' + (n % 10))
+		n /= 10
+	}
+	return sign + string(buf[i:])
+}
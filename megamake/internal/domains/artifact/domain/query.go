@@ -0,0 +1,264 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Query is a jq-style path expression over decoded JSON. The supported subset covers
+// field selection: ".", ".a.b", ".\"key with spaces\"", ".[\"key\"]", ".items[0]",
+// ".items[-1]", ".items[].name" (iteration), and "|" between such paths.
+//
+// As in jq, a field of null is null, and iterating an object yields its values (here
+// in key order, for stable output).
+type Query struct {
+	expr  string
+	steps []queryStep
+}
+
+type stepKind int
+
+const (
+	stepField stepKind = iota
+	stepIndex
+	stepIterate
+)
+
+type queryStep struct {
+	kind  stepKind
+	field string
+	index int
+}
+
+// ParseQuery parses a path expression; "" is the identity.
+func ParseQuery(expr string) (Query, error) {
+	q := Query{expr: strings.TrimSpace(expr)}
+	if q.expr == "" {
+		return q, nil
+	}
+	for _, stage := range splitPipes(q.expr) {
+		steps, err := parseStage(strings.TrimSpace(stage))
+		if err != nil {
+			return Query{}, fmt.Errorf("invalid query %q: %v", q.expr, err)
+		}
+		q.steps = append(q.steps, steps...)
+	}
+	return q, nil
+}
+
+func parseStage(s string) ([]queryStep, error) {
+	if !strings.HasPrefix(s, ".") {
+		return nil, fmt.Errorf("a path must start with '.'")
+	}
+	var steps []queryStep
+	i := 0
+	for i < len(s) {
+		switch s[i] {
+		case '.':
+			i++
+			if i >= len(s) || s[i] == '[' {
+				continue
+			}
+			if s[i] == '"' {
+				name, n, err := readQuoted(s[i:])
+				if err != nil {
+					return nil, err
+				}
+				steps = append(steps, queryStep{kind: stepField, field: name})
+				i += n
+				continue
+			}
+			start := i
+			for i < len(s) && isIdentByte(s[i]) {
+				i++
+			}
+			if start == i {
+				return nil, fmt.Errorf("expected a field name at offset %d", start)
+			}
+			steps = append(steps, queryStep{kind: stepField, field: s[start:i]})
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated '['")
+			}
+			inner := strings.TrimSpace(s[i+1 : i+end])
+			switch {
+			case inner == "":
+				steps = append(steps, queryStep{kind: stepIterate})
+			case strings.HasPrefix(inner, "\""):
+				name, n, err := readQuoted(inner)
+				if err != nil {
+					return nil, err
+				}
+				if n != len(inner) {
+					return nil, fmt.Errorf("unexpected text after %s", inner[:n])
+				}
+				steps = append(steps, queryStep{kind: stepField, field: name})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %q", inner)
+				}
+				steps = append(steps, queryStep{kind: stepIndex, index: n})
+			}
+			i += end + 1
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", s[i], i)
+		}
+	}
+	return steps, nil
+}
+
+// splitPipes splits expr at "|" outside quoted names.
+func splitPipes(expr string) []string {
+	var out []string
+	start, quoted := 0, false
+	for i := 0; i < len(expr); i++ {
+		switch {
+		case quoted && expr[i] == '\\':
+			i++
+		case expr[i] == '"':
+			quoted = !quoted
+		case !quoted && expr[i] == '|':
+			out = append(out, expr[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, expr[start:])
+}
+
+// readQuoted reads a JSON string literal at the start of s and returns it with its length.
+func readQuoted(s string) (string, int, error) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			var out string
+			if err := json.Unmarshal([]byte(s[:i+1]), &out); err != nil {
+				return "", 0, fmt.Errorf("invalid quoted name %s", s[:i+1])
+			}
+			return out, i + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted name")
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c == '-' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// Apply evaluates the query against a decoded JSON value (as produced by DecodeJSON) and
+// returns every result, in order.
+func (q Query) Apply(v any) ([]any, error) {
+	cur := []any{v}
+	for _, st := range q.steps {
+		var next []any
+		for _, x := range cur {
+			out, err := applyStep(st, x)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, out...)
+		}
+		cur = next
+	}
+	return cur, nil
+}
+
+func applyStep(st queryStep, v any) ([]any, error) {
+	switch st.kind {
+	case stepField:
+		switch x := v.(type) {
+		case nil:
+			return []any{nil}, nil
+		case map[string]any:
+			return []any{x[st.field]}, nil
+		default:
+			return nil, fmt.Errorf("cannot index %s with %q", jsonKind(v), st.field)
+		}
+	case stepIndex:
+		switch x := v.(type) {
+		case nil:
+			return []any{nil}, nil
+		case []any:
+			i := st.index
+			if i < 0 {
+				i += len(x)
+			}
+			if i < 0 || i >= len(x) {
+				return []any{nil}, nil
+			}
+			return []any{x[i]}, nil
+		default:
+			return nil, fmt.Errorf("cannot index %s with number", jsonKind(v))
+		}
+	default:
+		switch x := v.(type) {
+		case []any:
+			return x, nil
+		case map[string]any:
+			keys := make([]string, 0, len(x))
+			for k := range x {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			out := make([]any, 0, len(keys))
+			for _, k := range keys {
+				out = append(out, x[k])
+			}
+			return out, nil
+		default:
+			return nil, fmt.Errorf("cannot iterate over %s", jsonKind(v))
+		}
+	}
+}
+
+func jsonKind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// DecodeJSON decodes text keeping numbers exact (json.Number), so selected values print
+// as they were written.
+func DecodeJSON(text string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// FormatResult renders one query result like jq: indented JSON, or with raw set, strings
+// without quotes.
+func FormatResult(v any, raw bool) string {
+	if s, ok := v.(string); ok && raw {
+		return s
+	}
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// toolAliases maps command names to the artifact prefixes their tools write.
+var toolAliases = map[string]string{
+	"prompt":       "MEGAPROMPT",
+	"doc":          "MEGADOC",
+	"diagnose":     "MEGADIAG",
+	"diag":         "MEGADIAG",
+	"megadiagnose": "MEGADIAG", // the tool name in MEGADIAG artifact headers
+	"test":         "MEGATEST",
+	"secure":       "MEGASECURE",
+	"patch":        "MEGAPATCH",
+	"make":         "MEGAMAKE",
+	"workflow":     "MEGAMAKE",
+}
+
+var toolPrefixRe = regexp.MustCompile(`^MEGA[A-Z]+$`)
+
+// ToolPrefix resolves a --tool value to an artifact filename prefix. It accepts the prefix
+// itself in any case (MEGADIAG, megadiag) or a command name (diagnose, prompt, ...).
+func ToolPrefix(name string) (string, error) {
+	n := strings.TrimSpace(name)
+	if p, ok := toolAliases[strings.ToLower(n)]; ok {
+		return p, nil
+	}
+	if up := strings.ToUpper(n); toolPrefixRe.MatchString(up) {
+		return up, nil
+	}
+	return "", fmt.Errorf("unknown tool %q (expected a MEGA* prefix or one of: %s)", name, strings.Join(toolAliasNames(), ", "))
+}
+
+func toolAliasNames() []string {
+	out := make([]string, 0, len(toolAliases))
+	for k := range toolAliases {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// LatestPointerName is the pointer file a tool rewrites after each artifact.
+func LatestPointerName(prefix string) string {
+	return prefix + "_latest.txt"
+}
+
+// ParseLatestPointer returns the artifact filename a pointer file names (its first line).
+// Pointers hold a bare filename relative to their own directory.
+func ParseLatestPointer(content string) (string, error) {
+	line := strings.TrimSpace(strings.SplitN(content, "\n", 2)[0])
+	if line == "" {
+		return "", fmt.Errorf("latest pointer is empty")
+	}
+	if strings.ContainsAny(line, `/\`) {
+		return "", fmt.Errorf("latest pointer names a path, not a file: %q", line)
+	}
+	return line, nil
+}
@@ -0,0 +1,8 @@
+package ports
+
+// Git reports the current work tree state, to compare with a manifest.
+type Git interface {
+	// HeadState returns HEAD's commit and whether root has uncommitted changes; ok is false
+	// outside a git work tree.
+	HeadState(root string) (head string, dirty bool, ok bool)
+}
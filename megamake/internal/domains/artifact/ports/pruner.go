@@ -0,0 +1,28 @@
+package ports
+
+import "time"
+
+type PruneRequest struct {
+	ArtifactDir string
+	ToolPrefix  string // empty: every tool
+	Keep        int
+	MaxAge      time.Duration
+	DryRun      bool
+}
+
+type PrunedArtifact struct {
+	ToolPrefix  string
+	GeneratedAt time.Time
+	Files       []string
+	Bytes       int64
+}
+
+type PruneResult struct {
+	Removed []PrunedArtifact
+	Kept    int
+}
+
+// Pruner removes artifacts beyond a retention policy, never the one a latest pointer names.
+type Pruner interface {
+	Prune(req PruneRequest) (PruneResult, error)
+}
@@ -0,0 +1,10 @@
+package ports
+
+// Store reads artifacts and their latest pointers from disk, and hashes the files a
+// manifest lists.
+type Store interface {
+	ReadFile(path string) ([]byte, error)
+
+	// HashFile returns the hex SHA-256 and size of a file.
+	HashFile(path string) (string, int64, error)
+}
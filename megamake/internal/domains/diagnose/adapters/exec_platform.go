@@ -18,7 +18,14 @@ func (PlatformExec) Which(name string) (string, bool) {
 }
 
 func (PlatformExec) Run(launchPath string, args []string, cwd string, timeout time.Duration) ports.ExecResult {
-	r := plat.Run(launchPath, args, cwd, timeout)
+	return toExecResult(plat.Run(launchPath, args, cwd, timeout))
+}
+
+func (PlatformExec) RunEnv(launchPath string, args []string, cwd string, env []string, timeout time.Duration) ports.ExecResult {
+	return toExecResult(plat.RunEnv(launchPath, args, cwd, env, timeout))
+}
+
+func toExecResult(r plat.Result) ports.ExecResult {
 	return ports.ExecResult{
 		ExitCode: r.ExitCode,
 		Stdout:   r.Stdout,
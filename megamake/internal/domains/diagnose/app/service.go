@@ -116,9 +116,17 @@ func (s *Service) Diagnose(req DiagnoseRequest) (DiagnoseResult, error) {
 		IgnoreNames:  req.IgnoreNames,
 		IgnoreGlobs:  req.IgnoreGlobs,
 		Exec:         s.Exec,
+		Offline:      !req.NetEnabled,
 	}
 
-	rep, warnings := runner.Run(profile, pyFiles)
+	var rep contract.DiagnosticsReportV1
+	var warnings []string
+	if len(profile.Members) > 0 {
+		// Monorepo: each member's toolchain runs from the member directory.
+		rep, warnings = runner.RunWorkspace(profile, pyFiles)
+	} else {
+		rep, warnings = runner.Run(profile, pyFiles)
+	}
 	rep.GeneratedAt = contractartifact.FormatRFC3339NanoUTC(now)
 	// Merge warnings from runner into report warnings.
 	rep.Warnings = append(rep.Warnings, warnings...)
@@ -145,6 +153,9 @@ func (s *Service) Diagnose(req DiagnoseRequest) (DiagnoseResult, error) {
 		XML:    xmlOut,
 		JSON:   jsonOut,
 		Prompt: fixPrompt,
+
+		// The toolchains see the whole tree, so every scanned file is part of the input.
+		Manifest: contractartifact.NewManifest(req.RootPath, project.RelPaths(files)),
 	}
 
 	artifactPath, latestPath, err := s.ArtifactWriter.WriteToolArtifact(ports.WriteArtifactRequest{
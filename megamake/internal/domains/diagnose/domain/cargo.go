@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	contract "github.com/megamake/megamake/internal/contracts/v1/diagnose"
+)
+
+type rustcSpan struct {
+	FileName                string  `json:"file_name"`
+	LineStart               int     `json:"line_start"`
+	LineEnd                 int     `json:"line_end"`
+	ColumnStart             int     `json:"column_start"`
+	ColumnEnd               int     `json:"column_end"`
+	IsPrimary               bool    `json:"is_primary"`
+	Label                   *string `json:"label"`
+	SuggestedReplacement    *string `json:"suggested_replacement"`
+	SuggestionApplicability *string `json:"suggestion_applicability"`
+	Expansion               *struct {
+		Span rustcSpan `json:"span"`
+	} `json:"expansion"`
+}
+
+type rustcDiagnostic struct {
+	Message string `json:"message"`
+	Code    *struct {
+		Code string `json:"code"`
+	} `json:"code"`
+	Level    string            `json:"level"`
+	Spans    []rustcSpan       `json:"spans"`
+	Children []rustcDiagnostic `json:"children"`
+}
+
+// rustcSummaryRe matches the closing summaries rustc emits as diagnostics of their own.
+var rustcSummaryRe = regexp.MustCompile(`^(aborting due to|\d+ warnings? emitted|could not compile)`)
+
+// ParseCargoJSON reads `cargo ... --message-format=json` output: one JSON object per line,
+// of which "compiler-message" records carry a rustc diagnostic. The primary span gives the
+// position, the lint or error code (E0308, unused_variables, clippy::...) the Code; secondary
+// spans and child notes become Related, and suggested_replacements become Suggestions.
+func ParseCargoJSON(stdout string) []contract.DiagnosticV1 {
+	tool := "cargo check"
+	var out []contract.DiagnosticV1
+	for _, ln := range strings.Split(stdout, "\n") {
+		ln = strings.TrimSpace(ln)
+		if !strings.HasPrefix(ln, "{") {
+			continue
+		}
+		var rec struct {
+			Reason  string          `json:"reason"`
+			Message rustcDiagnostic `json:"message"`
+		}
+		if json.Unmarshal([]byte(ln), &rec) != nil || rec.Reason != "compiler-message" {
+			continue
+		}
+		m := rec.Message
+		if len(m.Spans) == 0 && (m.Level == "failure-note" || rustcSummaryRe.MatchString(m.Message)) {
+			continue
+		}
+
+		d := contract.DiagnosticV1{
+			Tool:     tool,
+			Language: "rust",
+			Severity: rustcSeverity(m.Level),
+			Message:  m.Message,
+		}
+		if m.Code != nil {
+			d.Code = m.Code.Code
+		}
+
+		for _, sp := range m.Spans {
+			sp = userSpan(sp)
+			if sp.IsPrimary && d.File == "" {
+				d.File = sp.FileName
+				d.Line, d.Column = positivePtr(sp.LineStart), positivePtr(sp.ColumnStart)
+				d.EndLine, d.EndColumn = positivePtr(sp.LineEnd), positivePtr(sp.ColumnEnd)
+			}
+			if sp.Label != nil && strings.TrimSpace(*sp.Label) != "" {
+				d.Related = append(d.Related, relatedAt(sp, *sp.Label))
+			}
+			if sp.SuggestedReplacement != nil {
+				d.Suggestions = append(d.Suggestions, suggestionAt(sp, ""))
+			}
+		}
+
+		for _, c := range m.Children {
+			suggested := false
+			for _, sp := range c.Spans {
+				if sp.SuggestedReplacement != nil {
+					d.Suggestions = append(d.Suggestions, suggestionAt(userSpan(sp), c.Message))
+					suggested = true
+				}
+			}
+			if suggested {
+				continue
+			}
+			r := contract.RelatedV1{Message: c.Level + ": " + c.Message}
+			if len(c.Spans) > 0 {
+				r = relatedAt(userSpan(c.Spans[0]), r.Message)
+			}
+			d.Related = append(d.Related, r)
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// userSpan follows macro expansions back to the user's code, so a diagnostic inside a macro
+// points at the invocation rather than at "<::std::macros>".
+func userSpan(sp rustcSpan) rustcSpan {
+	for strings.HasPrefix(sp.FileName, "<") && sp.Expansion != nil {
+		primary := sp.IsPrimary
+		sp = sp.Expansion.Span
+		sp.IsPrimary = primary
+	}
+	return sp
+}
+
+func relatedAt(sp rustcSpan, msg string) contract.RelatedV1 {
+	return contract.RelatedV1{
+		File:      sp.FileName,
+		Line:      positivePtr(sp.LineStart),
+		Column:    positivePtr(sp.ColumnStart),
+		EndLine:   positivePtr(sp.LineEnd),
+		EndColumn: positivePtr(sp.ColumnEnd),
+		Message:   msg,
+	}
+}
+
+func suggestionAt(sp rustcSpan, msg string) contract.SuggestionV1 {
+	s := contract.SuggestionV1{
+		Message:   msg,
+		File:      sp.FileName,
+		Line:      positivePtr(sp.LineStart),
+		Column:    positivePtr(sp.ColumnStart),
+		EndLine:   positivePtr(sp.LineEnd),
+		EndColumn: positivePtr(sp.ColumnEnd),
+	}
+	if sp.SuggestedReplacement != nil {
+		s.Replacement = *sp.SuggestedReplacement
+	}
+	if sp.SuggestionApplicability != nil {
+		s.Applicability = *sp.SuggestionApplicability
+	}
+	return s
+}
+
+func rustcSeverity(level string) contract.SeverityV1 {
+	switch {
+	case strings.HasPrefix(level, "error"):
+		return contract.SeverityError
+	case level == "warning":
+		return contract.SeverityWarning
+	}
+	return contract.SeverityInfo
+}
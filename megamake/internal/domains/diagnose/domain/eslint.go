@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	contract "github.com/megamake/megamake/internal/contracts/v1/diagnose"
+)
+
+// eslintConfigFiles are the flat (eslint.config.*) and legacy (.eslintrc*) config names.
+var eslintConfigFiles = []string{
+	"eslint.config.js", "eslint.config.mjs", "eslint.config.cjs",
+	"eslint.config.ts", "eslint.config.mts", "eslint.config.cts",
+	".eslintrc", ".eslintrc.js", ".eslintrc.cjs", ".eslintrc.yaml", ".eslintrc.yml", ".eslintrc.json",
+}
+
+// hasESLintConfig reports whether the project configures ESLint, in a config file or in
+// package.json's "eslintConfig". Without one, modern ESLint refuses to run.
+func hasESLintConfig(root string) bool {
+	for _, name := range eslintConfigFiles {
+		if fileExists(filepath.Join(root, name)) {
+			return true
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(root, "package.json"))
+	if err != nil {
+		return false
+	}
+	var pkg map[string]json.RawMessage
+	if json.Unmarshal(b, &pkg) != nil {
+		return false
+	}
+	_, ok := pkg["eslintConfig"]
+	return ok
+}
+
+type eslintFix struct {
+	Range [2]int `json:"range"`
+	Text  string `json:"text"`
+}
+
+// ParseESLintJSON reads `eslint -f json`: an array of files, each with its messages. The rule
+// ID (no-unused-vars, @typescript-eslint/no-explicit-any) becomes the Code; a message with a
+// fix is marked Fixable (eslint --fix applies it), and both the fix and the rule's editor
+// suggestions become Suggestions. Parse errors have no rule and are reported as errors.
+func ParseESLintJSON(stdout string, rootPath string, language string) []contract.DiagnosticV1 {
+	var files []struct {
+		FilePath string `json:"filePath"`
+		Source   string `json:"source"`
+		Messages []struct {
+			RuleID      *string    `json:"ruleId"`
+			Severity    int        `json:"severity"`
+			Message     string     `json:"message"`
+			Line        int        `json:"line"`
+			Column      int        `json:"column"`
+			EndLine     int        `json:"endLine"`
+			EndColumn   int        `json:"endColumn"`
+			Fix         *eslintFix `json:"fix"`
+			Suggestions []struct {
+				Desc string     `json:"desc"`
+				Fix  *eslintFix `json:"fix"`
+			} `json:"suggestions"`
+		} `json:"messages"`
+	}
+	s := strings.TrimSpace(stdout)
+	if i := strings.Index(s, "["); i > 0 {
+		s = s[i:]
+	}
+	if json.Unmarshal([]byte(s), &files) != nil {
+		return nil
+	}
+
+	var out []contract.DiagnosticV1
+	for _, f := range files {
+		file := relToRoot(f.FilePath, rootPath)
+		// Fix ranges are offsets into the source; load it only when there is a fix to place.
+		var src *string
+		source := func() string {
+			if src == nil {
+				text := f.Source
+				if text == "" {
+					b, _ := os.ReadFile(f.FilePath)
+					text = string(b)
+				}
+				src = &text
+			}
+			return *src
+		}
+		suggestion := func(fix *eslintFix, msg string, applicability string) contract.SuggestionV1 {
+			line, col := offsetPosition(source(), fix.Range[0])
+			endLine, endCol := offsetPosition(source(), fix.Range[1])
+			return contract.SuggestionV1{
+				Message:       msg,
+				File:          file,
+				Line:          positivePtr(line),
+				Column:        positivePtr(col),
+				EndLine:       positivePtr(endLine),
+				EndColumn:     positivePtr(endCol),
+				Replacement:   fix.Text,
+				Applicability: applicability,
+			}
+		}
+
+		for _, m := range f.Messages {
+			d := contract.DiagnosticV1{
+				Tool:      "eslint",
+				Language:  language,
+				File:      file,
+				Line:      positivePtr(m.Line),
+				Column:    positivePtr(m.Column),
+				EndLine:   positivePtr(m.EndLine),
+				EndColumn: positivePtr(m.EndColumn),
+				Severity:  contract.SeverityWarning,
+				Message:   m.Message,
+			}
+			if m.Severity >= 2 || m.RuleID == nil {
+				d.Severity = contract.SeverityError
+			}
+			if m.RuleID != nil {
+				d.Code = *m.RuleID
+			}
+			if m.Fix != nil {
+				d.Fixable = true
+				d.Suggestions = append(d.Suggestions, suggestion(m.Fix, "eslint --fix", "fix"))
+			}
+			for _, sg := range m.Suggestions {
+				if sg.Fix != nil {
+					d.Suggestions = append(d.Suggestions, suggestion(sg.Fix, sg.Desc, "suggestion"))
+				}
+			}
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// offsetPosition turns an ESLint source offset (UTF-16 code units, as JavaScript counts)
+// into a 1-based line and column.
+func offsetPosition(text string, offset int) (int, int) {
+	line, col, n := 1, 1, 0
+	for _, r := range text {
+		if n >= offset {
+			break
+		}
+		if r == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+		if r >= 0x10000 && r != utf8.RuneError {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return line, col
+}
+
+// isJSTestFile matches the test naming the include-tests ESLint globs cover.
+func isJSTestFile(p string) bool {
+	p = filepathToSlash(p)
+	base := path.Base(p)
+	if strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") {
+		return true
+	}
+	return strings.HasPrefix(p, "__tests__/") || strings.Contains(p, "/__tests__/")
+}
@@ -44,7 +44,11 @@ func GenerateFixPrompt(report contract.DiagnosticsReportV1, rootPath string) str
 				w++
 			}
 		}
-		lines = append(lines, "- "+ld.Name+": "+itoa(e)+" errors, "+itoa(w)+" warnings")
+		name := ld.Name
+		if ld.Package != "" {
+			name += " [" + ld.Package + "]"
+		}
+		lines = append(lines, "- "+name+": "+itoa(e)+" errors, "+itoa(w)+" warnings")
 		limit := 5
 		if len(ld.Issues) < limit {
 			limit = len(ld.Issues)
@@ -56,7 +60,21 @@ func GenerateFixPrompt(report contract.DiagnosticsReportV1, rootPath string) str
 			if code != "" {
 				code = " " + code
 			}
-			lines = append(lines, "  • "+loc+code+": "+d.Message)
+			fixable := ""
+			if d.Fixable {
+				fixable = " (auto-fixable)"
+			}
+			lines = append(lines, "  • "+loc+code+": "+d.Message+fixable)
+			for _, rel := range d.Related {
+				at := ""
+				if rel.File != "" {
+					at = " (" + locationString(contract.DiagnosticV1{File: rel.File, Line: rel.Line, Column: rel.Column}, rootPath) + ")"
+				}
+				lines = append(lines, "      - "+rel.Message+at)
+			}
+			for _, s := range d.Suggestions {
+				lines = append(lines, "      - "+suggestionLine(s, rootPath))
+			}
 		}
 		if len(ld.Issues) > 5 {
 			lines = append(lines, "  • ... "+itoa(len(ld.Issues)-5)+" more")
@@ -67,6 +85,7 @@ func GenerateFixPrompt(report contract.DiagnosticsReportV1, rootPath string) str
 	lines = append(lines, "Instructions:")
 	lines = append(lines, "- Produce minimal, correct fixes for each issue.")
 	lines = append(lines, "- Maintain existing architecture and conventions.")
+	lines = append(lines, "- Where a tool suggested a fix, prefer it unless it is marked MaybeIncorrect or conflicts with the intent of the code.")
 	lines = append(lines, "- Include tests or adjustments to tests as needed.")
 	lines = append(lines, "- If a tool was unavailable, suggest installation steps.")
 	lines = append(lines, "")
@@ -74,6 +93,31 @@ func GenerateFixPrompt(report contract.DiagnosticsReportV1, rootPath string) str
 	return strings.Join(lines, "\n")
 }
 
+// suggestionLine renders a tool-proposed fix, e.g.
+// "suggested fix (MachineApplicable): replace src/main.rs:3:9-3:10 with `_x`".
+func suggestionLine(s contract.SuggestionV1, rootPath string) string {
+	head := "suggested fix"
+	if s.Applicability != "" {
+		head += " (" + s.Applicability + ")"
+	}
+	if s.Message != "" {
+		head += ", " + s.Message
+	}
+	where := locationString(contract.DiagnosticV1{File: s.File, Line: s.Line, Column: s.Column}, rootPath)
+	empty := s.Line != nil && s.Column != nil && s.EndLine != nil && s.EndColumn != nil &&
+		*s.Line == *s.EndLine && *s.Column == *s.EndColumn
+	if empty {
+		return head + ": insert `" + s.Replacement + "` at " + where
+	}
+	if s.EndLine != nil && s.EndColumn != nil {
+		where += "-" + itoa(*s.EndLine) + ":" + itoa(*s.EndColumn)
+	}
+	if s.Replacement == "" {
+		return head + ": delete " + where
+	}
+	return head + ": replace " + where + " with `" + s.Replacement + "`"
+}
+
 func locationString(d contract.DiagnosticV1, rootPath string) string {
 	path := d.File
 	if strings.TrimSpace(rootPath) != "" {
@@ -115,7 +159,7 @@ func itoa(n int) string {
 
 func SortedIssuesByFile(issues []contract.DiagnosticV1) []contract.DiagnosticV1 {
 	out := append([]contract.DiagnosticV1(nil), issues...)
-	sort.Slice(out, func(i, j int) bool {
+	sort.SliceStable(out, func(i, j int) bool {
 		if out[i].File == out[j].File {
 			li := 0
 			lj := 0
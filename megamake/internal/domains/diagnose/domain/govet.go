@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	contract "github.com/megamake/megamake/internal/contracts/v1/diagnose"
+)
+
+// ParseGoVetJSON reads `go vet -json` output: one JSON object per package, keyed by package
+// path and then analyzer name, interleaved with "# pkg" headers and plain-text type errors.
+// The type errors are skipped (go build reports them already). The analyzer name becomes the
+// Code; rootPath makes the absolute positions vet prints root-relative.
+func ParseGoVetJSON(stdout string, stderr string, rootPath string) []contract.DiagnosticV1 {
+	var out []contract.DiagnosticV1
+	for _, text := range []string{stdout, stderr} {
+		for _, obj := range jsonObjects(text) {
+			var pkgs map[string]map[string]json.RawMessage
+			if json.Unmarshal([]byte(obj), &pkgs) != nil {
+				continue
+			}
+			for _, analyzers := range pkgs {
+				for name, raw := range analyzers {
+					var findings []struct {
+						Posn    string `json:"posn"`
+						End     string `json:"end"`
+						Message string `json:"message"`
+					}
+					// An analyzer that failed reports {"error": "..."} instead of a list.
+					if json.Unmarshal(raw, &findings) != nil {
+						continue
+					}
+					for _, f := range findings {
+						file, line, col := splitPosn(f.Posn)
+						d := contract.DiagnosticV1{
+							Tool:     "go vet",
+							Language: "go",
+							File:     relToRoot(file, rootPath),
+							Line:     line,
+							Column:   col,
+							Code:     name,
+							Severity: contract.SeverityWarning,
+							Message:  f.Message,
+						}
+						if endFile, endLine, endCol := splitPosn(f.End); endFile == file {
+							d.EndLine, d.EndColumn = endLine, endCol
+						}
+						out = append(out, d)
+					}
+				}
+			}
+		}
+	}
+	// Map order is random; keep the report stable.
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if la, lb := derefInt(a.Line), derefInt(b.Line); la != lb {
+			return la < lb
+		}
+		if ca, cb := derefInt(a.Column), derefInt(b.Column); ca != cb {
+			return ca < cb
+		}
+		return a.Code < b.Code
+	})
+	return out
+}
+
+// ParseStaticcheckJSON reads `staticcheck -f json` output (one JSON object per line). Check
+// IDs (SA4006, S1002, ...) become the Code; "compile" results duplicate go build's errors and
+// are skipped, as are results staticcheck marks ignored.
+func ParseStaticcheckJSON(stdout string, rootPath string) []contract.DiagnosticV1 {
+	type position struct {
+		File   string `json:"file"`
+		Line   int    `json:"line"`
+		Column int    `json:"column"`
+	}
+	var out []contract.DiagnosticV1
+	for _, ln := range strings.Split(stdout, "\n") {
+		ln = strings.TrimSpace(ln)
+		if !strings.HasPrefix(ln, "{") {
+			continue
+		}
+		var r struct {
+			Code     string   `json:"code"`
+			Severity string   `json:"severity"`
+			Location position `json:"location"`
+			End      position `json:"end"`
+			Message  string   `json:"message"`
+		}
+		if json.Unmarshal([]byte(ln), &r) != nil || r.Code == "compile" || r.Severity == "ignored" {
+			continue
+		}
+		sev := contract.SeverityWarning
+		if r.Severity == "error" {
+			sev = contract.SeverityError
+		}
+		d := contract.DiagnosticV1{
+			Tool:     "staticcheck",
+			Language: "go",
+			File:     relToRoot(r.Location.File, rootPath),
+			Line:     positivePtr(r.Location.Line),
+			Column:   positivePtr(r.Location.Column),
+			Code:     r.Code,
+			Severity: sev,
+			Message:  r.Message,
+		}
+		if r.End.File == r.Location.File {
+			d.EndLine, d.EndColumn = positivePtr(r.End.Line), positivePtr(r.End.Column)
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// jsonObjects returns the top-level {...} blocks that start at the beginning of a line.
+func jsonObjects(text string) []string {
+	var out []string
+	for len(text) > 0 {
+		start := 0
+		if !strings.HasPrefix(text, "{") {
+			i := strings.Index(text, "\n{")
+			if i < 0 {
+				break
+			}
+			start = i + 1
+		}
+		dec := json.NewDecoder(strings.NewReader(text[start:]))
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			text = text[start+1:]
+			continue
+		}
+		out = append(out, string(raw))
+		text = text[start+int(dec.InputOffset()):]
+	}
+	return out
+}
+
+// splitPosn splits "file:line:col" from the right, so Windows drive letters survive.
+func splitPosn(posn string) (string, *int, *int) {
+	posn = strings.TrimSpace(posn)
+	i := strings.LastIndex(posn, ":")
+	if i < 0 {
+		return posn, nil, nil
+	}
+	last, err := strconv.Atoi(posn[i+1:])
+	if err != nil {
+		return posn, nil, nil
+	}
+	head := posn[:i]
+	j := strings.LastIndex(head, ":")
+	if j >= 0 {
+		if line, err := strconv.Atoi(head[j+1:]); err == nil {
+			return head[:j], positivePtr(line), positivePtr(last)
+		}
+	}
+	return head, positivePtr(last), nil
+}
+
+func relToRoot(file string, rootPath string) string {
+	if file == "" || !filepath.IsAbs(file) {
+		return file
+	}
+	rootAbs, err := filepath.Abs(rootPath)
+	if err != nil {
+		return file
+	}
+	rel, err := filepath.Rel(rootAbs, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return file
+	}
+	return filepathToSlash(rel)
+}
+
+func derefInt(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func positivePtr(n int) *int {
+	if n <= 0 {
+		return nil
+	}
+	return &n
+}
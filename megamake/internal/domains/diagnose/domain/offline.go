@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	contract "github.com/megamake/megamake/internal/contracts/v1/diagnose"
+)
+
+// Toolchains happily download what they are missing: npx -y installs packages, cargo,
+// Maven and Gradle fetch dependencies, go fetches modules and toolchains. When network
+// access is disabled, each runner either passes the tool's offline switch (recorded as
+// forced) or, when the tool cannot work without downloading, does not run it (skipped).
+
+func newOffline() *contract.OfflineV1 {
+	return &contract.OfflineV1{Forced: []string{}, Skipped: []string{}}
+}
+
+func (r Runner) forceOffline(tool string) {
+	if r.offline == nil {
+		return
+	}
+	for _, t := range r.offline.Forced {
+		if t == tool {
+			return
+		}
+	}
+	r.offline.Forced = append(r.offline.Forced, tool)
+}
+
+func (r Runner) skipOffline(tool string, why string, warnings *[]string) {
+	if r.offline == nil {
+		return
+	}
+	for _, t := range r.offline.Skipped {
+		if t == tool {
+			return
+		}
+	}
+	r.offline.Skipped = append(r.offline.Skipped, tool)
+	*warnings = append(*warnings, tool+": "+why+" and network access is disabled; skipping (pass --net to allow downloads)")
+}
+
+// npxArgs returns the npx arguments that run pkg. Offline, npx may only use a package that
+// is already installed (node_modules/.bin or PATH); otherwise it reports false.
+func (r Runner) npxArgs(pkg string, args []string, warnings *[]string) ([]string, bool) {
+	if !r.Offline {
+		return append([]string{"-y", pkg}, args...), true
+	}
+	if !fileExists(filepath.Join(r.RootPath, "node_modules", ".bin", pkg)) {
+		if _, ok := r.Exec.Which(pkg); !ok {
+			r.skipOffline("npx "+pkg, pkg+" is not installed locally", warnings)
+			return nil, false
+		}
+	}
+	r.forceOffline("npx --no-install " + pkg)
+	return append([]string{"--no-install", pkg}, args...), true
+}
+
+// goEnv keeps go from downloading modules (GOPROXY=off) or toolchains (GOTOOLCHAIN=local).
+// -mod=mod lets go resolve from the module cache instead of failing on a stale go.sum;
+// vendored modules are left alone, since vendor/ is already the offline source.
+func (r Runner) goEnv() []string {
+	if !r.Offline {
+		return nil
+	}
+	env := []string{"GOPROXY=off", "GOTOOLCHAIN=local"}
+	desc := "go GOPROXY=off GOTOOLCHAIN=local"
+	flags := strings.TrimSpace(os.Getenv("GOFLAGS"))
+	// Workspace mode only accepts -mod=readonly or -mod=vendor.
+	if !strings.Contains(flags, "-mod=") && !fileExists(filepath.Join(r.RootPath, "vendor", "modules.txt")) && !r.inGoWorkspace() {
+		flags = strings.TrimSpace(flags + " -mod=mod")
+		desc += " GOFLAGS=-mod=mod"
+	}
+	if flags != "" {
+		env = append(env, "GOFLAGS="+flags)
+	}
+	r.forceOffline(desc)
+	return env
+}
+
+// inGoWorkspace reports whether go commands run from RootPath use a go.work: one named by
+// $GOWORK, or the nearest in RootPath or a parent directory.
+func (r Runner) inGoWorkspace() bool {
+	if r.goWorkOff {
+		return false
+	}
+	if gowork := strings.TrimSpace(os.Getenv("GOWORK")); gowork != "" {
+		return gowork != "off"
+	}
+	dir, err := filepath.Abs(r.RootPath)
+	if err != nil {
+		return false
+	}
+	for {
+		if fileExists(filepath.Join(dir, "go.work")) {
+			return true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
+
+// swiftDependenciesMissing reports whether Package.swift declares dependencies that have not
+// been checked out into .build yet; SwiftPM has no switch to build without fetching them.
+func (r Runner) swiftDependenciesMissing() bool {
+	b, err := os.ReadFile(filepath.Join(r.RootPath, "Package.swift"))
+	if err != nil || !strings.Contains(string(b), ".package(") {
+		return false
+	}
+	return !dirHasEntries(filepath.Join(r.RootPath, ".build", "checkouts"))
+}
+
+// lakeDependenciesMissing reports whether lake-manifest.json lists packages that are not in
+// .lake/packages yet; lake would clone them.
+func (r Runner) lakeDependenciesMissing() bool {
+	b, err := os.ReadFile(filepath.Join(r.RootPath, "lake-manifest.json"))
+	if err != nil {
+		return false
+	}
+	var m struct {
+		Packages []struct {
+			Name string `json:"name"`
+		} `json:"packages"`
+	}
+	if json.Unmarshal(b, &m) != nil {
+		return false
+	}
+	for _, p := range m.Packages {
+		if p.Name != "" && !fileExists(filepath.Join(r.RootPath, ".lake", "packages", p.Name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// gradleWrapperCached reports whether the distribution gradlew would download (per
+// gradle/wrapper/gradle-wrapper.properties) is already unpacked under the Gradle user home.
+// The wrapper ignores --offline when it fetches itself.
+func (r Runner) gradleWrapperCached() bool {
+	b, err := os.ReadFile(filepath.Join(r.RootPath, "gradle", "wrapper", "gradle-wrapper.properties"))
+	if err != nil {
+		return false
+	}
+	dist := ""
+	for _, ln := range strings.Split(string(b), "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(ln), "=")
+		if ok && strings.TrimSpace(k) == "distributionUrl" {
+			dist = strings.TrimSuffix(path.Base(strings.ReplaceAll(strings.TrimSpace(v), "\\:", ":")), ".zip")
+		}
+	}
+	if dist == "" {
+		return false
+	}
+	home := os.Getenv("GRADLE_USER_HOME")
+	if home == "" {
+		userHome, err := os.UserHomeDir()
+		if err != nil {
+			return false
+		}
+		home = filepath.Join(userHome, ".gradle")
+	}
+	return dirHasEntries(filepath.Join(home, "wrapper", "dists", dist))
+}
+
+func dirHasEntries(p string) bool {
+	entries, err := os.ReadDir(p)
+	return err == nil && len(entries) > 0
+}
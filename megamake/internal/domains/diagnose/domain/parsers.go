@@ -190,50 +190,6 @@ func ParseUnixStyle(stdout string, stderr string, language string, tool string)
 	return out
 }
 
-func ParsePython(stdout string, stderr string) []contract.DiagnosticV1 {
-	tool := "python -m py_compile"
-	var out []contract.DiagnosticV1
-	combined := stdout + "\n" + stderr
-	lines := strings.Split(combined, "\n")
-
-	fileRe := regexp.MustCompile(`^\s*File\s+"(.+?)",\s+line\s+(\d+).*$`)
-	errRe := regexp.MustCompile(`^(SyntaxError|IndentationError|NameError|TypeError):\s*(.+)$`)
-
-	for i := 0; i < len(lines); i++ {
-		line := lines[i]
-		m := fileRe.FindStringSubmatch(line)
-		if len(m) < 3 {
-			continue
-		}
-		file := m[1]
-		lineN := atoiPtr(m[2])
-
-		code := "SyntaxError"
-		msg := "syntax error"
-
-		for j := i + 1; j < len(lines); j++ {
-			em := errRe.FindStringSubmatch(strings.TrimSpace(lines[j]))
-			if len(em) >= 3 {
-				code = em[1]
-				msg = em[2]
-				break
-			}
-		}
-
-		out = append(out, contract.DiagnosticV1{
-			Tool:     tool,
-			Language: "python",
-			File:     file,
-			Line:     lineN,
-			Column:   nil,
-			Code:     code,
-			Severity: contract.SeverityError,
-			Message:  msg,
-		})
-	}
-	return out
-}
-
 func ParseRust(stdout string, stderr string) []contract.DiagnosticV1 {
 	tool := "cargo check"
 	var out []contract.DiagnosticV1
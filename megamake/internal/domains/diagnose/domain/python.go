@@ -0,0 +1,287 @@
+package domain
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	contract "github.com/megamake/megamake/internal/contracts/v1/diagnose"
+)
+
+// pyCompileScript compiles every file named on the command line in one interpreter and prints
+// one JSON line per failure. It does what py_compile does, minus writing .pyc files into the
+// project, and unlike `python -m py_compile a.py b.py` it does not stop at the first error.
+const pyCompileScript = `import json, sys
+for p in sys.argv[1:]:
+    try:
+        with open(p, "rb") as fh:
+            compile(fh.read(), p, "exec", dont_inherit=True)
+    except SyntaxError as e:
+        print(json.dumps({"file": p, "line": e.lineno, "column": e.offset, "endLine": getattr(e, "end_lineno", None), "endColumn": getattr(e, "end_offset", None), "code": type(e).__name__, "message": e.msg}))
+    except (ValueError, OSError) as e:
+        print(json.dumps({"file": p, "code": type(e).__name__, "message": str(e)}))
+`
+
+// pyBatchArgBytes keeps each batch's command line well below the Windows limit (32767).
+const pyBatchArgBytes = 24000
+
+// pyBatches splits files into command-line-sized batches.
+func pyBatches(files []string) [][]string {
+	var out [][]string
+	var cur []string
+	size := 0
+	for _, f := range files {
+		if len(cur) > 0 && size+len(f)+1 > pyBatchArgBytes {
+			out = append(out, cur)
+			cur, size = nil, 0
+		}
+		cur = append(cur, f)
+		size += len(f) + 1
+	}
+	if len(cur) > 0 {
+		out = append(out, cur)
+	}
+	return out
+}
+
+// ParsePyCompileJSON reads the JSON lines pyCompileScript prints.
+func ParsePyCompileJSON(stdout string) []contract.DiagnosticV1 {
+	var out []contract.DiagnosticV1
+	for _, ln := range strings.Split(stdout, "\n") {
+		ln = strings.TrimSpace(ln)
+		if !strings.HasPrefix(ln, "{") {
+			continue
+		}
+		var r struct {
+			File      string `json:"file"`
+			Line      int    `json:"line"`
+			Column    int    `json:"column"`
+			EndLine   int    `json:"endLine"`
+			EndColumn int    `json:"endColumn"`
+			Code      string `json:"code"`
+			Message   string `json:"message"`
+		}
+		if json.Unmarshal([]byte(ln), &r) != nil {
+			continue
+		}
+		out = append(out, contract.DiagnosticV1{
+			Tool:      "py_compile",
+			Language:  "python",
+			File:      r.File,
+			Line:      positivePtr(r.Line),
+			Column:    positivePtr(r.Column),
+			EndLine:   positivePtr(r.EndLine),
+			EndColumn: positivePtr(r.EndColumn),
+			Code:      r.Code,
+			Severity:  contract.SeverityError,
+			Message:   r.Message,
+		})
+	}
+	return out
+}
+
+type ruffLocation struct {
+	Row    int `json:"row"`
+	Column int `json:"column"`
+}
+
+// ParseRuffJSON reads `ruff check --output-format=json`: a JSON array of violations. The rule
+// (F401, E501, ...) becomes the Code and ruff's fix edits become Suggestions. Syntax errors
+// have no rule and are reported as errors; everything else is a warning.
+func ParseRuffJSON(stdout string, rootPath string) []contract.DiagnosticV1 {
+	var violations []struct {
+		Code        *string      `json:"code"`
+		Message     string       `json:"message"`
+		Filename    string       `json:"filename"`
+		Location    ruffLocation `json:"location"`
+		EndLocation ruffLocation `json:"end_location"`
+		Fix         *struct {
+			Applicability string `json:"applicability"`
+			Message       string `json:"message"`
+			Edits         []struct {
+				Content     string       `json:"content"`
+				Location    ruffLocation `json:"location"`
+				EndLocation ruffLocation `json:"end_location"`
+			} `json:"edits"`
+		} `json:"fix"`
+	}
+	s := strings.TrimSpace(stdout)
+	if i := strings.Index(s, "["); i > 0 {
+		s = s[i:]
+	}
+	if json.Unmarshal([]byte(s), &violations) != nil {
+		return nil
+	}
+
+	var out []contract.DiagnosticV1
+	for _, v := range violations {
+		file := relToRoot(v.Filename, rootPath)
+		d := contract.DiagnosticV1{
+			Tool:      "ruff",
+			Language:  "python",
+			File:      file,
+			Line:      positivePtr(v.Location.Row),
+			Column:    positivePtr(v.Location.Column),
+			EndLine:   positivePtr(v.EndLocation.Row),
+			EndColumn: positivePtr(v.EndLocation.Column),
+			Severity:  contract.SeverityWarning,
+			Message:   v.Message,
+		}
+		if v.Code != nil && *v.Code != "" {
+			d.Code = *v.Code
+		} else {
+			d.Severity = contract.SeverityError
+		}
+		if v.Fix != nil {
+			for _, e := range v.Fix.Edits {
+				d.Suggestions = append(d.Suggestions, contract.SuggestionV1{
+					Message:       v.Fix.Message,
+					File:          file,
+					Line:          positivePtr(e.Location.Row),
+					Column:        positivePtr(e.Location.Column),
+					EndLine:       positivePtr(e.EndLocation.Row),
+					EndColumn:     positivePtr(e.EndLocation.Column),
+					Replacement:   e.Content,
+					Applicability: v.Fix.Applicability,
+				})
+			}
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// ParseMypyJSON reads `mypy --output json` (mypy 1.11+): one JSON object per line. The error
+// code (arg-type, attr-defined, ...) becomes the Code and the hint a Related note. mypy's
+// columns are 0-based.
+func ParseMypyJSON(stdout string) []contract.DiagnosticV1 {
+	var out []contract.DiagnosticV1
+	for _, ln := range strings.Split(stdout, "\n") {
+		ln = strings.TrimSpace(ln)
+		if !strings.HasPrefix(ln, "{") {
+			continue
+		}
+		var r struct {
+			File     string  `json:"file"`
+			Line     int     `json:"line"`
+			Column   int     `json:"column"`
+			Message  string  `json:"message"`
+			Hint     *string `json:"hint"`
+			Code     *string `json:"code"`
+			Severity string  `json:"severity"`
+		}
+		if json.Unmarshal([]byte(ln), &r) != nil {
+			continue
+		}
+		d := contract.DiagnosticV1{
+			Tool:     "mypy",
+			Language: "python",
+			File:     r.File,
+			Line:     positivePtr(r.Line),
+			Column:   positivePtr(r.Column + 1),
+			Severity: mypySeverity(r.Severity),
+			Message:  r.Message,
+		}
+		if r.Code != nil {
+			d.Code = *r.Code
+		}
+		if r.Hint != nil && strings.TrimSpace(*r.Hint) != "" {
+			d.Related = append(d.Related, contract.RelatedV1{Message: "note: " + strings.TrimSpace(*r.Hint)})
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+var mypyTextRe = regexp.MustCompile(`^(.+?\.pyi?):(\d+):(?:(\d+):)?\s+(error|warning|note):\s+(.*?)(?:\s+\[([a-z0-9-]+)\])?$`)
+
+// ParseMypyText reads the text output of mypy versions without --output json (run with
+// --show-column-numbers --show-error-codes). Notes attach to the preceding diagnostic.
+func ParseMypyText(stdout string) []contract.DiagnosticV1 {
+	var out []contract.DiagnosticV1
+	for _, ln := range strings.Split(stdout, "\n") {
+		m := mypyTextRe.FindStringSubmatch(strings.TrimRight(ln, "\r"))
+		if len(m) < 7 {
+			continue
+		}
+		if m[4] == "note" && len(out) > 0 {
+			prev := &out[len(out)-1]
+			prev.Related = append(prev.Related, contract.RelatedV1{File: m[1], Line: atoiPtr(m[2]), Column: atoiPtr(m[3]), Message: "note: " + m[5]})
+			continue
+		}
+		out = append(out, contract.DiagnosticV1{
+			Tool:     "mypy",
+			Language: "python",
+			File:     m[1],
+			Line:     atoiPtr(m[2]),
+			Column:   atoiPtr(m[3]),
+			Code:     m[6],
+			Severity: mypySeverity(m[4]),
+			Message:  m[5],
+		})
+	}
+	return out
+}
+
+func mypySeverity(s string) contract.SeverityV1 {
+	switch s {
+	case "error":
+		return contract.SeverityError
+	case "warning":
+		return contract.SeverityWarning
+	}
+	return contract.SeverityInfo
+}
+
+// ParsePyrightJSON reads `pyright --outputjson`. The rule (reportMissingImports, ...) becomes
+// the Code; pyright's ranges are 0-based.
+func ParsePyrightJSON(stdout string, rootPath string) []contract.DiagnosticV1 {
+	type position struct {
+		Line      int `json:"line"`
+		Character int `json:"character"`
+	}
+	var r struct {
+		GeneralDiagnostics []struct {
+			File     string `json:"file"`
+			Severity string `json:"severity"`
+			Message  string `json:"message"`
+			Rule     string `json:"rule"`
+			Range    *struct {
+				Start position `json:"start"`
+				End   position `json:"end"`
+			} `json:"range"`
+		} `json:"generalDiagnostics"`
+	}
+	s := strings.TrimSpace(stdout)
+	if i := strings.Index(s, "{"); i > 0 {
+		s = s[i:]
+	}
+	if json.Unmarshal([]byte(s), &r) != nil {
+		return nil
+	}
+
+	var out []contract.DiagnosticV1
+	for _, g := range r.GeneralDiagnostics {
+		sev := contract.SeverityInfo
+		switch g.Severity {
+		case "error":
+			sev = contract.SeverityError
+		case "warning":
+			sev = contract.SeverityWarning
+		}
+		d := contract.DiagnosticV1{
+			Tool:     "pyright",
+			Language: "python",
+			File:     relToRoot(g.File, rootPath),
+			Code:     g.Rule,
+			Severity: sev,
+			Message:  g.Message,
+		}
+		if g.Range != nil {
+			d.Line, d.Column = positivePtr(g.Range.Start.Line+1), positivePtr(g.Range.Start.Character+1)
+			d.EndLine, d.EndColumn = positivePtr(g.Range.End.Line+1), positivePtr(g.Range.End.Character+1)
+		}
+		out = append(out, d)
+	}
+	return out
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -21,34 +22,46 @@ type Runner struct {
 	IgnoreNames  []string
 	IgnoreGlobs  []string
 	Exec         ports.Exec
+
+	// Offline keeps toolchains from downloading (see offline.go); set when --net is off.
+	Offline bool
+	offline *contract.OfflineV1
+
+	// skip names toolchains Run must not start (see rootSkips); goWorkOff builds Go with
+	// GOWORK=off, for a root module its go.work does not use.
+	skip      map[string]bool
+	goWorkOff bool
 }
 
 func (r Runner) Run(profile project.ProjectProfileV1, pyRelFiles []string) (contract.DiagnosticsReportV1, []string) {
 	var warnings []string
 	var langs []contract.LanguageDiagnosticsV1
+	if r.Offline {
+		r.offline = newOffline()
+	}
 
 	// Keep "attempted languages" consistent (include empty buckets).
-	if fileExists(filepath.Join(r.RootPath, "Package.swift")) {
+	if fileExists(filepath.Join(r.RootPath, "Package.swift")) && !r.skip["swift"] {
 		langs = append(langs, r.runSwift(&warnings))
 	}
-	if fileExists(filepath.Join(r.RootPath, "tsconfig.json")) || fileExists(filepath.Join(r.RootPath, "package.json")) {
+	if (fileExists(filepath.Join(r.RootPath, "tsconfig.json")) || fileExists(filepath.Join(r.RootPath, "package.json"))) && !r.skip["javascript"] {
 		langs = append(langs, r.runTypeScriptOrJS(&warnings))
 	}
-	if fileExists(filepath.Join(r.RootPath, "go.mod")) {
+	if fileExists(filepath.Join(r.RootPath, "go.mod")) && !r.skip["go"] {
 		langs = append(langs, r.runGo(&warnings))
 	}
-	if fileExists(filepath.Join(r.RootPath, "Cargo.toml")) {
+	if fileExists(filepath.Join(r.RootPath, "Cargo.toml")) && !r.skip["rust"] {
 		langs = append(langs, r.runRust(&warnings))
 	}
 	if len(pyRelFiles) > 0 {
 		langs = append(langs, r.runPython(pyRelFiles, &warnings))
 	}
-	if fileExists(filepath.Join(r.RootPath, "pom.xml")) ||
+	if (fileExists(filepath.Join(r.RootPath, "pom.xml")) ||
 		fileExists(filepath.Join(r.RootPath, "build.gradle")) ||
-		fileExists(filepath.Join(r.RootPath, "build.gradle.kts")) {
+		fileExists(filepath.Join(r.RootPath, "build.gradle.kts"))) && !r.skip["java"] {
 		langs = append(langs, r.runJava(&warnings))
 	}
-	if fileExists(filepath.Join(r.RootPath, "lakefile.lean")) || fileExists(filepath.Join(r.RootPath, "lean-toolchain")) {
+	if (fileExists(filepath.Join(r.RootPath, "lakefile.lean")) || fileExists(filepath.Join(r.RootPath, "lean-toolchain"))) && !r.skip["lean"] {
 		langs = append(langs, r.runLean(&warnings))
 	}
 
@@ -75,10 +88,123 @@ func (r Runner) Run(profile project.ProjectProfileV1, pyRelFiles []string) (cont
 		Languages:   filtered,
 		GeneratedAt: "", // filled by app/service using clock
 		Warnings:    warnings,
+		Offline:     r.offline,
 	}
 	return rep, warnings
 }
 
+// rootPackage tags the buckets of the workspace root's own toolchains.
+const rootPackage = "(root)"
+
+// RunWorkspace runs the toolchains of each workspace member from the member's own
+// directory and tags the buckets with the member name. Issue paths are rebased onto the
+// workspace root. When the root is not itself a member, its own toolchains run too (a
+// Cargo root package, a root Go module its go.work does not use); see rootSkips. Python
+// files are compiled once for the whole tree.
+func (r Runner) RunWorkspace(profile project.ProjectProfileV1, pyRelFiles []string) (contract.DiagnosticsReportV1, []string) {
+	var warnings []string
+	var langs []contract.LanguageDiagnosticsV1
+	var offline *contract.OfflineV1
+	if r.Offline {
+		offline = newOffline()
+	}
+
+	add := func(name string, relPath string, rep contract.DiagnosticsReportV1, runWarnings []string) {
+		for _, w := range runWarnings {
+			warnings = append(warnings, name+": "+w)
+		}
+		if o := rep.Offline; o != nil && offline != nil {
+			for _, t := range o.Forced {
+				offline.Forced = append(offline.Forced, name+": "+t)
+			}
+			for _, t := range o.Skipped {
+				offline.Skipped = append(offline.Skipped, name+": "+t)
+			}
+		}
+		for _, ld := range rep.Languages {
+			ld.Package = name
+			for i := range ld.Issues {
+				ld.Issues[i].File = rebaseIssuePath(ld.Issues[i].File, relPath)
+			}
+			langs = append(langs, ld)
+		}
+	}
+
+	rootIsMember := false
+	for _, m := range profile.Members {
+		rootIsMember = rootIsMember || m.RelPath == "."
+		mr := r
+		mr.RootPath = filepath.Join(r.RootPath, filepath.FromSlash(m.RelPath))
+		rep, memberWarnings := mr.Run(m.Profile, nil)
+		add(m.Name, m.RelPath, rep, memberWarnings)
+	}
+
+	if !rootIsMember {
+		rr := r
+		rr.skip = rootSkips(r.RootPath, profile.Workspaces)
+		rr.goWorkOff = containsString(profile.Workspaces, "go.work")
+		// An empty profile: no placeholder buckets when the root has no toolchain of its own.
+		rep, rootWarnings := rr.Run(project.ProjectProfileV1{}, nil)
+		add(rootPackage, ".", rep, rootWarnings)
+	}
+
+	if len(pyRelFiles) > 0 {
+		ld := r.runPython(pyRelFiles, &warnings)
+		ld.Issues = SortedIssuesByFile(r.filterIssues(ld.Issues))
+		langs = append(langs, ld)
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		if langs[i].Name != langs[j].Name {
+			return langs[i].Name < langs[j].Name
+		}
+		return langs[i].Package < langs[j].Package
+	})
+
+	return contract.DiagnosticsReportV1{Languages: langs, Warnings: warnings, Offline: offline}, warnings
+}
+
+var cargoPackageRe = regexp.MustCompile(`(?m)^\s*\[package\]`)
+
+// rootSkips names the root toolchains that only declare the workspace and have no code of
+// their own to check: a virtual Cargo manifest, the package.json of npm/pnpm workspaces and
+// the Gradle build of a settings.gradle multi-project.
+func rootSkips(rootPath string, workspaces []string) map[string]bool {
+	skip := map[string]bool{}
+	for _, w := range workspaces {
+		switch w {
+		case "Cargo.toml":
+			b, err := os.ReadFile(filepath.Join(rootPath, "Cargo.toml"))
+			if err != nil || !cargoPackageRe.Match(b) {
+				skip["rust"] = true
+			}
+		case "package.json", "pnpm-workspace.yaml":
+			skip["javascript"] = true
+		case "settings.gradle", "settings.gradle.kts":
+			skip["java"] = true
+		}
+	}
+	return skip
+}
+
+func containsString(xs []string, s string) bool {
+	for _, x := range xs {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// rebaseIssuePath turns a path relative to a member directory into one relative to the
+// workspace root; absolute paths and empty paths are kept.
+func rebaseIssuePath(file string, memberRel string) string {
+	if strings.TrimSpace(file) == "" || filepath.IsAbs(file) || memberRel == "." {
+		return file
+	}
+	return path.Join(memberRel, filepathToSlash(file))
+}
+
 func (r Runner) runSwift(warnings *[]string) contract.LanguageDiagnosticsV1 {
 	tool := "swift build"
 	var issues []contract.DiagnosticV1
@@ -91,6 +217,14 @@ func (r Runner) runSwift(warnings *[]string) contract.LanguageDiagnosticsV1 {
 	if r.IncludeTests {
 		args = append(args, "--build-tests")
 	}
+	if r.Offline {
+		if r.swiftDependenciesMissing() {
+			r.skipOffline(tool, "package dependencies are not checked out yet", warnings)
+			return contract.LanguageDiagnosticsV1{Name: "swift", Tool: tool, Issues: nil}
+		}
+		args = append(args, "--disable-automatic-resolution")
+		r.forceOffline(tool + " --disable-automatic-resolution")
+	}
 	res := r.Exec.Run(p, args, r.RootPath, r.Timeout)
 	issues = append(issues, ParseSwift(res.Stdout, res.Stderr)...)
 	return contract.LanguageDiagnosticsV1{Name: "swift", Tool: tool, Issues: issues}
@@ -104,6 +238,10 @@ func (r Runner) runLean(warnings *[]string) contract.LanguageDiagnosticsV1 {
 		*warnings = append(*warnings, "lake not found in PATH; skipping Lean diagnostics (install Lean 4 via elan, which provides lake)")
 		return contract.LanguageDiagnosticsV1{Name: "lean", Tool: tool, Issues: nil}
 	}
+	if r.Offline && r.lakeDependenciesMissing() {
+		r.skipOffline(tool, "lake-manifest.json lists packages that are not in .lake/packages yet", warnings)
+		return contract.LanguageDiagnosticsV1{Name: "lean", Tool: tool, Issues: nil}
+	}
 	res := r.Exec.Run(p, []string{"build"}, r.RootPath, r.Timeout)
 	issues = append(issues, ParseLean(res.Stdout, res.Stderr)...)
 	return contract.LanguageDiagnosticsV1{Name: "lean", Tool: tool, Issues: issues}
@@ -121,11 +259,13 @@ func (r Runner) runTypeScriptOrJS(warnings *[]string) contract.LanguageDiagnosti
 
 	tryTSC := func(args []string) bool {
 		if npx, ok := r.Exec.Which("npx"); ok {
-			usedTool = "npx tsc"
-			res := r.Exec.Run(npx, append([]string{"-y", "tsc"}, args...), r.RootPath, r.Timeout)
-			diags := ParseTypeScript(res.Stdout, res.Stderr, lang, usedTool)
-			issues = append(issues, diags...)
-			return len(diags) > 0 || res.ExitCode != 0
+			if npxArgs, ok := r.npxArgs("tsc", args, warnings); ok {
+				usedTool = "npx tsc"
+				res := r.Exec.Run(npx, npxArgs, r.RootPath, r.Timeout)
+				diags := ParseTypeScript(res.Stdout, res.Stderr, lang, usedTool)
+				issues = append(issues, diags...)
+				return len(diags) > 0 || res.ExitCode != 0
+			}
 		}
 		if tsc, ok := r.Exec.Which("tsc"); ok {
 			usedTool = "tsc"
@@ -137,16 +277,22 @@ func (r Runner) runTypeScriptOrJS(warnings *[]string) contract.LanguageDiagnosti
 		return false
 	}
 
+	// A project with an ESLint config gets a full lint pass with JSON output instead of the
+	// unix-format fallbacks below.
+	lintConfigured := hasESLintConfig(r.RootPath)
+
 	if hasTS {
 		_ = tryTSC([]string{"-p", ".", "--noEmit"})
 	} else {
 		// JS-only: attempt tsc in checkJs mode, then eslint unix.
 		ok := tryTSC([]string{"--allowJs", "--checkJs", "--noEmit"})
-		if !ok || len(issues) == 0 {
+		if !lintConfigured && (!ok || len(issues) == 0) {
 			if npx, ok2 := r.Exec.Which("npx"); ok2 {
-				usedTool = "eslint -f unix"
-				res := r.Exec.Run(npx, []string{"-y", "eslint", "-f", "unix", "."}, r.RootPath, r.Timeout)
-				issues = append(issues, ParseUnixStyle(res.Stdout, res.Stderr, "javascript", "eslint")...)
+				if npxArgs, ok3 := r.npxArgs("eslint", []string{"-f", "unix", "."}, warnings); ok3 {
+					usedTool = "eslint -f unix"
+					res := r.Exec.Run(npx, npxArgs, r.RootPath, r.Timeout)
+					issues = append(issues, ParseUnixStyle(res.Stdout, res.Stderr, "javascript", "eslint")...)
+				}
 			} else if eslint, ok2 := r.Exec.Which("eslint"); ok2 {
 				usedTool = "eslint -f unix"
 				res := r.Exec.Run(eslint, []string{"-f", "unix", "."}, r.RootPath, r.Timeout)
@@ -157,8 +303,19 @@ func (r Runner) runTypeScriptOrJS(warnings *[]string) contract.LanguageDiagnosti
 		}
 	}
 
+	if lintConfigured {
+		if lint, ok := r.runESLintJSON(lang, warnings); ok {
+			issues = append(issues, lint...)
+			if usedTool == "" {
+				usedTool = "eslint -f json"
+			} else {
+				usedTool += " + eslint -f json"
+			}
+		}
+	}
+
 	// When including tests, run eslint over common test globs if available.
-	if r.IncludeTests {
+	if r.IncludeTests && !lintConfigured {
 		globs := []string{}
 		if hasTS {
 			globs = []string{"**/*.test.ts", "**/*.spec.ts", "**/*.test.tsx", "**/*.spec.tsx"}
@@ -166,10 +323,12 @@ func (r Runner) runTypeScriptOrJS(warnings *[]string) contract.LanguageDiagnosti
 			globs = []string{"**/*.test.js", "**/*.spec.js", "**/*.test.jsx", "**/*.spec.jsx"}
 		}
 		if npx, ok := r.Exec.Which("npx"); ok {
-			res := r.Exec.Run(npx, append([]string{"-y", "eslint", "-f", "unix"}, globs...), r.RootPath, r.Timeout)
-			issues = append(issues, ParseUnixStyle(res.Stdout, res.Stderr, lang, "eslint")...)
-			if usedTool == "" {
-				usedTool = "eslint -f unix"
+			if npxArgs, ok := r.npxArgs("eslint", append([]string{"-f", "unix"}, globs...), warnings); ok {
+				res := r.Exec.Run(npx, npxArgs, r.RootPath, r.Timeout)
+				issues = append(issues, ParseUnixStyle(res.Stdout, res.Stderr, lang, "eslint")...)
+				if usedTool == "" {
+					usedTool = "eslint -f unix"
+				}
 			}
 		} else if eslint, ok := r.Exec.Which("eslint"); ok {
 			res := r.Exec.Run(eslint, append([]string{"-f", "unix"}, globs...), r.RootPath, r.Timeout)
@@ -191,6 +350,39 @@ func (r Runner) runTypeScriptOrJS(warnings *[]string) contract.LanguageDiagnosti
 	}
 }
 
+// runESLintJSON lints the whole project with its own ESLint config. Findings in test files
+// are dropped unless tests were asked for.
+func (r Runner) runESLintJSON(lang string, warnings *[]string) ([]contract.DiagnosticV1, bool) {
+	args := []string{"-f", "json", "."}
+	var res ports.ExecResult
+	if npx, ok := r.Exec.Which("npx"); ok {
+		npxArgs, ok := r.npxArgs("eslint", args, warnings)
+		if !ok {
+			return nil, false
+		}
+		res = r.Exec.Run(npx, npxArgs, r.RootPath, r.Timeout)
+	} else if eslint, ok := r.Exec.Which("eslint"); ok {
+		res = r.Exec.Run(eslint, args, r.RootPath, r.Timeout)
+	} else {
+		*warnings = append(*warnings, "ESLint is configured but neither npx nor eslint is in PATH; skipping lint")
+		return nil, false
+	}
+
+	// Exit code 1 means lint problems were found; 2 means ESLint itself failed.
+	if res.ExitCode > 1 {
+		*warnings = append(*warnings, "eslint failed: "+lastLine(res.Stderr))
+		return nil, false
+	}
+	var out []contract.DiagnosticV1
+	for _, d := range ParseESLintJSON(res.Stdout, r.RootPath, lang) {
+		if !r.IncludeTests && isJSTestFile(d.File) {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out, true
+}
+
 func (r Runner) runGo(warnings *[]string) contract.LanguageDiagnosticsV1 {
 	tool := "go build"
 	var issues []contract.DiagnosticV1
@@ -200,28 +392,61 @@ func (r Runner) runGo(warnings *[]string) contract.LanguageDiagnosticsV1 {
 		return contract.LanguageDiagnosticsV1{Name: "go", Tool: tool, Issues: nil}
 	}
 
+	env := r.goEnv()
+	if r.goWorkOff {
+		env = append(env, "GOWORK=off")
+	}
+
 	// Global build
-	res := r.Exec.Run(goPath, []string{"build", "-gcflags=all=-e", "./..."}, r.RootPath, r.Timeout)
+	res := r.Exec.RunEnv(goPath, []string{"build", "-gcflags=all=-e", "./..."}, r.RootPath, env, r.Timeout)
 	issues = append(issues, ParseGo(res.Stdout, res.Stderr)...)
 
 	// Per-package build (best-effort)
-	pkgs := r.listGoPackages(goPath)
+	pkgs := r.listGoPackages(goPath, env)
 	for _, pkg := range pkgs {
-		res2 := r.Exec.Run(goPath, []string{"build", "-gcflags=all=-e", pkg}, r.RootPath, r.Timeout)
+		res2 := r.Exec.RunEnv(goPath, []string{"build", "-gcflags=all=-e", pkg}, r.RootPath, env, r.Timeout)
 		issues = append(issues, ParseGo(res2.Stdout, res2.Stderr)...)
 
 		if r.IncludeTests {
 			devNull := r.Exec.DevNullPath()
-			resT := r.Exec.Run(goPath, []string{"test", "-c", "-o", devNull, pkg}, r.RootPath, r.Timeout)
+			resT := r.Exec.RunEnv(goPath, []string{"test", "-c", "-o", devNull, pkg}, r.RootPath, env, r.Timeout)
 			issues = append(issues, ParseGo(resT.Stdout, resT.Stderr)...)
 		}
 	}
 
-	return contract.LanguageDiagnosticsV1{Name: "go", Tool: "go build (-gcflags=all=-e)", Issues: issues}
+	tool = "go build (-gcflags=all=-e) + go vet"
+	issues = append(issues, r.runGoAnalyzers(goPath, env, &tool)...)
+
+	return contract.LanguageDiagnosticsV1{Name: "go", Tool: tool, Issues: issues}
 }
 
-func (r Runner) listGoPackages(goPath string) []string {
-	res := r.Exec.Run(goPath, []string{"list", "./..."}, r.RootPath, r.Timeout)
+// runGoAnalyzers runs go vet (and staticcheck when installed) with JSON output. They catch
+// what compiles but is wrong: printf verbs, copied locks, lost context cancels and the like.
+func (r Runner) runGoAnalyzers(goPath string, env []string, tool *string) []contract.DiagnosticV1 {
+	// vet always checks _test.go files too; drop those findings unless tests were asked for.
+	res := r.Exec.RunEnv(goPath, []string{"vet", "-json", "./..."}, r.RootPath, env, r.Timeout)
+	var out []contract.DiagnosticV1
+	for _, d := range ParseGoVetJSON(res.Stdout, res.Stderr, r.RootPath) {
+		if !r.IncludeTests && strings.HasSuffix(d.File, "_test.go") {
+			continue
+		}
+		out = append(out, d)
+	}
+
+	if sc, ok := r.Exec.Which("staticcheck"); ok {
+		args := []string{"-f", "json"}
+		if !r.IncludeTests {
+			args = append(args, "-tests=false")
+		}
+		resS := r.Exec.RunEnv(sc, append(args, "./..."), r.RootPath, env, r.Timeout)
+		out = append(out, ParseStaticcheckJSON(resS.Stdout, r.RootPath)...)
+		*tool += " + staticcheck"
+	}
+	return out
+}
+
+func (r Runner) listGoPackages(goPath string, env []string) []string {
+	res := r.Exec.RunEnv(goPath, []string{"list", "./..."}, r.RootPath, env, r.Timeout)
 	combined := res.Stdout + "\n" + res.Stderr
 	lines := strings.Split(combined, "\n")
 	set := map[string]bool{}
@@ -255,18 +480,33 @@ func (r Runner) runRust(warnings *[]string) contract.LanguageDiagnosticsV1 {
 		*warnings = append(*warnings, "cargo not found in PATH; skipping Rust diagnostics")
 		return contract.LanguageDiagnosticsV1{Name: "rust", Tool: tool, Issues: nil}
 	}
-	res := r.Exec.Run(cargo, []string{"check", "--color", "never"}, r.RootPath, r.Timeout)
-	issues = append(issues, ParseRust(res.Stdout, res.Stderr)...)
+	var offline []string
+	if r.Offline {
+		offline = []string{"--offline"}
+		r.forceOffline("cargo --offline")
+	}
+	args := append([]string{"check", "--color", "never", "--message-format=json"}, offline...)
+	issues = append(issues, parseCargo(r.Exec.Run(cargo, args, r.RootPath, r.Timeout))...)
 
 	if r.IncludeTests {
-		resT := r.Exec.Run(cargo, []string{"test", "--no-run", "--color", "never"}, r.RootPath, r.Timeout)
-		issues = append(issues, ParseRust(resT.Stdout, resT.Stderr)...)
+		args := append([]string{"test", "--no-run", "--color", "never", "--message-format=json"}, offline...)
+		issues = append(issues, parseCargo(r.Exec.Run(cargo, args, r.RootPath, r.Timeout))...)
 	}
 	return contract.LanguageDiagnosticsV1{Name: "rust", Tool: tool, Issues: issues}
 }
 
+// parseCargo decodes the JSON messages. When cargo fails before rustc runs (a broken
+// Cargo.toml, an unresolvable dependency) there are none, and its text errors are parsed.
+func parseCargo(res ports.ExecResult) []contract.DiagnosticV1 {
+	issues := ParseCargoJSON(res.Stdout)
+	if len(issues) == 0 && res.ExitCode != 0 {
+		issues = ParseRust("", res.Stderr)
+	}
+	return issues
+}
+
 func (r Runner) runPython(pyRelFiles []string, warnings *[]string) contract.LanguageDiagnosticsV1 {
-	tool := "python -m py_compile"
+	tool := "py_compile"
 	var issues []contract.DiagnosticV1
 	py := ""
 	if p, ok := r.Exec.Which("python3"); ok {
@@ -279,14 +519,70 @@ func (r Runner) runPython(pyRelFiles []string, warnings *[]string) contract.Lang
 		return contract.LanguageDiagnosticsV1{Name: "python", Tool: tool, Issues: nil}
 	}
 
-	for _, rel := range pyRelFiles {
-		abs := filepath.Join(r.RootPath, filepath.FromSlash(rel))
-		res := r.Exec.Run(py, []string{"-m", "py_compile", abs}, r.RootPath, minDuration(r.Timeout, 30*time.Second))
-		issues = append(issues, ParsePython(res.Stdout, res.Stderr)...)
+	// One interpreter per command-line-sized batch rather than one per file.
+	for _, batch := range pyBatches(pyRelFiles) {
+		res := r.Exec.Run(py, append([]string{"-c", pyCompileScript}, batch...), r.RootPath, r.Timeout)
+		if res.ExitCode != 0 {
+			*warnings = append(*warnings, "python compile check failed: "+lastLine(res.Stderr))
+		}
+		issues = append(issues, ParsePyCompileJSON(res.Stdout)...)
 	}
+
+	issues = append(issues, r.runPythonCheckers(pyRelFiles, &tool)...)
 	return contract.LanguageDiagnosticsV1{Name: "python", Tool: tool, Issues: issues}
 }
 
+// runPythonCheckers runs whichever of ruff, mypy and pyright is installed, with JSON output,
+// over the same files. They run from the root, so each picks up its settings from
+// pyproject.toml ([tool.ruff], [tool.mypy], [tool.pyright]) or its own config file there;
+// ruff also applies the configured excludes (--force-exclude).
+func (r Runner) runPythonCheckers(files []string, tool *string) []contract.DiagnosticV1 {
+	var out []contract.DiagnosticV1
+	batches := pyBatches(files)
+
+	if ruff, ok := r.Exec.Which("ruff"); ok {
+		for _, batch := range batches {
+			args := append([]string{"check", "--output-format=json", "--exit-zero", "--no-fix", "--force-exclude"}, batch...)
+			res := r.Exec.Run(ruff, args, r.RootPath, r.Timeout)
+			out = append(out, ParseRuffJSON(res.Stdout, r.RootPath)...)
+		}
+		*tool += " + ruff"
+	}
+
+	if mypy, ok := r.Exec.Which("mypy"); ok {
+		// --output json needs mypy 1.11; older versions get the text format.
+		jsonOut := true
+		for _, batch := range batches {
+			if jsonOut {
+				res := r.Exec.Run(mypy, append([]string{"--output", "json"}, batch...), r.RootPath, r.Timeout)
+				if !strings.Contains(res.Stderr, "unrecognized arguments") {
+					out = append(out, ParseMypyJSON(res.Stdout)...)
+					continue
+				}
+				jsonOut = false
+			}
+			args := []string{"--show-column-numbers", "--show-error-codes", "--no-error-summary", "--no-pretty", "--no-color-output"}
+			res := r.Exec.Run(mypy, append(args, batch...), r.RootPath, r.Timeout)
+			out = append(out, ParseMypyText(res.Stdout)...)
+		}
+		*tool += " + mypy"
+	}
+
+	if pyright, ok := r.Exec.Which("pyright"); ok {
+		for _, batch := range batches {
+			res := r.Exec.Run(pyright, append([]string{"--outputjson"}, batch...), r.RootPath, r.Timeout)
+			out = append(out, ParsePyrightJSON(res.Stdout, r.RootPath)...)
+		}
+		*tool += " + pyright"
+	}
+	return out
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
 func (r Runner) runJava(warnings *[]string) contract.LanguageDiagnosticsV1 {
 	// Maven preferred, then Gradle/Gradle wrapper.
 	if fileExists(filepath.Join(r.RootPath, "pom.xml")) {
@@ -297,6 +593,10 @@ func (r Runner) runJava(warnings *[]string) contract.LanguageDiagnosticsV1 {
 				args = []string{"-q", "-DskipTests", "test-compile"}
 				tool = "mvn test-compile"
 			}
+			if r.Offline {
+				args = append([]string{"-o"}, args...)
+				r.forceOffline("mvn -o")
+			}
 			res := r.Exec.Run(mvn, args, r.RootPath, r.Timeout)
 			issues := ParseJava(res.Stdout, res.Stderr)
 			return contract.LanguageDiagnosticsV1{Name: "java", Tool: tool, Issues: issues}
@@ -305,8 +605,8 @@ func (r Runner) runJava(warnings *[]string) contract.LanguageDiagnosticsV1 {
 	}
 
 	gradlePath := ""
-	// Prefer local wrapper if present.
-	if fileExists(filepath.Join(r.RootPath, "gradlew")) {
+	// Prefer local wrapper if present (offline, only when its distribution is already cached).
+	if fileExists(filepath.Join(r.RootPath, "gradlew")) && (!r.Offline || r.gradleWrapperCached()) {
 		gradlePath = filepath.Join(r.RootPath, "gradlew")
 	}
 	if gradlePath == "" {
@@ -319,11 +619,20 @@ func (r Runner) runJava(warnings *[]string) contract.LanguageDiagnosticsV1 {
 		if r.IncludeTests {
 			task = "testClasses"
 		}
-		res := r.Exec.Run(gradlePath, []string{"-q", task}, r.RootPath, r.Timeout)
+		args := []string{"-q", task}
+		if r.Offline {
+			args = append(args, "--offline")
+			r.forceOffline("gradle --offline")
+		}
+		res := r.Exec.Run(gradlePath, args, r.RootPath, r.Timeout)
 		issues := ParseJava(res.Stdout, res.Stderr)
 		return contract.LanguageDiagnosticsV1{Name: "java", Tool: "gradle " + task, Issues: issues}
 	}
 
+	if r.Offline && fileExists(filepath.Join(r.RootPath, "gradlew")) {
+		r.skipOffline("gradlew", "the Gradle distribution is not downloaded yet", warnings)
+		return contract.LanguageDiagnosticsV1{Name: "java", Tool: "gradle", Issues: nil}
+	}
 	*warnings = append(*warnings, "no Maven/Gradle found; skipping Java diagnostics")
 	return contract.LanguageDiagnosticsV1{Name: "java", Tool: "javac/maven", Issues: nil}
 }
@@ -411,12 +720,5 @@ func fileExists(p string) bool {
 	return err == nil
 }
 
-func minDuration(a, b time.Duration) time.Duration {
-	if a <= b {
-		return a
-	}
-	return b
-}
-
 // Ensure we can parse "A..B" style args safely if needed later.
 var _ = regexp.MustCompile
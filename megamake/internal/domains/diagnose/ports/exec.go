@@ -12,5 +12,7 @@ type ExecResult struct {
 type Exec interface {
 	Which(name string) (string, bool)
 	Run(launchPath string, args []string, cwd string, timeout time.Duration) ExecResult
+	// RunEnv is Run with extra KEY=VALUE entries overriding the inherited environment.
+	RunEnv(launchPath string, args []string, cwd string, env []string, timeout time.Duration) ExecResult
 	DevNullPath() string
 }
@@ -44,8 +44,11 @@ type CreateRequest struct {
 	UMLIncludeIO        bool
 	UMLIncludeEndpoints bool
 
-	IgnoreNames []string
-	IgnoreGlobs []string
+	IgnoreNames  []string
+	IgnoreGlobs  []string
+	IncludeExts  []string
+	IncludeGlobs []string
+	ExcludeExts  []string
 
 	// Included for audit metadata parity with global flags (even if unused in local mode).
 	NetEnabled   bool
@@ -139,6 +142,9 @@ func (s *Service) Create(req CreateRequest) (CreateResult, error) {
 		MaxFileBytes: req.MaxFileBytes,
 		IgnoreNames:  req.IgnoreNames,
 		IgnoreGlobs:  req.IgnoreGlobs,
+		IncludeExts:  req.IncludeExts,
+		IncludeGlobs: req.IncludeGlobs,
+		ExcludeExts:  req.ExcludeExts,
 	})
 	if err != nil {
 		return CreateResult{}, err
@@ -153,13 +159,16 @@ func (s *Service) Create(req CreateRequest) (CreateResult, error) {
 	fileContents := map[string]string{}
 	var warnings []string
 	var sampleTexts []string
+	var hashes []contractartifact.FileHashV1
 
 	for _, rel := range relPaths {
-		b, err := s.Repo.ReadFileRel(req.RootPath, rel, req.MaxFileBytes)
+		tf, err := s.Repo.ReadTextFileRel(req.RootPath, rel, req.MaxFileBytes)
 		if err != nil {
 			warnings = append(warnings, "unable to read "+rel+": "+err.Error())
 			continue
 		}
+		hashes = append(hashes, contractartifact.FileHashV1{Path: rel, SHA256: tf.SHA256, Bytes: tf.Bytes})
+		b := tf.Text
 		if int64(len(b)) > req.MaxAnalyzeBytes {
 			b = b[:req.MaxAnalyzeBytes]
 		}
@@ -209,6 +218,7 @@ func (s *Service) Create(req CreateRequest) (CreateResult, error) {
 		Mode:                 contractdoc.DocModeLocal,
 		RootPath:             req.RootPath,
 		Languages:            sortedStrings(profile.Languages),
+		Packages:             docPackages(profile),
 		DirectoryTree:        dirTree,
 		ImportGraph:          asciiGraph,
 		Imports:              imps,
@@ -237,6 +247,10 @@ func (s *Service) Create(req CreateRequest) (CreateResult, error) {
 	}
 
 	env := contractartifact.ArtifactEnvelopeV1{Meta: meta, XML: xmlOut, JSON: jsonOut, Prompt: promptText}
+	env.Manifest = contractartifact.NewManifest(req.RootPath, relPaths)
+	for _, h := range hashes {
+		env.Manifest.SetHash(h.Path, h.SHA256, h.Bytes)
+	}
 
 	artifactPath, latestPath, err := s.ArtifactWriter.WriteToolArtifact(ports.WriteArtifactRequest{
 		ArtifactDir:    req.ArtifactDir,
@@ -381,6 +395,19 @@ func (s *Service) Get(req GetRequest) (GetResult, error) {
 
 func timePtr(t time.Time) *time.Time { return &t }
 
+func docPackages(p project.ProjectProfileV1) []contractdoc.DocPackageV1 {
+	var out []contractdoc.DocPackageV1
+	for _, m := range p.Members {
+		out = append(out, contractdoc.DocPackageV1{
+			Name:      m.Name,
+			RelPath:   m.RelPath,
+			Kind:      m.Kind,
+			Languages: sortedStrings(m.Profile.Languages),
+		})
+	}
+	return out
+}
+
 func buildSafetyStopMessage(p project.ProjectProfileV1) string {
 	var b strings.Builder
 	b.WriteString("Safety stop: This directory does not appear to be a code project.\n")
@@ -2,11 +2,11 @@ package domain
 
 import (
 	"path"
-	"regexp"
 	"sort"
 	"strings"
 
 	contractdoc "github.com/megamake/megamake/internal/contracts/v1/doc"
+	repodomain "github.com/megamake/megamake/internal/domains/repo/domain"
 )
 
 // importEdge is a stable, file-scope edge type for import graph rendering.
@@ -78,133 +78,18 @@ func BuildImportGraph(relPaths []string, fileContents map[string]string) (import
 	return imports, asciiGraph, externalCounts
 }
 
+// languageForRel and parseImports defer to the repo language-pack registry, so a language
+// added there is picked up by the import graph without changes here.
 func languageForRel(rel string) string {
-	ext := strings.ToLower(path.Ext(rel))
-	switch ext {
-	case ".ts", ".tsx":
-		return "typescript"
-	case ".js", ".jsx", ".mjs", ".cjs":
-		return "javascript"
-	case ".py":
-		return "python"
-	case ".go":
-		return "go"
-	case ".rs":
-		return "rust"
-	case ".swift":
-		return "swift"
-	case ".java":
-		return "java"
-	case ".kt", ".kts":
-		return "kotlin"
-	case ".lean":
-		return "lean"
-	default:
-		return ""
-	}
+	return repodomain.LanguageForRel(rel)
 }
 
 func parseImports(content string, lang string) []string {
-	var out []string
-	add := func(s string) {
-		s = strings.TrimSpace(s)
-		if s != "" {
-			out = append(out, s)
-		}
+	pack, ok := repodomain.LanguagePackByName(lang)
+	if !ok {
+		return nil
 	}
-
-	switch lang {
-	case "typescript", "javascript":
-		re1 := regexp.MustCompile(`(?m)^\s*import\s+(?:[^'"]*\s+from\s+)?['"]([^'"]+)['"]`)
-		re2 := regexp.MustCompile(`(?m)require\(\s*['"]([^'"]+)['"]\s*\)`)
-		re3 := regexp.MustCompile(`(?m)import\(\s*['"]([^'"]+)['"]\s*\)`)
-		for _, m := range re1.FindAllStringSubmatch(content, -1) {
-			if len(m) >= 2 {
-				add(m[1])
-			}
-		}
-		for _, m := range re2.FindAllStringSubmatch(content, -1) {
-			if len(m) >= 2 {
-				add(m[1])
-			}
-		}
-		for _, m := range re3.FindAllStringSubmatch(content, -1) {
-			if len(m) >= 2 {
-				add(m[1])
-			}
-		}
-
-	case "python":
-		re1 := regexp.MustCompile(`(?m)^\s*import\s+([A-Za-z0-9_\.]+)`)
-		re2 := regexp.MustCompile(`(?m)^\s*from\s+([A-Za-z0-9_\.]+)\s+import\s+`)
-		for _, m := range re1.FindAllStringSubmatch(content, -1) {
-			if len(m) >= 2 {
-				add(m[1])
-			}
-		}
-		for _, m := range re2.FindAllStringSubmatch(content, -1) {
-			if len(m) >= 2 {
-				add(m[1])
-			}
-		}
-
-	case "go":
-		reLine := regexp.MustCompile(`(?m)^\s*import\s+"([^"]+)"`)
-		for _, m := range reLine.FindAllStringSubmatch(content, -1) {
-			if len(m) >= 2 {
-				add(m[1])
-			}
-		}
-		reBlock := regexp.MustCompile(`(?s)import\s*\(\s*([^\)]+)\s*\)`)
-		blocks := reBlock.FindAllStringSubmatch(content, -1)
-		reQ := regexp.MustCompile(`(?m)"([^"]+)"`)
-		for _, b := range blocks {
-			if len(b) >= 2 {
-				for _, m := range reQ.FindAllStringSubmatch(b[1], -1) {
-					if len(m) >= 2 {
-						add(m[1])
-					}
-				}
-			}
-		}
-
-	case "rust":
-		re := regexp.MustCompile(`(?m)^\s*use\s+([A-Za-z0-9_:]+)`)
-		for _, m := range re.FindAllStringSubmatch(content, -1) {
-			if len(m) >= 2 {
-				add(m[1])
-			}
-		}
-
-	case "swift":
-		re := regexp.MustCompile(`(?m)^\s*import\s+([A-Za-z0-9_]+)`)
-		for _, m := range re.FindAllStringSubmatch(content, -1) {
-			if len(m) >= 2 {
-				add(m[1])
-			}
-		}
-
-	case "java", "kotlin":
-		re := regexp.MustCompile(`(?m)^\s*import\s+([A-Za-z0-9_\.]+)`)
-		for _, m := range re.FindAllStringSubmatch(content, -1) {
-			if len(m) >= 2 {
-				add(m[1])
-			}
-		}
-
-	case "lean":
-		re := regexp.MustCompile(`(?m)^\s*import\s+(.+)$`)
-		for _, m := range re.FindAllStringSubmatch(content, -1) {
-			if len(m) >= 2 {
-				parts := strings.Fields(m[1])
-				for _, p := range parts {
-					add(p)
-				}
-			}
-		}
-	}
-
-	return compact(out)
+	return compact(pack.ParseImports(content))
 }
 
 func compact(xs []string) []string {
@@ -310,10 +195,11 @@ func resolveRelative(fromRel string, raw string, exists map[string]bool) string
 		return cand
 	}
 
-	exts := []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".py", ".go", ".rs", ".swift", ".java", ".kt", ".kts", ".lean"}
-	for _, e := range exts {
-		if exists[cand+e] {
-			return cand + e
+	for _, pack := range repodomain.LanguagePacks() {
+		for _, e := range pack.Extensions {
+			if exists[cand+e] {
+				return cand + e
+			}
 		}
 	}
 
@@ -20,6 +20,14 @@ func GenerateDocPrompt(r contractdoc.DocReportV1) string {
 		lines = append(lines, "Languages: "+strings.Join(langs, ", "))
 	}
 
+	if len(r.Packages) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, "Workspace packages (document each one's role and how they depend on each other):")
+		for _, p := range r.Packages {
+			lines = append(lines, "  - "+p.Name+" ("+p.Kind+", "+p.RelPath+")")
+		}
+	}
+
 	if r.Mode == contractdoc.DocModeLocal {
 		lines = append(lines, "")
 		lines = append(lines, "Directory tree:")
@@ -0,0 +1,135 @@
+package adapters
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/megamake/megamake/internal/domains/patch/ports"
+	"github.com/megamake/megamake/internal/platform/errors"
+)
+
+type OSFileSystem struct{}
+
+func NewOSFileSystem() OSFileSystem {
+	return OSFileSystem{}
+}
+
+func (OSFileSystem) Stat(absPath string) (ports.FileState, error) {
+	linfo, err := os.Lstat(absPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ports.FileState{}, nil
+		}
+		return ports.FileState{}, errors.New(errors.KindIO, "failed to stat "+absPath, err)
+	}
+	symlink := linfo.Mode()&fs.ModeSymlink != 0
+	info, err := os.Stat(absPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Dangling symlink.
+			return ports.FileState{Symlink: symlink}, nil
+		}
+		return ports.FileState{}, errors.New(errors.KindIO, "failed to stat "+absPath, err)
+	}
+	return ports.FileState{Exists: true, IsDir: info.IsDir(), Mode: info.Mode().Perm(), Symlink: symlink}, nil
+}
+
+func (OSFileSystem) ReadFile(absPath string) ([]byte, error) {
+	b, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, errors.New(errors.KindIO, "failed to read "+absPath, err)
+	}
+	return b, nil
+}
+
+func (OSFileSystem) WriteFileAtomic(absPath string, data []byte, mode fs.FileMode) error {
+	if mode == 0 {
+		mode = 0o644
+	}
+	dir := filepath.Dir(absPath)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(absPath)+".megapatch-*")
+	if err != nil {
+		return errors.New(errors.KindIO, "failed to create temp file for "+absPath, err)
+	}
+	tmpPath := tmp.Name()
+	cleanup := func() { _ = os.Remove(tmpPath) }
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		cleanup()
+		return errors.New(errors.KindIO, "failed to write temp file for "+absPath, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		cleanup()
+		return errors.New(errors.KindIO, "failed to sync temp file for "+absPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return errors.New(errors.KindIO, "failed to close temp file for "+absPath, err)
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		cleanup()
+		return errors.New(errors.KindIO, "failed to set mode on temp file for "+absPath, err)
+	}
+	if err := os.Rename(tmpPath, absPath); err != nil {
+		cleanup()
+		return errors.New(errors.KindIO, "failed to replace "+absPath, err)
+	}
+	return nil
+}
+
+func (OSFileSystem) Remove(absPath string) error {
+	if err := os.Remove(absPath); err != nil && !os.IsNotExist(err) {
+		return errors.New(errors.KindIO, "failed to remove "+absPath, err)
+	}
+	return nil
+}
+
+func (OSFileSystem) MkdirAll(absDir string) ([]string, error) {
+	var missing []string
+	for d := absDir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if err := os.MkdirAll(absDir, 0o755); err != nil {
+		return nil, errors.New(errors.KindIO, "failed to create directory "+absDir, err)
+	}
+
+	// Outermost first.
+	created := make([]string, 0, len(missing))
+	for i := len(missing) - 1; i >= 0; i-- {
+		created = append(created, missing[i])
+	}
+	return created, nil
+}
+
+func (OSFileSystem) Resolve(absPath string) (string, error) {
+	var missing []string
+	for p := filepath.Clean(absPath); ; p = filepath.Dir(p) {
+		_, err := os.Lstat(p)
+		if err == nil {
+			real, err := filepath.EvalSymlinks(p)
+			if err != nil {
+				return "", errors.New(errors.KindIO, "failed to resolve "+p, err)
+			}
+			for i := len(missing) - 1; i >= 0; i-- {
+				real = filepath.Join(real, missing[i])
+			}
+			return real, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", errors.New(errors.KindIO, "failed to stat "+p, err)
+		}
+		if filepath.Dir(p) == p {
+			return filepath.Clean(absPath), nil
+		}
+		missing = append(missing, filepath.Base(p))
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	patchapp "github.com/megamake/megamake/internal/domains/patch/app"
+	patchports "github.com/megamake/megamake/internal/domains/patch/ports"
+	"github.com/megamake/megamake/internal/platform/clock"
+)
+
+type API interface {
+	Apply(req patchapp.ApplyRequest) (patchapp.ApplyResult, error)
+}
+
+type Dependencies struct {
+	Clock          clock.Clock
+	FS             patchports.FileSystem
+	ArtifactWriter patchports.ArtifactWriter
+}
+
+func New(deps Dependencies) API {
+	return &patchAPI{
+		svc: &patchapp.Service{
+			Clock:          deps.Clock,
+			FS:             deps.FS,
+			ArtifactWriter: deps.ArtifactWriter,
+		},
+	}
+}
+
+type patchAPI struct {
+	svc *patchapp.Service
+}
+
+func (p *patchAPI) Apply(req patchapp.ApplyRequest) (patchapp.ApplyResult, error) {
+	return p.svc.Apply(req)
+}
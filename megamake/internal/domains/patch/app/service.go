@@ -0,0 +1,444 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	contractartifact "github.com/megamake/megamake/internal/contracts/v1/artifact"
+	contract "github.com/megamake/megamake/internal/contracts/v1/patch"
+	"github.com/megamake/megamake/internal/domains/patch/domain"
+	"github.com/megamake/megamake/internal/domains/patch/ports"
+	"github.com/megamake/megamake/internal/platform/clock"
+	"github.com/megamake/megamake/internal/platform/policy"
+)
+
+type Service struct {
+	Clock          clock.Clock
+	FS             ports.FileSystem
+	ArtifactWriter ports.ArtifactWriter
+}
+
+type ApplyRequest struct {
+	RootPath    string
+	ArtifactDir string
+
+	// Script is the raw MegaPatch v1 text; Source describes where it came from (path or "stdin").
+	Script string
+	Source string
+
+	DryRun bool
+
+	// AllowDelete is the consent gate for "delete" statements.
+	AllowDelete bool
+
+	// Included for audit metadata parity with global flags.
+	NetEnabled   bool
+	AllowDomains []string
+	Args         []string
+}
+
+type ApplyResult struct {
+	Report      contract.PatchReportV1
+	ReportXML   string
+	ReportJSON  string
+	AgentPrompt string
+
+	ArtifactPath string
+	LatestPath   string
+}
+
+// plannedOp is an operation resolved against the (virtual) state of the tree.
+type plannedOp struct {
+	op     domain.Operation
+	abs    string
+	after  []byte
+	mode   fs.FileMode
+	report contract.PatchOpV1
+}
+
+// backup captures the pre-patch state of one path for rollback.
+type backup struct {
+	abs     string
+	existed bool
+	content []byte
+	mode    fs.FileMode
+}
+
+// virtualFile tracks a path's content as earlier operations in the same script modify it.
+type virtualFile struct {
+	exists  bool
+	content []byte
+	mode    fs.FileMode
+}
+
+// Apply parses, previews and (unless DryRun) applies a MegaPatch script.
+// Multi-file application is all-or-nothing: on any failure every touched path is restored.
+// Every run that gets past parsing is recorded as a MEGAPATCH artifact, including blocked
+// and rolled-back runs.
+func (s *Service) Apply(req ApplyRequest) (ApplyResult, error) {
+	if s.Clock == nil {
+		return ApplyResult{}, fmt.Errorf("internal error: Clock is nil")
+	}
+	if s.FS == nil {
+		return ApplyResult{}, fmt.Errorf("internal error: FS is nil")
+	}
+	if s.ArtifactWriter == nil {
+		return ApplyResult{}, fmt.Errorf("internal error: ArtifactWriter is nil")
+	}
+	if strings.TrimSpace(req.RootPath) == "" {
+		req.RootPath = "."
+	}
+	if strings.TrimSpace(req.ArtifactDir) == "" {
+		req.ArtifactDir = req.RootPath
+	}
+	if strings.TrimSpace(req.Source) == "" {
+		req.Source = "stdin"
+	}
+
+	now := s.Clock.NowUTC()
+
+	rootAbs, err := filepath.Abs(req.RootPath)
+	if err != nil {
+		return ApplyResult{}, err
+	}
+	rootState, err := s.FS.Stat(rootAbs)
+	if err != nil {
+		return ApplyResult{}, err
+	}
+	if !rootState.Exists || !rootState.IsDir {
+		return ApplyResult{}, fmt.Errorf("patch root is not a directory: %s", req.RootPath)
+	}
+
+	ops, err := domain.Parse(req.Script)
+	if err != nil {
+		return ApplyResult{}, fmt.Errorf("invalid MegaPatch script: %v", err)
+	}
+
+	planned, warnings, err := s.plan(rootAbs, ops)
+	if err != nil {
+		return ApplyResult{}, err
+	}
+
+	report := contract.PatchReportV1{
+		GeneratedAt: contractartifact.FormatRFC3339NanoUTC(now),
+		RootPath:    req.RootPath,
+		Source:      req.Source,
+		DryRun:      req.DryRun,
+		Warnings:    warnings,
+	}
+	for _, p := range planned {
+		report.Operations = append(report.Operations, p.report)
+	}
+	report.Summary = summarize(report.Operations)
+
+	pol := policy.Policy{
+		NetEnabled:   req.NetEnabled,
+		AllowDomains: cloneStrings(req.AllowDomains),
+		AllowDelete:  req.AllowDelete,
+	}
+	var blocked []string
+	for _, p := range planned {
+		if p.op.Kind != contract.PatchOpDelete {
+			continue
+		}
+		if err := pol.RequireDeleteAllowed(p.op.Path); err != nil {
+			blocked = append(blocked, err.Error())
+		}
+	}
+
+	switch {
+	case req.DryRun:
+		report.Status = contract.PatchStatusDryRun
+		report.Warnings = append(report.Warnings, blocked...)
+	case len(blocked) > 0:
+		report.Status = contract.PatchStatusBlocked
+		report.Error = strings.Join(blocked, "; ")
+	default:
+		if err := s.applyAll(planned); err != nil {
+			report.Status = contract.PatchStatusRolledBack
+			report.Error = err.Error()
+		} else {
+			report.Status = contract.PatchStatusApplied
+		}
+	}
+
+	reportXML := report.ToXML()
+	jb, _ := json.MarshalIndent(report, "", "  ")
+	reportJSON := string(jb)
+	agentPrompt := domain.GeneratePatchPrompt(report)
+
+	metaWarnings := append([]string(nil), report.Warnings...)
+	if report.Error != "" {
+		metaWarnings = append(metaWarnings, report.Error)
+	}
+
+	meta := contractartifact.ArtifactMetaV1{
+		Tool:         "megapatch",
+		Contract:     "v1",
+		GeneratedAt:  contractartifact.FormatRFC3339NanoUTC(now),
+		RootPath:     req.RootPath,
+		Args:         req.Args,
+		NetEnabled:   req.NetEnabled,
+		AllowDomains: cloneStrings(req.AllowDomains),
+		Warnings:     metaWarnings,
+	}
+
+	env := contractartifact.ArtifactEnvelopeV1{
+		Meta:   meta,
+		XML:    reportXML,
+		JSON:   reportJSON,
+		Prompt: agentPrompt,
+	}
+
+	artifactPath, latestPath, err := s.ArtifactWriter.WriteToolArtifact(ports.WriteArtifactRequest{
+		ArtifactDir:    req.ArtifactDir,
+		ToolPrefix:     "MEGAPATCH",
+		Envelope:       env,
+		GeneratedAtUTC: timePtr(now),
+	})
+	if err != nil {
+		return ApplyResult{}, err
+	}
+
+	return ApplyResult{
+		Report:       report,
+		ReportXML:    reportXML,
+		ReportJSON:   reportJSON,
+		AgentPrompt:  agentPrompt,
+		ArtifactPath: artifactPath,
+		LatestPath:   latestPath,
+	}, nil
+}
+
+// plan resolves each operation against the current tree (plus the effects of earlier
+// operations in the same script) and computes diff previews. It never writes.
+func (s *Service) plan(rootAbs string, ops []domain.Operation) ([]plannedOp, []string, error) {
+	virtual := map[string]*virtualFile{}
+	var warnings []string
+
+	// Script paths are validated lexically; symlinks inside the root could still point a
+	// write elsewhere, so every target's real location must stay under the real root.
+	rootReal, err := s.FS.Resolve(rootAbs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	load := func(rel string, abs string) (*virtualFile, error) {
+		if vf, ok := virtual[rel]; ok {
+			return vf, nil
+		}
+		st, err := s.FS.Stat(abs)
+		if err != nil {
+			return nil, err
+		}
+		if st.IsDir {
+			return nil, fmt.Errorf("%s is a directory; MegaPatch only operates on files", rel)
+		}
+		if st.Symlink {
+			return nil, fmt.Errorf("%s is a symlink; MegaPatch does not write or delete through symlinks", rel)
+		}
+		vf := &virtualFile{exists: st.Exists, mode: st.Mode}
+		if st.Exists {
+			b, err := s.FS.ReadFile(abs)
+			if err != nil {
+				return nil, err
+			}
+			vf.content = b
+		}
+		virtual[rel] = vf
+		return vf, nil
+	}
+
+	var out []plannedOp
+	for _, op := range ops {
+		abs := filepath.Join(rootAbs, filepath.FromSlash(op.Path))
+		dirReal, err := s.FS.Resolve(filepath.Dir(abs))
+		if err != nil {
+			return nil, nil, err
+		}
+		if !withinRoot(rootReal, dirReal) {
+			return nil, nil, fmt.Errorf("%s (line %d) resolves outside the patch root through a symlink: %s", op.Path, op.Line, dirReal)
+		}
+		vf, err := load(op.Path, abs)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		before := vf.content
+		beforeExists := vf.exists
+		p := plannedOp{op: op, abs: abs, mode: vf.mode}
+		p.report = contract.PatchOpV1{
+			Op:          op.Kind,
+			Path:        op.Path,
+			Line:        op.Line,
+			BytesBefore: int64(len(before)),
+		}
+
+		switch op.Kind {
+		case contract.PatchOpWrite:
+			p.after = []byte(op.Content)
+			p.report.BytesAfter = int64(len(p.after))
+			switch {
+			case !beforeExists:
+				p.report.Action = "create"
+			case string(before) == op.Content:
+				p.report.Action = "unchanged"
+			default:
+				p.report.Action = "modify"
+			}
+			p.report.Diff, p.report.LinesAdded, p.report.LinesRemoved = domain.UnifiedDiff(op.Path, string(before), op.Content, beforeExists, true)
+			vf.exists = true
+			vf.content = p.after
+
+		case contract.PatchOpDelete:
+			if !beforeExists {
+				p.report.Action = "missing"
+				warnings = append(warnings, "delete of missing file is a no-op: "+op.Path)
+			} else {
+				p.report.Action = "delete"
+				p.report.Diff, p.report.LinesAdded, p.report.LinesRemoved = domain.UnifiedDiff(op.Path, string(before), "", true, false)
+			}
+			vf.exists = false
+			vf.content = nil
+		}
+
+		out = append(out, p)
+	}
+	return out, warnings, nil
+}
+
+// applyAll applies planned operations in order and rolls everything back on the first failure.
+func (s *Service) applyAll(planned []plannedOp) error {
+	var backups []backup
+	backedUp := map[string]bool{}
+	var createdDirs []string
+
+	for _, p := range planned {
+		if backedUp[p.abs] {
+			continue
+		}
+		backedUp[p.abs] = true
+		st, err := s.FS.Stat(p.abs)
+		if err != nil {
+			return err
+		}
+		// The tree may have changed since plan(); never follow a symlink that appeared since.
+		if st.Symlink {
+			return fmt.Errorf("%s (line %d) is a symlink; MegaPatch does not write or delete through symlinks", p.op.Path, p.op.Line)
+		}
+		b := backup{abs: p.abs, existed: st.Exists, mode: st.Mode}
+		if st.Exists {
+			content, err := s.FS.ReadFile(p.abs)
+			if err != nil {
+				return err
+			}
+			b.content = content
+		}
+		backups = append(backups, b)
+	}
+
+	var applyErr error
+	for _, p := range planned {
+		switch p.op.Kind {
+		case contract.PatchOpWrite:
+			if p.report.Action == "unchanged" {
+				continue
+			}
+			dirs, err := s.FS.MkdirAll(filepath.Dir(p.abs))
+			createdDirs = append(createdDirs, dirs...)
+			if err != nil {
+				applyErr = err
+				break
+			}
+			if err := s.FS.WriteFileAtomic(p.abs, p.after, p.mode); err != nil {
+				applyErr = err
+			}
+		case contract.PatchOpDelete:
+			if p.report.Action == "missing" {
+				continue
+			}
+			if err := s.FS.Remove(p.abs); err != nil {
+				applyErr = err
+			}
+		}
+		if applyErr != nil {
+			applyErr = fmt.Errorf("%s (line %d): %v", p.op.Path, p.op.Line, applyErr)
+			break
+		}
+	}
+	if applyErr == nil {
+		return nil
+	}
+
+	var rollbackErrs []string
+	for i := len(backups) - 1; i >= 0; i-- {
+		b := backups[i]
+		var err error
+		if b.existed {
+			err = s.FS.WriteFileAtomic(b.abs, b.content, b.mode)
+		} else {
+			err = s.FS.Remove(b.abs)
+		}
+		if err != nil {
+			rollbackErrs = append(rollbackErrs, err.Error())
+		}
+	}
+	// Innermost first; only directories this run created (they are empty again after restore).
+	for i := len(createdDirs) - 1; i >= 0; i-- {
+		_ = s.FS.Remove(createdDirs[i])
+	}
+
+	if len(rollbackErrs) > 0 {
+		return fmt.Errorf("apply failed: %v; rollback incomplete: %s", applyErr, strings.Join(rollbackErrs, "; "))
+	}
+	return fmt.Errorf("apply failed and was rolled back: %v", applyErr)
+}
+
+func summarize(ops []contract.PatchOpV1) contract.PatchSummaryV1 {
+	s := contract.PatchSummaryV1{Operations: len(ops)}
+	for _, op := range ops {
+		switch op.Action {
+		case "create":
+			s.Created++
+		case "modify":
+			s.Modified++
+		case "delete":
+			s.Deleted++
+		case "unchanged", "missing":
+			s.Unchanged++
+		}
+		s.LinesAdded += op.LinesAdded
+		s.LinesRemoved += op.LinesRemoved
+	}
+	return s
+}
+
+// withinRoot reports whether path is root or lies beneath it.
+func withinRoot(root string, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
+
+func cloneStrings(xs []string) []string {
+	if len(xs) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(xs))
+	for _, x := range xs {
+		x = strings.TrimSpace(x)
+		if x == "" {
+			continue
+		}
+		out = append(out, x)
+	}
+	return out
+}
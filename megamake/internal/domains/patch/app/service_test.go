@@ -0,0 +1,107 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/megamake/megamake/internal/domains/patch/adapters"
+	"github.com/megamake/megamake/internal/domains/patch/ports"
+	"github.com/megamake/megamake/internal/platform/clock"
+)
+
+type discardArtifacts struct{}
+
+func (discardArtifacts) WriteToolArtifact(req ports.WriteArtifactRequest) (string, string, error) {
+	return filepath.Join(req.ArtifactDir, req.ToolPrefix+"_test.txt"), "", nil
+}
+
+func newTestService() *Service {
+	return &Service{Clock: clock.SystemUTC{}, FS: adapters.NewOSFileSystem(), ArtifactWriter: discardArtifacts{}}
+}
+
+// symlinkOrSkip creates link -> target, skipping where symlinks are unavailable (Windows
+// without developer mode).
+func symlinkOrSkip(t *testing.T, target string, link string) {
+	t.Helper()
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+}
+
+func TestApplyRejectsWriteThroughSymlinkedDir(t *testing.T) {
+	tmp := t.TempDir()
+	root := filepath.Join(tmp, "proj")
+	outside := filepath.Join(tmp, "outside")
+	for _, d := range []string{root, outside} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	symlinkOrSkip(t, outside, filepath.Join(root, "link"))
+
+	for _, script := range []string{
+		"write link/pwned.txt <<'EOF'\nowned\nEOF\n",
+		"write link/sub/pwned.txt <<'EOF'\nowned\nEOF\n",
+	} {
+		_, err := newTestService().Apply(ApplyRequest{RootPath: root, Script: script})
+		if err == nil || !strings.Contains(err.Error(), "outside the patch root") {
+			t.Fatalf("script %q: want outside-root error, got %v", script, err)
+		}
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Fatalf("patch wrote outside the root: %v", entries)
+	}
+}
+
+func TestApplyRejectsFinalComponentSymlink(t *testing.T) {
+	tmp := t.TempDir()
+	root := filepath.Join(tmp, "proj")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	victim := filepath.Join(tmp, "victim.txt")
+	if err := os.WriteFile(victim, []byte("keep\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	symlinkOrSkip(t, victim, filepath.Join(root, "file.txt"))
+
+	for _, tc := range []struct {
+		script      string
+		allowDelete bool
+	}{
+		{"write file.txt <<'EOF'\nowned\nEOF\n", false},
+		{"delete file.txt\n", true},
+	} {
+		_, err := newTestService().Apply(ApplyRequest{RootPath: root, Script: tc.script, AllowDelete: tc.allowDelete})
+		if err == nil || !strings.Contains(err.Error(), "symlink") {
+			t.Fatalf("script %q: want symlink error, got %v", tc.script, err)
+		}
+	}
+	if b, err := os.ReadFile(victim); err != nil || string(b) != "keep\n" {
+		t.Fatalf("symlink target changed: %q, %v", b, err)
+	}
+	if _, err := os.Lstat(filepath.Join(root, "file.txt")); err != nil {
+		t.Fatalf("symlink removed: %v", err)
+	}
+}
+
+func TestApplyAllowsSymlinkInsideRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "real"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	symlinkOrSkip(t, filepath.Join(root, "real"), filepath.Join(root, "alias"))
+
+	res, err := newTestService().Apply(ApplyRequest{RootPath: root, Script: "write alias/ok.txt <<'EOF'\nfine\nEOF\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Report.Status != "applied" {
+		t.Fatalf("status = %s (%s)", res.Report.Status, res.Report.Error)
+	}
+	if b, err := os.ReadFile(filepath.Join(root, "real", "ok.txt")); err != nil || string(b) != "fine\n" {
+		t.Fatalf("content = %q, %v", b, err)
+	}
+}
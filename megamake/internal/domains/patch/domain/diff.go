@@ -0,0 +1,285 @@
+package domain
+
+import (
+	"strings"
+)
+
+const diffContextLines = 3
+
+type editKind byte
+
+const (
+	editEqual  editKind = ' '
+	editDelete editKind = '-'
+	editInsert editKind = '+'
+)
+
+type edit struct {
+	kind editKind
+	a    int // index into old lines (equal/delete)
+	b    int // index into new lines (equal/insert)
+}
+
+// UnifiedDiff renders a unified diff (3 lines of context) between before and after.
+// beforeExists/afterExists select /dev/null headers for creates and deletes.
+// It returns an empty string when the contents are identical.
+func UnifiedDiff(relPath string, before string, after string, beforeExists bool, afterExists bool) (diff string, added int, removed int) {
+	if beforeExists == afterExists && before == after {
+		return "", 0, 0
+	}
+
+	a := splitLines(before)
+	b := splitLines(after)
+	edits := myersDiff(a, b)
+
+	for _, e := range edits {
+		switch e.kind {
+		case editInsert:
+			added++
+		case editDelete:
+			removed++
+		}
+	}
+
+	oldName := "a/" + relPath
+	newName := "b/" + relPath
+	if !beforeExists {
+		oldName = "/dev/null"
+	}
+	if !afterExists {
+		newName = "/dev/null"
+	}
+
+	var out strings.Builder
+	out.WriteString("--- " + oldName + "\n")
+	out.WriteString("+++ " + newName + "\n")
+
+	for _, h := range groupHunks(edits) {
+		writeHunk(&out, edits[h[0]:h[1]], a, b)
+	}
+	return out.String(), added, removed
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if strings.HasSuffix(s, "\n") {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// myersDiff computes a shortest edit script between a and b (Myers, O((N+M)D)).
+func myersDiff(a []string, b []string) []edit {
+	n := len(a)
+	m := len(b)
+	maxD := n + m
+	if maxD == 0 {
+		return nil
+	}
+
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		// Only k in [-d-1, d+1] is read when backtracking step d; keep just that window.
+		snapshot := make([]int, 2*d+3)
+		copy(snapshot, v[offset-d-1:offset+d+2])
+		trace = append(trace, snapshot)
+
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+				break
+			}
+		}
+		if done {
+			break
+		}
+	}
+
+	// Backtrack through the recorded V arrays.
+	var rev []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		vd := trace[d]
+		at := func(k int) int { return vd[k+d+1] }
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, edit{kind: editEqual, a: x, b: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				rev = append(rev, edit{kind: editInsert, a: x, b: y})
+			} else {
+				x--
+				rev = append(rev, edit{kind: editDelete, a: x, b: y})
+			}
+		}
+	}
+
+	out := make([]edit, 0, len(rev))
+	for i := len(rev) - 1; i >= 0; i-- {
+		out = append(out, rev[i])
+	}
+	return out
+}
+
+// groupHunks returns [start,end) index ranges into edits, each covering a change
+// plus up to diffContextLines of surrounding equal lines.
+func groupHunks(edits []edit) [][2]int {
+	var hunks [][2]int
+	i := 0
+	for i < len(edits) {
+		for i < len(edits) && edits[i].kind == editEqual {
+			i++
+		}
+		if i >= len(edits) {
+			break
+		}
+
+		start := i - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+
+		end := i
+		equalRun := 0
+		for end < len(edits) {
+			if edits[end].kind == editEqual {
+				equalRun++
+				if equalRun > 2*diffContextLines {
+					break
+				}
+			} else {
+				equalRun = 0
+			}
+			end++
+		}
+		// Trim trailing context beyond diffContextLines.
+		trailing := 0
+		for j := end - 1; j >= 0 && edits[j].kind == editEqual; j-- {
+			trailing++
+		}
+		if trailing > diffContextLines {
+			end -= trailing - diffContextLines
+		}
+
+		if len(hunks) > 0 && start < hunks[len(hunks)-1][1] {
+			start = hunks[len(hunks)-1][1]
+		}
+		hunks = append(hunks, [2]int{start, end})
+		i = end
+	}
+	return hunks
+}
+
+func writeHunk(out *strings.Builder, hunk []edit, a []string, b []string) {
+	if len(hunk) == 0 {
+		return
+	}
+
+	aStart, bStart := -1, -1
+	aCount, bCount := 0, 0
+	for _, e := range hunk {
+		switch e.kind {
+		case editEqual:
+			if aStart < 0 {
+				aStart = e.a
+			}
+			if bStart < 0 {
+				bStart = e.b
+			}
+			aCount++
+			bCount++
+		case editDelete:
+			if aStart < 0 {
+				aStart = e.a
+			}
+			aCount++
+		case editInsert:
+			if bStart < 0 {
+				bStart = e.b
+			}
+			bCount++
+		}
+	}
+
+	// Unified diff convention: empty ranges start at the line before the hunk.
+	if aStart < 0 {
+		aStart = hunk[0].a - 1
+	}
+	if bStart < 0 {
+		bStart = hunk[0].b - 1
+	}
+
+	out.WriteString("@@ -" + hunkRange(aStart, aCount) + " +" + hunkRange(bStart, bCount) + " @@\n")
+	for _, e := range hunk {
+		switch e.kind {
+		case editEqual:
+			out.WriteString(" " + a[e.a] + "\n")
+		case editDelete:
+			out.WriteString("-" + a[e.a] + "\n")
+		case editInsert:
+			out.WriteString("+" + b[e.b] + "\n")
+		}
+	}
+}
+
+func hunkRange(start0 int, count int) string {
+	if count == 0 {
+		return itoa(start0+1) + ",0"
+	}
+	if count == 1 {
+		return itoa(start0 + 1)
+	}
+	return itoa(start0+1) + "," + itoa(count)
+}
+
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	var buf [32]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = byte('0' + (n % 10))
+		n /= 10
+	}
+	return sign + string(buf[i:])
+}
@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	contract "github.com/megamake/megamake/internal/contracts/v1/patch"
+)
+
+// Operation is a single parsed MegaPatch statement.
+type Operation struct {
+	Kind    contract.PatchOpKindV1
+	Path    string // validated POSIX relpath
+	Content string // full new content (write only)
+	Line    int    // 1-based statement line in the script
+}
+
+var (
+	headerRe = regexp.MustCompile(`(?i)^#?\s*(?:!.*\s)?megapatch\s+v(\d+)\s*$`)
+
+	// write path <<'EOF'   |   cat > path <<'EOF'
+	writeRe = regexp.MustCompile(`^(?:write|cat\s*>)\s*("[^"]+"|'[^']+'|\S+)\s*<<-?\s*(['"]?)([A-Za-z_][A-Za-z0-9_]*)(['"]?)\s*$`)
+	// cat <<'EOF' > path
+	catRevRe = regexp.MustCompile(`^cat\s+<<-?\s*(['"]?)([A-Za-z_][A-Za-z0-9_]*)(['"]?)\s*>\s*("[^"]+"|'[^']+'|\S+)\s*$`)
+
+	deleteRe = regexp.MustCompile(`^(?:delete|rm(?:\s+-f)?)\s+("[^"]+"|'[^']+'|\S+)\s*$`)
+	rmDirRe  = regexp.MustCompile(`^(?:rm\s+-[a-zA-Z]*[rR][a-zA-Z]*|rmdir)\b`)
+
+	// Shell preamble that models commonly emit around heredoc scripts; safe to ignore.
+	ignorableRe = regexp.MustCompile(`^(?:#!|set\s+-[a-z]+|mkdir\s+-p\s)`)
+)
+
+// Parse parses a MegaPatch v1 script into operations.
+//
+// Accepted statements (one per line; blank lines and # comments are ignored):
+//
+//	write <path> <<'EOF'   (also: cat > <path> <<'EOF', cat <<'EOF' > <path>)
+//	...content...
+//	EOF
+//	delete <path>          (also: rm <path>, rm -f <path>)
+//
+// Markdown code fences around the script are tolerated because models often add them.
+// Directory deletes (rm -r, rmdir) are rejected outright.
+func Parse(script string) ([]Operation, error) {
+	script = strings.ReplaceAll(script, "\r\n", "\n")
+	lines := strings.Split(script, "\n")
+
+	var ops []Operation
+	sawHeader := false
+
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+
+		if line == "" || strings.HasPrefix(line, "```") {
+			continue
+		}
+		if m := headerRe.FindStringSubmatch(line); len(m) >= 2 {
+			if sawHeader || len(ops) > 0 {
+				return nil, fmt.Errorf("line %d: MegaPatch header must appear once, before any statement", lineNo)
+			}
+			if m[1] != "1" {
+				return nil, fmt.Errorf("line %d: unsupported MegaPatch version v%s (expected v1)", lineNo, m[1])
+			}
+			sawHeader = true
+			continue
+		}
+		if strings.HasPrefix(line, "#") || ignorableRe.MatchString(line) {
+			continue
+		}
+
+		rawPath, delim, quoteOpen, quoteClose := "", "", "", ""
+		if m := writeRe.FindStringSubmatch(line); len(m) >= 5 {
+			rawPath, quoteOpen, delim, quoteClose = m[1], m[2], m[3], m[4]
+		} else if m := catRevRe.FindStringSubmatch(line); len(m) >= 5 {
+			quoteOpen, delim, quoteClose, rawPath = m[1], m[2], m[3], m[4]
+		}
+		if delim != "" {
+			if quoteOpen != quoteClose {
+				return nil, fmt.Errorf("line %d: mismatched quotes around heredoc delimiter", lineNo)
+			}
+			rel, err := ValidateRelPath(unquote(rawPath))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+
+			var body []string
+			closed := false
+			for j := i + 1; j < len(lines); j++ {
+				if strings.TrimRight(lines[j], " \t") == delim {
+					i = j
+					closed = true
+					break
+				}
+				body = append(body, lines[j])
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: heredoc for %s is missing its closing %s line", lineNo, rel, delim)
+			}
+
+			content := ""
+			if len(body) > 0 {
+				content = strings.Join(body, "\n") + "\n"
+			}
+			ops = append(ops, Operation{Kind: contract.PatchOpWrite, Path: rel, Content: content, Line: lineNo})
+			continue
+		}
+
+		if rmDirRe.MatchString(line) {
+			return nil, fmt.Errorf("line %d: directory deletes are not allowed in MegaPatch", lineNo)
+		}
+		if m := deleteRe.FindStringSubmatch(line); len(m) >= 2 {
+			rel, err := ValidateRelPath(unquote(m[1]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			ops = append(ops, Operation{Kind: contract.PatchOpDelete, Path: rel, Line: lineNo})
+			continue
+		}
+
+		return nil, fmt.Errorf("line %d: unrecognized MegaPatch statement: %s", lineNo, truncate(line, 80))
+	}
+
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("MegaPatch script contains no operations")
+	}
+	return ops, nil
+}
+
+// ValidateRelPath normalizes a script path and rejects anything that could escape the root.
+func ValidateRelPath(p string) (string, error) {
+	raw := strings.TrimSpace(p)
+	if raw == "" {
+		return "", fmt.Errorf("empty path")
+	}
+	x := strings.ReplaceAll(raw, "\\", "/")
+	if strings.HasPrefix(x, "/") || (len(x) >= 2 && x[1] == ':') {
+		return "", fmt.Errorf("absolute paths are not allowed: %s", raw)
+	}
+	for _, seg := range strings.Split(x, "/") {
+		if seg == ".." {
+			return "", fmt.Errorf("path escapes project root: %s", raw)
+		}
+	}
+	x = path.Clean(x)
+	if x == "." || x == "" {
+		return "", fmt.Errorf("path refers to the project root: %s", raw)
+	}
+	if x == ".git" || strings.HasPrefix(x, ".git/") {
+		return "", fmt.Errorf("refusing to modify git metadata: %s", raw)
+	}
+	return x, nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 {
+		if (s[0] == '"' && s[len(s)-1] == '"') || (s[0] == '\'' && s[len(s)-1] == '\'') {
+			return s[1 : len(s)-1]
+		}
+	}
+	return s
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
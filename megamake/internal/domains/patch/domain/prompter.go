@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"strings"
+
+	contract "github.com/megamake/megamake/internal/contracts/v1/patch"
+)
+
+// GeneratePatchPrompt produces agent-facing follow-up text describing what a MegaPatch did.
+func GeneratePatchPrompt(r contract.PatchReportV1) string {
+	var lines []string
+	lines = append(lines, "You are an expert software engineer reviewing a MegaPatch v1 application.")
+	lines = append(lines, "")
+	lines = append(lines, "Status: "+string(r.Status))
+	if r.DryRun {
+		lines = append(lines, "Mode: dry-run (no files were changed)")
+	}
+	s := r.Summary
+	lines = append(lines, "Operations: "+itoa(s.Operations)+" (created "+itoa(s.Created)+", modified "+itoa(s.Modified)+", deleted "+itoa(s.Deleted)+", unchanged "+itoa(s.Unchanged)+")")
+	lines = append(lines, "Lines: +"+itoa(s.LinesAdded)+" -"+itoa(s.LinesRemoved))
+	if strings.TrimSpace(r.Error) != "" {
+		lines = append(lines, "Error: "+r.Error)
+	}
+	lines = append(lines, "")
+	lines = append(lines, "Files:")
+	for _, op := range r.Operations {
+		lines = append(lines, "- ["+op.Action+"] "+op.Path+" (+"+itoa(op.LinesAdded)+" -"+itoa(op.LinesRemoved)+")")
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, "Instructions:")
+	switch r.Status {
+	case contract.PatchStatusBlocked:
+		lines = append(lines, "- The patch was blocked by policy. Either drop the file deletes or ask the user to re-run with --allow-delete.")
+	case contract.PatchStatusRolledBack:
+		lines = append(lines, "- Applying the patch failed and every file was restored. Fix the cause above and emit a corrected MegaPatch.")
+	default:
+		lines = append(lines, "- Review the diffs in the XML section for correctness and unintended changes.")
+		lines = append(lines, "- If follow-up changes are needed, return a single new MegaPatch script (no prose).")
+	}
+	lines = append(lines, "")
+	lines = append(lines, contract.SyntaxV1)
+	return strings.Join(lines, "\n")
+}
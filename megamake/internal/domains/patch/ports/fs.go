@@ -0,0 +1,33 @@
+package ports
+
+import "io/fs"
+
+// FileState describes a path before a patch touches it.
+type FileState struct {
+	Exists bool
+	IsDir  bool
+	Mode   fs.FileMode
+
+	// Symlink reports that the path itself is a symbolic link; the other fields describe its target.
+	Symlink bool
+}
+
+// FileSystem is the minimal filesystem surface needed to apply a patch transactionally.
+// All paths are absolute OS paths.
+type FileSystem interface {
+	Stat(absPath string) (FileState, error)
+	ReadFile(absPath string) ([]byte, error)
+
+	// WriteFileAtomic writes via a temp file in the same directory followed by a rename,
+	// so readers never observe a half-written file.
+	WriteFileAtomic(absPath string, data []byte, mode fs.FileMode) error
+	Remove(absPath string) error
+
+	// MkdirAll creates absDir and returns the directories it actually created (outermost first),
+	// so a rollback can remove them again.
+	MkdirAll(absDir string) (created []string, err error)
+
+	// Resolve evaluates the symlinks in the deepest existing ancestor of absPath and appends
+	// the components that do not exist yet, giving the real location a write would land in.
+	Resolve(absPath string) (string, error)
+}
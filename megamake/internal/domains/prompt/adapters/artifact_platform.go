@@ -23,3 +23,22 @@ func (p PlatformArtifactWriter) WriteToolArtifact(req ports.WriteArtifactRequest
 		GeneratedAtUTC: req.GeneratedAtUTC,
 	})
 }
+
+func (p PlatformArtifactWriter) StreamToolArtifact(req ports.StreamArtifactRequest) (string, string, error) {
+	return p.Writer.StreamToolArtifact(artifactwriter.StreamRequest{
+		ArtifactDir:    req.ArtifactDir,
+		ToolPrefix:     req.ToolPrefix,
+		Envelope:       req.Envelope,
+		GeneratedAtUTC: req.GeneratedAtUTC,
+		XML:            req.XML,
+	})
+}
+
+func (p PlatformArtifactWriter) WriteArtifactParts(req ports.WriteArtifactPartsRequest) ([]string, error) {
+	return p.Writer.WriteParts(artifactwriter.WritePartsRequest{
+		ArtifactDir:    req.ArtifactDir,
+		ToolPrefix:     req.ToolPrefix,
+		Parts:          req.Parts,
+		GeneratedAtUTC: req.GeneratedAtUTC,
+	})
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	chatports "github.com/megamake/megamake/internal/domains/chat/ports"
 	promptapp "github.com/megamake/megamake/internal/domains/prompt/app"
 	promptports "github.com/megamake/megamake/internal/domains/prompt/ports"
 	repoapi "github.com/megamake/megamake/internal/domains/repo/api"
@@ -16,6 +17,8 @@ type Dependencies struct {
 	Repo           repoapi.API
 	ArtifactWriter promptports.ArtifactWriter
 	Clipboard      promptports.Clipboard
+	Git            promptports.Git
+	TokenCounter   chatports.TokenCounter
 }
 
 func New(deps Dependencies) API {
@@ -25,6 +28,8 @@ func New(deps Dependencies) API {
 			Repo:           deps.Repo,
 			ArtifactWriter: deps.ArtifactWriter,
 			Clipboard:      deps.Clipboard,
+			Git:            deps.Git,
+			TokenCounter:   deps.TokenCounter,
 		},
 	}
 }
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"runtime"
+	"sync"
+
+	project "github.com/megamake/megamake/internal/contracts/v1/project"
+	promptdomain "github.com/megamake/megamake/internal/domains/prompt/domain"
+)
+
+// fileRead is one file as read, transcoded and redacted by the read pool.
+type fileRead struct {
+	Input    promptdomain.FileInput
+	Encoding string   // source encoding when transcoded ("" for plain UTF-8)
+	Warnings []string // in the order the serial loop used to add them
+	OK       bool     // false if the file could not be read
+
+	// SHA256 and Bytes describe the bytes read from disk, for the artifact manifest.
+	SHA256 string
+	Bytes  int64
+}
+
+// defaultReadWorkers bounds concurrent file reads when GenerateRequest.Workers is 0.
+func defaultReadWorkers() int {
+	n := runtime.GOMAXPROCS(0)
+	if n < 4 {
+		n = 4
+	}
+	if n > 16 {
+		n = 16
+	}
+	return n
+}
+
+// readFiles reads files with a bounded pool of workers and hands each result to emit in
+// file order, on the calling goroutine. At most 2*workers results are in flight, so memory
+// is bounded by the largest files being read rather than by the size of the repository.
+func (s *Service) readFiles(req GenerateRequest, files []project.FileRefV1, emit func(i int, r fileRead)) {
+	workers := req.Workers
+	if workers <= 0 {
+		workers = defaultReadWorkers()
+	}
+	window := 2 * workers
+
+	// Result i goes to slot i%window. Index i is only dispatched once i-window has been
+	// emitted, so a worker never finds its slot occupied.
+	slots := make([]chan fileRead, window)
+	for k := range slots {
+		slots[k] = make(chan fileRead, 1)
+	}
+	jobs := make(chan int, window)
+
+	var wg sync.WaitGroup
+	for n := 0; n < workers; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				slots[i%window] <- s.readFile(req, files[i])
+			}
+		}()
+	}
+
+	next := 0
+	for i := range files {
+		for next < len(files) && next < i+window {
+			jobs <- next
+			next++
+		}
+		emit(i, <-slots[i%window])
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+func (s *Service) readFile(req GenerateRequest, f project.FileRefV1) fileRead {
+	tf, err := s.Repo.ReadTextFileRel(req.RootPath, f.RelPath, req.MaxFileBytes)
+	if err != nil {
+		return fileRead{Warnings: []string{"unable to read " + f.RelPath + ": " + err.Error()}}
+	}
+	b, enc := tf.Text, tf.Encoding
+	r := fileRead{Encoding: enc, OK: true, SHA256: tf.SHA256, Bytes: tf.Bytes}
+	if enc != "" {
+		r.Warnings = append(r.Warnings, "transcoded "+f.RelPath+" from "+enc+" to UTF-8")
+	}
+	if !req.NoRedact {
+		var n int
+		b, n = promptdomain.RedactSecrets(b)
+		if n > 0 {
+			r.Warnings = append(r.Warnings, "redacted "+itoa(n)+" secret(s) in "+f.RelPath)
+		}
+	}
+	r.Input = promptdomain.FileInput{RelPath: f.RelPath, Content: b}
+	return r
+}
@@ -3,12 +3,17 @@ package app
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	contractartifact "github.com/megamake/megamake/internal/contracts/v1/artifact"
+	contractpatch "github.com/megamake/megamake/internal/contracts/v1/patch"
 	project "github.com/megamake/megamake/internal/contracts/v1/project"
 	contractprompt "github.com/megamake/megamake/internal/contracts/v1/prompt"
+	chatports "github.com/megamake/megamake/internal/domains/chat/ports"
 	promptdomain "github.com/megamake/megamake/internal/domains/prompt/domain"
 	"github.com/megamake/megamake/internal/domains/prompt/ports"
 	repoapi "github.com/megamake/megamake/internal/domains/repo/api"
@@ -20,6 +25,12 @@ type Service struct {
 	Repo           repoapi.API
 	ArtifactWriter ports.ArtifactWriter
 	Clipboard      ports.Clipboard
+
+	// Git is used by --since/--range (optional otherwise).
+	Git ports.Git
+
+	// TokenCounter estimates tokens for --max-tokens packing (optional otherwise).
+	TokenCounter chatports.TokenCounter
 }
 
 type GenerateRequest struct {
@@ -29,12 +40,62 @@ type GenerateRequest struct {
 	MaxFileBytes int64
 	IgnoreNames  []string
 	IgnoreGlobs  []string
+	IncludeExts  []string
+	IncludeGlobs []string
+	ExcludeExts  []string
+
+	// ExplainIgnores lists the paths .gitignore/.megamakeignore rules excluded in the report.
+	ExplainIgnores bool
+
+	// Focus, if set, limits the context to a file, directory or symbol definition plus its
+	// import neighborhood up to FocusDepth edges (and importers too with FocusDependents).
+	Focus           string
+	FocusDepth      int
+	FocusDependents bool
+
+	// SinceRef (ref..HEAD) or Range (A..B) limit the context to changed files plus their
+	// direct import neighbors, and add a <diff> section with the unified hunks.
+	SinceRef string
+	Range    string
+
+	// Format selects the context blob format (pseudo-xml when empty).
+	Format promptdomain.ContextFormat
+
+	// Skeleton replaces function bodies with an elision marker, keeping declarations and
+	// signatures; files matching a SkeletonExcept glob keep their full bodies.
+	Skeleton       bool
+	SkeletonExcept []string
+
+	// NoRedact disables replacing detected secrets (keys, tokens, private key material)
+	// with placeholders before the context is built.
+	NoRedact bool
+
+	// MaxTokens, if > 0, packs the highest-ranked files into this token budget
+	// (manifests, entrypoints, READMEs, then by import centrality) and drops the rest.
+	MaxTokens int
+
+	// SplitBytes or SplitTokens, if > 0, write the context as numbered part files
+	// (MEGAPROMPT_<ts>_partNN.txt), each at most this large including its header.
+	// The main artifact then holds the manifest instead of the context.
+	SplitBytes  int
+	SplitTokens int
 
 	// CopyToClipboard is best-effort and must not fail the run if clipboard is unavailable.
 	CopyToClipboard bool
+
+	// Workers bounds concurrent file reads (0 = based on the CPU count).
+	Workers int
+
+	// ContextOut, if set, receives the context blob (or the part manifest) as well. When no
+	// option needs every file at once (focus, diff, skeleton, token budget, split, clipboard),
+	// files are streamed from disk through ContextOut and into the artifact, so memory stays
+	// flat however large the repository is; GenerateResult.ContextXML is then empty.
+	ContextOut io.Writer
 }
 
 type GenerateResult struct {
+	// ContextXML is the context blob, or the part manifest when the context was split.
+	// It is empty when the context was streamed to GenerateRequest.ContextOut.
 	ContextXML  string
 	Report      contractprompt.PromptReportV1
 	ReportJSON  string
@@ -42,6 +103,7 @@ type GenerateResult struct {
 
 	ArtifactPath string
 	LatestPath   string
+	PartPaths    []string
 	Copied       bool
 }
 
@@ -64,6 +126,32 @@ func (s *Service) Generate(req GenerateRequest) (GenerateResult, error) {
 	if req.MaxFileBytes <= 0 {
 		req.MaxFileBytes = 1_500_000
 	}
+	format, err := promptdomain.ParseContextFormat(string(req.Format))
+	if err != nil {
+		return GenerateResult{}, err
+	}
+	if req.MaxTokens < 0 {
+		return GenerateResult{}, fmt.Errorf("max tokens must not be negative")
+	}
+	diffMode := strings.TrimSpace(req.SinceRef) != "" || strings.TrimSpace(req.Range) != ""
+	if strings.TrimSpace(req.SinceRef) != "" && strings.TrimSpace(req.Range) != "" {
+		return GenerateResult{}, fmt.Errorf("use either --since or --range, not both")
+	}
+	if diffMode && strings.TrimSpace(req.Focus) != "" {
+		return GenerateResult{}, fmt.Errorf("--focus cannot be combined with --since/--range")
+	}
+	if diffMode && s.Git == nil {
+		return GenerateResult{}, fmt.Errorf("internal error: Git is nil")
+	}
+	if req.SplitBytes < 0 || req.SplitTokens < 0 {
+		return GenerateResult{}, fmt.Errorf("split limits must not be negative")
+	}
+	if req.SplitBytes > 0 && req.SplitTokens > 0 {
+		return GenerateResult{}, fmt.Errorf("use either --split-bytes or --split-tokens, not both")
+	}
+	if (req.MaxTokens > 0 || req.SplitTokens > 0) && s.TokenCounter == nil {
+		return GenerateResult{}, fmt.Errorf("internal error: TokenCounter is nil")
+	}
 
 	now := s.Clock.NowUTC()
 
@@ -75,36 +163,177 @@ func (s *Service) Generate(req GenerateRequest) (GenerateResult, error) {
 		return GenerateResult{}, fmt.Errorf(buildSafetyStopMessage(profile))
 	}
 
-	files, err := s.Repo.Scan(req.RootPath, profile, repoapi.ScanOptions{
+	scanOpts := repoapi.ScanOptions{
 		MaxFileBytes: req.MaxFileBytes,
 		IgnoreNames:  req.IgnoreNames,
 		IgnoreGlobs:  req.IgnoreGlobs,
-	})
+		IncludeExts:  req.IncludeExts,
+		IncludeGlobs: req.IncludeGlobs,
+		ExcludeExts:  req.ExcludeExts,
+	}
+	var files []project.FileRefV1
+	var ignored []project.IgnoredPathV1
+	if req.ExplainIgnores {
+		files, ignored, err = s.Repo.ScanExplained(req.RootPath, profile, scanOpts)
+	} else {
+		files, err = s.Repo.Scan(req.RootPath, profile, scanOpts)
+	}
 	if err != nil {
 		return GenerateResult{}, err
 	}
 
-	var inputs []promptdomain.FileInput
-	inputs = make([]promptdomain.FileInput, 0, len(files))
-
 	var totalBytes int64
-	var warnings []string
-
 	for _, f := range files {
 		totalBytes += f.SizeBytes
-		b, err := s.Repo.ReadFileRel(req.RootPath, f.RelPath, req.MaxFileBytes)
+	}
+
+	// Streaming reads each file while the artifact is written; everything else needs the
+	// whole set in memory to select, pack or split it.
+	streaming := req.ContextOut != nil && strings.TrimSpace(req.Focus) == "" && !diffMode && !req.Skeleton &&
+		req.MaxTokens == 0 && req.SplitBytes == 0 && req.SplitTokens == 0 && !req.CopyToClipboard
+
+	var inputs []promptdomain.FileInput
+	var warnings []string
+	var hashes []contractartifact.FileHashV1
+	if !streaming {
+		inputs = make([]promptdomain.FileInput, 0, len(files))
+		s.readFiles(req, files, func(i int, r fileRead) {
+			files[i].Encoding = r.Encoding
+			warnings = append(warnings, r.Warnings...)
+			if r.OK {
+				inputs = append(inputs, r.Input)
+				hashes = append(hashes, contractartifact.FileHashV1{Path: files[i].RelPath, SHA256: r.SHA256, Bytes: r.Bytes})
+			}
+		})
+	}
+
+	var focus *contractprompt.FocusReportV1
+	if strings.TrimSpace(req.Focus) != "" {
+		kept, fr, err := promptdomain.FocusFiles(inputs, promptdomain.FocusOptions{
+			Target:     req.Focus,
+			Depth:      req.FocusDepth,
+			Dependents: req.FocusDependents,
+		})
 		if err != nil {
-			warnings = append(warnings, "unable to read "+f.RelPath+": "+err.Error())
-			continue
+			return GenerateResult{}, err
+		}
+		inputs = kept
+		focus = &fr
+	}
+
+	var diffScope *contractprompt.DiffScopeV1
+	diffText := ""
+	if diffMode {
+		scope := contractprompt.DiffScopeV1{Mode: "since", Ref: strings.TrimSpace(req.SinceRef)}
+		var changed []string
+		if strings.TrimSpace(req.Range) != "" {
+			scope = contractprompt.DiffScopeV1{Mode: "range", Ref: strings.TrimSpace(req.Range)}
+			changed = s.Git.ChangedFilesInRange(req.RootPath, scope.Ref)
+		} else {
+			changed = s.Git.ChangedFilesSince(req.RootPath, scope.Ref)
+		}
+		if len(changed) == 0 {
+			return GenerateResult{}, fmt.Errorf("no changed files for %s %s (or git is unavailable / not a repository)", scope.Mode, scope.Ref)
+		}
+
+		kept, nodes, missing := promptdomain.ChangedNeighborhood(inputs, changed)
+		inputs = kept
+		for _, n := range nodes {
+			if n.Depth == 0 {
+				scope.ChangedFiles = append(scope.ChangedFiles, n.Path)
+			} else {
+				scope.Neighbors = append(scope.Neighbors, n.Path)
+			}
+		}
+		scope.Missing = missing
+
+		// Hunks for every changed path, including deletions that are not in the context;
+		// paths excluded by ignores/size limits stay out of the diff too.
+		scanned := map[string]bool{}
+		for _, f := range files {
+			scanned[f.RelPath] = true
+		}
+		var diffPaths []string
+		for _, p := range changed {
+			if scanned[p] || !fileExists(req.RootPath, p) {
+				diffPaths = append(diffPaths, p)
+			}
+		}
+		if len(diffPaths) > 0 {
+			if scope.Mode == "range" {
+				diffText = s.Git.DiffInRange(req.RootPath, scope.Ref, diffPaths)
+			} else {
+				diffText = s.Git.DiffSince(req.RootPath, scope.Ref, diffPaths)
+			}
+		}
+		if !req.NoRedact && diffText != "" {
+			redacted, n := promptdomain.RedactSecrets([]byte(diffText))
+			diffText = string(redacted)
+			if n > 0 {
+				warnings = append(warnings, "redacted "+itoa(n)+" secret(s) in the diff")
+			}
+		}
+		scope.DiffBytes = len(diffText)
+		diffScope = &scope
+	}
+
+	var skeletonized []string
+	if req.Skeleton {
+		inputs, skeletonized = promptdomain.SkeletonizeFiles(inputs, promptdomain.SkeletonOptions{Except: req.SkeletonExcept})
+	}
+
+	var dropped []contractprompt.DroppedFileV1
+	if req.MaxTokens > 0 {
+		budget := req.MaxTokens - s.countTokens(promptdomain.RenderDiffEntry(diffText, format))
+		if budget <= 0 {
+			return GenerateResult{}, fmt.Errorf("the <diff> section alone exceeds --max-tokens %d", req.MaxTokens)
+		}
+		packed := promptdomain.PackByTokenBudget(inputs, budget, format, s.countTokens)
+		inputs = packed.Kept
+		dropped = packed.Dropped
+		if len(dropped) > 0 {
+			warnings = append(warnings, "token budget: dropped "+itoa(len(dropped))+" file(s) to fit "+itoa(req.MaxTokens)+" tokens (see report dropped list)")
+		}
+	}
+
+	contextXML := ""
+	estimatedTokens := 0
+	if !streaming {
+		var buildWarnings []string
+		contextXML, buildWarnings = promptdomain.BuildContext(inputs, diffText, format)
+		warnings = append(warnings, buildWarnings...)
+		if s.TokenCounter != nil {
+			estimatedTokens = s.countTokens(contextXML)
 		}
-		inputs = append(inputs, promptdomain.FileInput{
-			RelPath: f.RelPath,
-			Content: b,
-		})
 	}
 
-	contextXML, buildWarnings := promptdomain.BuildContextBlob(inputs)
-	warnings = append(warnings, buildWarnings...)
+	var split *contractprompt.SplitReportV1
+	var partTexts []string
+	if req.SplitBytes > 0 || req.SplitTokens > 0 {
+		unit, limit, measure := "bytes", req.SplitBytes, func(text string) int { return len(text) }
+		if req.SplitTokens > 0 {
+			unit, limit, measure = "tokens", req.SplitTokens, s.countTokens
+		}
+		parts, splitWarnings, err := promptdomain.SplitContext(inputs, diffText, format, limit, measure)
+		if err != nil {
+			return GenerateResult{}, err
+		}
+		warnings = append(warnings, splitWarnings...)
+
+		sr := contractprompt.SplitReportV1{Unit: unit, Limit: limit}
+		for i, p := range parts {
+			text := promptdomain.PartHeader(i+1, len(parts)) + p.Blob
+			partTexts = append(partTexts, text)
+			sr.Parts = append(sr.Parts, contractprompt.PromptPartV1{
+				Index: i + 1,
+				File:  contractartifact.PartFilename("MEGAPROMPT", now, i+1, len(parts)),
+				Size:  measure(text),
+				Files: p.Files,
+				Diff:  p.Diff,
+			})
+		}
+		split = &sr
+	}
 
 	report := contractprompt.PromptReportV1{
 		GeneratedAt:   contractartifact.FormatRFC3339NanoUTC(now),
@@ -114,20 +343,59 @@ func (s *Service) Generate(req GenerateRequest) (GenerateResult, error) {
 		FilesIncluded: len(inputs),
 		TotalBytes:    totalBytes,
 		Files:         files,
-		Warnings:      warnings,
-	}
+		Ignored:       ignored,
+		Format:        string(format),
 
-	reportJSONBytes, _ := json.MarshalIndent(report, "", "  ")
-	reportJSON := string(reportJSONBytes)
+		Focus: focus,
+		Diff:  diffScope,
+
+		MaxTokens:       req.MaxTokens,
+		EstimatedTokens: estimatedTokens,
+		Dropped:         dropped,
+		Split:           split,
+		Skeletonized:    skeletonized,
+
+		Warnings: warnings,
+	}
 
-	agentPrompt := strings.Join([]string{
+	where := "below"
+	if split != nil {
+		where = "sent earlier in " + itoa(len(split.Parts)) + " part(s)"
+	}
+	promptLines := []string{
 		"You are an expert software engineer.",
-		"Using the <context> below (real source files), propose a MegaPatch v1 script.",
+		"Using the " + contextNoun(format) + " " + where + " (real source files), propose a MegaPatch v1 script.",
+	}
+	if len(skeletonized) > 0 {
+		promptLines = append(promptLines,
+			"Files marked skeleton show declarations and signatures only; their function bodies were elided.",
+			"Do not patch an elided body without asking for the full file.",
+			"",
+		)
+	}
+	if focus != nil {
+		promptLines = append(promptLines,
+			"The context is limited to "+focus.Target+" and its import neighborhood (depth "+itoa(focus.Depth)+"):",
+			focus.Tree,
+			"",
+		)
+	}
+	if diffScope != nil {
+		promptLines = append(promptLines,
+			"The context holds the files changed ("+diffScope.Mode+" "+diffScope.Ref+"), their direct import neighbors,",
+			"and a diff section with the unified hunks. Review the change or continue it as the user asks.",
+			"",
+		)
+	}
+	promptLines = append(promptLines,
 		"Rules:",
 		"- Do not delete directories. File deletes require explicit user consent.",
 		"- Keep changes minimal and correct; preserve existing architecture.",
 		"- Return only a single MegaPatch script (no prose).",
-	}, "\n")
+		"",
+		contractpatch.SyntaxV1,
+	)
+	agentPrompt := strings.Join(promptLines, "\n")
 
 	meta := contractartifact.ArtifactMetaV1{
 		Tool:         "megaprompt",
@@ -138,42 +406,170 @@ func (s *Service) Generate(req GenerateRequest) (GenerateResult, error) {
 		NetEnabled:   false,
 		AllowDomains: nil,
 		Warnings:     warnings,
+		Format:       string(format),
+	}
+
+	var partPaths []string
+	if split != nil {
+		contextXML = promptdomain.RenderManifest(*split)
+		partPaths, err = s.ArtifactWriter.WriteArtifactParts(ports.WriteArtifactPartsRequest{
+			ArtifactDir:    req.ArtifactDir,
+			ToolPrefix:     "MEGAPROMPT",
+			Parts:          partTexts,
+			GeneratedAtUTC: timePtr(now),
+		})
+		if err != nil {
+			return GenerateResult{}, err
+		}
 	}
 
 	envelope := contractartifact.ArtifactEnvelopeV1{
 		Meta:   meta,
 		XML:    contextXML,
-		JSON:   reportJSON,
 		Prompt: agentPrompt,
 	}
+	if streaming {
+		envelope.Manifest = contractartifact.NewManifest(req.RootPath, project.RelPaths(files))
+	} else {
+		included := make([]string, 0, len(inputs))
+		for _, in := range inputs {
+			included = append(included, in.RelPath)
+		}
+		envelope.Manifest = contractartifact.NewManifest(req.RootPath, included)
+		for _, h := range hashes {
+			envelope.Manifest.SetHash(h.Path, h.SHA256, h.Bytes)
+		}
+	}
 
-	artifactPath, latestPath, err := s.ArtifactWriter.WriteToolArtifact(ports.WriteArtifactRequest{
-		ArtifactDir:    req.ArtifactDir,
-		ToolPrefix:     "MEGAPROMPT",
-		Envelope:       envelope,
-		GeneratedAtUTC: timePtr(now),
-	})
+	var artifactPath, latestPath string
+	if streaming {
+		artifactPath, latestPath, err = s.ArtifactWriter.StreamToolArtifact(ports.StreamArtifactRequest{
+			ArtifactDir:    req.ArtifactDir,
+			ToolPrefix:     "MEGAPROMPT",
+			Envelope:       &envelope,
+			GeneratedAtUTC: timePtr(now),
+			XML: func(w io.Writer) error {
+				tokens := &tokenMeter{count: s.countTokens, enabled: s.TokenCounter != nil}
+				cw := promptdomain.NewContextWriter(io.MultiWriter(w, req.ContextOut, tokens), format)
+				included := 0
+				unread := map[string]bool{}
+				s.readFiles(req, files, func(i int, r fileRead) {
+					files[i].Encoding = r.Encoding
+					warnings = append(warnings, r.Warnings...)
+					if r.OK {
+						cw.WriteFile(r.Input)
+						envelope.Manifest.SetHash(files[i].RelPath, r.SHA256, r.Bytes)
+						included++
+					} else {
+						unread[files[i].RelPath] = true
+					}
+				})
+				if len(unread) > 0 {
+					kept := envelope.Manifest.Files[:0]
+					for _, f := range envelope.Manifest.Files {
+						if !unread[f.Path] {
+							kept = append(kept, f)
+						}
+					}
+					envelope.Manifest.Files = kept
+				}
+				buildWarnings, err := cw.Close()
+				warnings = append(warnings, buildWarnings...)
+
+				report.FilesIncluded = included
+				report.EstimatedTokens = tokens.total
+				report.Warnings = warnings
+				envelope.Meta.Warnings = warnings
+				envelope.JSON = marshalReport(report)
+				return err
+			},
+		})
+	} else {
+		envelope.JSON = marshalReport(report)
+		artifactPath, latestPath, err = s.ArtifactWriter.WriteToolArtifact(ports.WriteArtifactRequest{
+			ArtifactDir:    req.ArtifactDir,
+			ToolPrefix:     "MEGAPROMPT",
+			Envelope:       envelope,
+			GeneratedAtUTC: timePtr(now),
+		})
+	}
 	if err != nil {
 		return GenerateResult{}, err
 	}
+	if !streaming && req.ContextOut != nil {
+		if _, err := io.WriteString(req.ContextOut, contextXML); err != nil {
+			return GenerateResult{}, fmt.Errorf("failed writing context: %v", err)
+		}
+	}
 
 	copied := false
 	if req.CopyToClipboard && s.Clipboard != nil {
-		ok, _ := s.Clipboard.Copy(contextXML)
+		text := contextXML
+		if len(partTexts) > 0 {
+			text = partTexts[0]
+		}
+		ok, _ := s.Clipboard.Copy(text)
 		copied = ok
 	}
 
 	return GenerateResult{
 		ContextXML:   contextXML,
 		Report:       report,
-		ReportJSON:   reportJSON,
+		ReportJSON:   envelope.JSON,
 		AgentPrompt:  agentPrompt,
 		ArtifactPath: artifactPath,
 		LatestPath:   latestPath,
+		PartPaths:    partPaths,
 		Copied:       copied,
 	}, nil
 }
 
+func marshalReport(report contractprompt.PromptReportV1) string {
+	b, _ := json.MarshalIndent(report, "", "  ")
+	return string(b)
+}
+
+// tokenMeter estimates the tokens of a streamed context chunk by chunk (the context
+// writer emits one chunk per file entry).
+type tokenMeter struct {
+	count   func(text string) int
+	enabled bool
+	total   int
+}
+
+func (t *tokenMeter) Write(p []byte) (int, error) {
+	if t.enabled {
+		t.total += t.count(string(p))
+	}
+	return len(p), nil
+}
+
+// countTokens estimates tokens via the chat TokenCounter (input side only).
+func (s *Service) countTokens(text string) int {
+	u := s.TokenCounter.Count(chatports.TokenCountRequest{InputText: text})
+	if u.InputTokens == nil {
+		return 0
+	}
+	return *u.InputTokens
+}
+
+// contextNoun names the context blob in the agent prompt for the given format.
+func contextNoun(format promptdomain.ContextFormat) string {
+	switch format {
+	case promptdomain.FormatMarkdown:
+		return "Markdown context"
+	case promptdomain.FormatJSON:
+		return "JSON context array"
+	default:
+		return "<context>"
+	}
+}
+
+func fileExists(root string, rel string) bool {
+	_, err := os.Stat(filepath.Join(root, filepath.FromSlash(rel)))
+	return err == nil
+}
+
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
@@ -192,3 +588,22 @@ func buildSafetyStopMessage(p project.ProjectProfileV1) string {
 	b.WriteString("If you are certain, re-run with --force.\n")
 	return b.String()
 }
+
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	var buf [32]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = byte('0' + (n % 10))
+		n /= 10
+	}
+	return sign + string(buf[i:])
+}
@@ -1,6 +1,11 @@
 package domain
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
 	"strings"
 	"unicode/utf8"
 )
@@ -9,6 +14,39 @@ import (
 type FileInput struct {
 	RelPath string // POSIX-style relative path
 	Content []byte // raw bytes, expected to be UTF-8
+
+	// Skeleton marks content whose function bodies were elided (--skeleton).
+	Skeleton bool
+}
+
+// ContextFormat selects how the context blob is rendered.
+type ContextFormat string
+
+const (
+	// FormatPseudoXML uses each relative path as the tag name (the original format).
+	FormatPseudoXML ContextFormat = "pseudo-xml"
+	// FormatXML is well-formed XML: <file path="..."> elements.
+	FormatXML ContextFormat = "xml"
+	// FormatMarkdown uses a heading per file and fenced code blocks with language hints.
+	FormatMarkdown ContextFormat = "markdown"
+	// FormatJSON is a JSON array of {"type","path","language","content"} objects.
+	FormatJSON ContextFormat = "json"
+)
+
+// ParseContextFormat parses a --format value; empty means pseudo-xml.
+func ParseContextFormat(s string) (ContextFormat, error) {
+	switch ContextFormat(strings.ToLower(strings.TrimSpace(s))) {
+	case "", FormatPseudoXML:
+		return FormatPseudoXML, nil
+	case FormatXML:
+		return FormatXML, nil
+	case FormatMarkdown, "md":
+		return FormatMarkdown, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown format %q (expected pseudo-xml|xml|markdown|json)", s)
+	}
 }
 
 // BuildContextBlob builds the pseudo-XML <context> blob:
@@ -26,39 +64,338 @@ type FileInput struct {
 // - Tag names are relative paths, which is not strict XML.
 // - We defensively make CDATA safe by splitting any "]]>" sequences.
 func BuildContextBlob(files []FileInput) (blob string, warnings []string) {
+	return BuildContext(files, "", FormatPseudoXML)
+}
+
+// BuildContext renders files (and, when non-empty, a trailing diff section with the
+// unified hunks) in the given format. Files with an empty relpath or non-UTF-8 content
+// are skipped with a warning.
+func BuildContext(files []FileInput, diff string, format ContextFormat) (blob string, warnings []string) {
 	var b strings.Builder
 	b.Grow(1024 * 32)
+	cw := NewContextWriter(&b, format)
+	for _, f := range files {
+		cw.WriteFile(f)
+	}
+	cw.WriteDiff(diff)
+	warnings, _ = cw.Close()
+	return b.String(), warnings
+}
 
-	b.WriteString("<context>\n")
+// ContextWriter renders a context blob entry by entry to an io.Writer, producing the same
+// output as BuildContext without holding every file in memory. Write errors are sticky and
+// reported by Close.
+type ContextWriter struct {
+	w        io.Writer
+	l        contextLayout
+	entries  int
+	warnings []string
+	err      error
+}
 
-	for _, f := range files {
-		if strings.TrimSpace(f.RelPath) == "" {
-			warnings = append(warnings, "skipping file with empty relpath")
-			continue
+// NewContextWriter starts a context blob in the given format.
+func NewContextWriter(w io.Writer, format ContextFormat) *ContextWriter {
+	c := &ContextWriter{w: w, l: layoutFor(format)}
+	c.write(c.l.open)
+	return c
+}
+
+// WriteFile appends one file entry, or records a warning for an unusable one.
+func (c *ContextWriter) WriteFile(f FileInput) {
+	if strings.TrimSpace(f.RelPath) == "" {
+		c.warnings = append(c.warnings, "skipping file with empty relpath")
+		return
+	}
+	if !utf8.Valid(f.Content) {
+		c.warnings = append(c.warnings, "skipping non-UTF8 file: "+f.RelPath)
+		return
+	}
+	c.entry(c.l.file(f))
+}
+
+// WriteDiff appends the diff section; an empty diff writes nothing.
+func (c *ContextWriter) WriteDiff(diff string) {
+	if strings.TrimSpace(diff) != "" {
+		c.entry(c.l.diff(diff))
+	}
+}
+
+// Close ends the blob and returns the skip warnings and the first write error.
+func (c *ContextWriter) Close() ([]string, error) {
+	c.write(c.l.close)
+	return c.warnings, c.err
+}
+
+func (c *ContextWriter) entry(text string) {
+	if c.entries > 0 {
+		c.write(c.l.sep)
+	}
+	c.entries++
+	c.write(text)
+}
+
+func (c *ContextWriter) write(text string) {
+	if c.err != nil || text == "" {
+		return
+	}
+	_, c.err = io.WriteString(c.w, text)
+}
+
+// RenderDiffEntry renders the diff section as BuildContext emits it, or "" for an empty diff.
+func RenderDiffEntry(diff string, format ContextFormat) string {
+	if strings.TrimSpace(diff) == "" {
+		return ""
+	}
+	l := layoutFor(format)
+	return l.diff(diff) + l.sep
+}
+
+// contextLayout describes one format: entries are joined with sep between open and close.
+type contextLayout struct {
+	open  string
+	close string
+	sep   string
+	file  func(f FileInput) string
+	diff  func(diff string) string
+}
+
+func layoutFor(format ContextFormat) contextLayout {
+	switch format {
+	case FormatXML:
+		return contextLayout{
+			open:  "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<context>\n",
+			close: "</context>\n",
+			file: func(f FileInput) string {
+				return "<file path=\"" + escapeXMLAttr(f.RelPath) + "\"" + skeletonAttr(f) + "><![CDATA[\n" + xmlCDATA(string(f.Content)) + "]]></file>\n"
+			},
+			diff: func(d string) string {
+				return "<diff><![CDATA[\n" + xmlCDATA(d) + "]]></diff>\n"
+			},
 		}
-		if !utf8.Valid(f.Content) {
-			warnings = append(warnings, "skipping non-UTF8 file: "+f.RelPath)
-			continue
+	case FormatMarkdown:
+		return contextLayout{
+			open:  "# Context\n\n",
+			close: "",
+			sep:   "\n",
+			file: func(f FileInput) string {
+				heading := "## " + f.RelPath
+				if f.Skeleton {
+					heading += " (skeleton: bodies elided)"
+				}
+				return heading + "\n\n" + fenced(string(f.Content), languageHint(f.RelPath))
+			},
+			diff: func(d string) string {
+				return "## Diff\n\n" + fenced(d, "diff")
+			},
+		}
+	case FormatJSON:
+		return contextLayout{
+			open:  "[\n",
+			close: "\n]\n",
+			sep:   ",\n",
+			file: func(f FileInput) string {
+				fields := map[string]string{
+					"type":     "file",
+					"path":     f.RelPath,
+					"language": languageHint(f.RelPath),
+					"content":  string(f.Content),
+				}
+				if f.Skeleton {
+					fields["skeleton"] = "true"
+				}
+				return jsonEntry(fields)
+			},
+			diff: func(d string) string {
+				return jsonEntry(map[string]string{"type": "diff", "language": "diff", "content": d})
+			},
+		}
+	default:
+		return contextLayout{
+			open:  "<context>\n",
+			close: "</context>\n",
+			file:  renderFileBlock,
+			diff: func(d string) string {
+				d = strings.ReplaceAll(d, "]]>", "]]]]><![CDATA[>")
+				if !strings.HasSuffix(d, "\n") {
+					d += "\n"
+				}
+				return "<diff>\n<![CDATA[\n" + d + "]]>\n</diff>\n"
+			},
 		}
+	}
+}
+
+// renderFileBlock renders one pseudo-XML file entry.
+func renderFileBlock(f FileInput) string {
+	content := string(f.Content)
+	// Ensure CDATA cannot be prematurely terminated.
+	content = strings.ReplaceAll(content, "]]>", "]]]]><![CDATA[>")
+
+	var b strings.Builder
+	b.Grow(len(content) + 2*len(f.RelPath) + 32)
+	b.WriteString("<")
+	b.WriteString(f.RelPath)
+	b.WriteString(skeletonAttr(f))
+	b.WriteString(">\n")
+	b.WriteString("<![CDATA[\n")
+	b.WriteString(content)
+	if !strings.HasSuffix(content, "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("]]>\n")
+	b.WriteString("</")
+	b.WriteString(f.RelPath)
+	b.WriteString(">\n")
+	return b.String()
+}
 
-		content := string(f.Content)
-		// Ensure CDATA cannot be prematurely terminated.
-		content = strings.ReplaceAll(content, "]]>", "]]]]><![CDATA[>")
-
-		b.WriteString("<")
-		b.WriteString(f.RelPath)
-		b.WriteString(">\n")
-		b.WriteString("<![CDATA[\n")
-		b.WriteString(content)
-		if !strings.HasSuffix(content, "\n") {
-			b.WriteString("\n")
+// xmlCDATA makes text safe for a CDATA section in strict XML: "]]>" is split and
+// characters XML 1.0 forbids (most C0 controls) become U+FFFD.
+func xmlCDATA(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || (r >= 0x20 && r != 0xFFFE && r != 0xFFFF) {
+			return r
 		}
-		b.WriteString("]]>\n")
-		b.WriteString("</")
-		b.WriteString(f.RelPath)
-		b.WriteString(">\n")
+		return '\uFFFD'
+	}, s)
+	s = strings.ReplaceAll(s, "]]>", "]]]]><![CDATA[>")
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
 	}
+	return s
+}
 
-	b.WriteString("</context>\n")
-	return b.String(), warnings
+// skeletonAttr marks a skeletonized file's opening tag.
+func skeletonAttr(f FileInput) string {
+	if f.Skeleton {
+		return ` skeleton="true"`
+	}
+	return ""
+}
+
+func escapeXMLAttr(s string) string {
+	return strings.NewReplacer("&", "&amp;", "\"", "&quot;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// fenced wraps content in a code fence longer than any backtick run inside it.
+func fenced(content string, lang string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	fence := "```"
+	if longest >= 3 {
+		fence = strings.Repeat("`", longest+1)
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return fence + lang + "\n" + content + fence + "\n"
+}
+
+func jsonEntry(fields map[string]string) string {
+	// Fixed key order keeps the output stable and readable.
+	var parts []string
+	for _, k := range []string{"type", "path", "language", "skeleton", "content"} {
+		v, ok := fields[k]
+		if !ok || (v == "" && k != "content") {
+			continue
+		}
+		if k == "skeleton" {
+			// A flag, not text.
+			parts = append(parts, jsonString(k)+": "+v)
+			continue
+		}
+		parts = append(parts, jsonString(k)+": "+jsonString(v))
+	}
+	return "  {" + strings.Join(parts, ", ") + "}"
+}
+
+// jsonString encodes s without HTML escaping, so code stays readable (<, >, & as-is).
+func jsonString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// languageHint maps a file extension to a Markdown fence language.
+func languageHint(rel string) string {
+	base := strings.ToLower(path.Base(rel))
+	switch base {
+	case "dockerfile":
+		return "dockerfile"
+	case "makefile":
+		return "makefile"
+	case "go.mod", "go.sum":
+		return "text"
+	}
+	switch strings.ToLower(path.Ext(rel)) {
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".ts":
+		return "typescript"
+	case ".tsx":
+		return "tsx"
+	case ".js", ".mjs", ".cjs":
+		return "javascript"
+	case ".jsx":
+		return "jsx"
+	case ".rs":
+		return "rust"
+	case ".swift":
+		return "swift"
+	case ".java":
+		return "java"
+	case ".kt", ".kts":
+		return "kotlin"
+	case ".cs":
+		return "csharp"
+	case ".c", ".h":
+		return "c"
+	case ".cc", ".cpp", ".cxx", ".hpp", ".hh":
+		return "cpp"
+	case ".rb":
+		return "ruby"
+	case ".php":
+		return "php"
+	case ".lean":
+		return "lean"
+	case ".tex":
+		return "latex"
+	case ".sh", ".bash", ".zsh":
+		return "bash"
+	case ".ps1":
+		return "powershell"
+	case ".sql":
+		return "sql"
+	case ".tf":
+		return "hcl"
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	case ".xml":
+		return "xml"
+	case ".html", ".htm":
+		return "html"
+	case ".css":
+		return "css"
+	case ".md":
+		return "markdown"
+	default:
+		return ""
+	}
 }
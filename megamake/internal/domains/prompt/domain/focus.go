@@ -0,0 +1,231 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	contractprompt "github.com/megamake/megamake/internal/contracts/v1/prompt"
+)
+
+// Focus edge kinds.
+const (
+	EdgeFocus      = "focus"
+	EdgeImports    = "imports"
+	EdgeImportedBy = "imported-by"
+)
+
+// FocusOptions selects a neighborhood of the import graph.
+type FocusOptions struct {
+	// Target is a relpath, a directory relpath, or a symbol name (e.g. ParseSpec or Service.Run).
+	Target string
+	// Depth is how many import edges to follow from the target (0 = target only).
+	Depth int
+	// Dependents also follows reverse edges (files importing the visited files).
+	Dependents bool
+}
+
+// FocusFiles keeps only the target files and their import neighborhood, in input order.
+func FocusFiles(files []FileInput, opt FocusOptions) ([]FileInput, contractprompt.FocusReportV1, error) {
+	target := strings.Trim(strings.TrimPrefix(strings.ReplaceAll(strings.TrimSpace(opt.Target), "\\", "/"), "./"), "/")
+	if target == "" {
+		return nil, contractprompt.FocusReportV1{}, fmt.Errorf("focus: empty target")
+	}
+	if opt.Depth < 0 {
+		return nil, contractprompt.FocusReportV1{}, fmt.Errorf("focus: depth must not be negative")
+	}
+
+	roots, kind := resolveFocusTarget(target, files)
+	if len(roots) == 0 {
+		return nil, contractprompt.FocusReportV1{}, fmt.Errorf("focus: no file, directory or symbol definition matches %q", opt.Target)
+	}
+
+	kept, nodes := traverseImports(files, roots, opt.Depth, opt.Dependents)
+
+	return kept, contractprompt.FocusReportV1{
+		Target:     target,
+		TargetKind: kind,
+		Depth:      opt.Depth,
+		Dependents: opt.Dependents,
+		Roots:      roots,
+		Nodes:      nodes,
+		Tree:       renderFocusTree(nodes),
+	}, nil
+}
+
+// ChangedNeighborhood keeps the changed files plus their direct import neighbors
+// (files they import and files importing them), in input order.
+// Changed paths that were not scanned (deleted, ignored, binary) are returned as missing.
+func ChangedNeighborhood(files []FileInput, changed []string) (kept []FileInput, nodes []contractprompt.FocusNodeV1, missing []string) {
+	scanned := map[string]bool{}
+	for _, f := range files {
+		scanned[f.RelPath] = true
+	}
+	var roots []string
+	for _, p := range changed {
+		if scanned[p] {
+			roots = append(roots, p)
+		} else {
+			missing = append(missing, p)
+		}
+	}
+	kept, nodes = traverseImports(files, roots, 1, true)
+	return kept, nodes, missing
+}
+
+// traverseImports walks the import graph breadth-first from roots, so every file is
+// reported at its shortest distance. Kept files preserve the input order.
+func traverseImports(files []FileInput, roots []string, depth int, dependents bool) ([]FileInput, []contractprompt.FocusNodeV1) {
+	g := BuildFileGraph(files)
+
+	seen := map[string]bool{}
+	var nodes []contractprompt.FocusNodeV1
+	for _, r := range roots {
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+		nodes = append(nodes, contractprompt.FocusNodeV1{Path: r, Depth: 0, Edge: EdgeFocus})
+	}
+	for i := 0; i < len(nodes); i++ {
+		n := nodes[i]
+		if n.Depth >= depth {
+			continue
+		}
+		visit := func(next []string, edge string) {
+			for _, p := range next {
+				if seen[p] {
+					continue
+				}
+				seen[p] = true
+				nodes = append(nodes, contractprompt.FocusNodeV1{Path: p, Depth: n.Depth + 1, Parent: n.Path, Edge: edge})
+			}
+		}
+		visit(g.Imports[n.Path], EdgeImports)
+		if dependents {
+			visit(g.ImportedBy[n.Path], EdgeImportedBy)
+		}
+	}
+
+	kept := make([]FileInput, 0, len(seen))
+	for _, f := range files {
+		if seen[f.RelPath] {
+			kept = append(kept, f)
+		}
+	}
+	return kept, nodes
+}
+
+// resolveFocusTarget matches, in order: an exact file, a directory prefix, then symbol definitions.
+// For "A.B" symbols, files defining B are narrowed to those also defining A when possible.
+func resolveFocusTarget(target string, files []FileInput) ([]string, string) {
+	for _, f := range files {
+		if f.RelPath == target {
+			return []string{target}, "file"
+		}
+	}
+
+	var inDir []string
+	for _, f := range files {
+		if strings.HasPrefix(f.RelPath, target+"/") {
+			inDir = append(inDir, f.RelPath)
+		}
+	}
+	if len(inDir) > 0 {
+		return inDir, "dir"
+	}
+
+	// Anything path-like that did not match a file or directory is not a symbol.
+	if strings.Contains(target, "/") || !identPathRe.MatchString(target) {
+		return nil, ""
+	}
+
+	parts := strings.Split(target, ".")
+	defs := filesDefining(parts[len(parts)-1], files)
+	if len(parts) > 1 && len(defs) > 1 {
+		outer := map[string]bool{}
+		for _, p := range filesDefining(parts[len(parts)-2], files) {
+			outer[p] = true
+		}
+		var narrowed []string
+		for _, p := range defs {
+			if outer[p] {
+				narrowed = append(narrowed, p)
+			}
+		}
+		if len(narrowed) > 0 {
+			defs = narrowed
+		}
+	}
+	return defs, "symbol"
+}
+
+var identPathRe = regexp.MustCompile(`^[A-Za-z_$][\w$]*(\.[A-Za-z_$][\w$]*)*$`)
+
+// symbolDefTemplate matches a declaration of NAME across the supported languages:
+// Go func/method/type/var/const, Python def/class, JS/TS function/class/interface/type/enum
+// and const/let/var bindings, Rust fn/struct/enum/trait/mod, and Java/Kotlin/Swift/C# types.
+const symbolDefTemplate = `(?m)^[ \t]*(?:(?:export|default|declare|pub(?:\([^)]*\))?|public|private|protected|internal|static|abstract|final|async|open|data|sealed|override|unsafe)\s+)*` +
+	`(?:func|fn|def|function\*?|class|struct|interface|type|enum|trait|protocol|object|record|mod|fun|const|let|var)\s+` +
+	`(?:\([^)]*\)\s*)?NAME\b`
+
+func filesDefining(name string, files []FileInput) []string {
+	re := regexp.MustCompile(strings.Replace(symbolDefTemplate, "NAME", regexp.QuoteMeta(name), 1))
+	var out []string
+	for _, f := range files {
+		if languageForFocus(f.RelPath) && utf8.Valid(f.Content) && re.Match(f.Content) {
+			out = append(out, f.RelPath)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func languageForFocus(rel string) bool {
+	switch strings.ToLower(path.Ext(rel)) {
+	case ".go", ".py", ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".rs", ".swift", ".java", ".kt", ".kts", ".cs", ".lean":
+		return true
+	}
+	return false
+}
+
+// renderFocusTree renders nodes as an indented tree under their BFS parents:
+//
+//	internal/app/service.go [focus]
+//	  └─> internal/domain/spec.go
+//	  <── cmd/tool/main.go (imported-by)
+func renderFocusTree(nodes []contractprompt.FocusNodeV1) string {
+	children := map[string][]contractprompt.FocusNodeV1{}
+	var roots []contractprompt.FocusNodeV1
+	for _, n := range nodes {
+		if n.Parent == "" {
+			roots = append(roots, n)
+			continue
+		}
+		children[n.Parent] = append(children[n.Parent], n)
+	}
+
+	var lines []string
+	var walk func(n contractprompt.FocusNodeV1)
+	walk = func(n contractprompt.FocusNodeV1) {
+		indent := strings.Repeat("  ", n.Depth)
+		switch n.Edge {
+		case EdgeFocus:
+			lines = append(lines, n.Path+" [focus]")
+		case EdgeImportedBy:
+			lines = append(lines, indent+"<── "+n.Path+" (imported-by)")
+		default:
+			lines = append(lines, indent+"└─> "+n.Path)
+		}
+		for _, c := range children[n.Path] {
+			walk(c)
+		}
+	}
+	for _, r := range roots {
+		walk(r)
+	}
+	return strings.Join(lines, "\n")
+}
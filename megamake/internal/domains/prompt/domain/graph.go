@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	contractdoc "github.com/megamake/megamake/internal/contracts/v1/doc"
+	docdomain "github.com/megamake/megamake/internal/domains/doc/domain"
+)
+
+// FileGraph is the internal file-to-file import graph of the scanned files.
+// Both maps hold sorted, de-duplicated POSIX relpaths.
+type FileGraph struct {
+	Imports    map[string][]string // file -> files it imports
+	ImportedBy map[string][]string // file -> files importing it
+}
+
+// BuildFileGraph resolves internal imports with doc's BuildImportGraph, plus Go
+// module-path imports (see addGoPackageEdges).
+func BuildFileGraph(files []FileInput) FileGraph {
+	relPaths := make([]string, 0, len(files))
+	contents := make(map[string]string, len(files))
+	for _, f := range files {
+		relPaths = append(relPaths, f.RelPath)
+		if utf8.Valid(f.Content) {
+			contents[f.RelPath] = string(f.Content)
+		}
+	}
+
+	imports, _, _ := docdomain.BuildImportGraph(relPaths, contents)
+
+	edges := map[string]map[string]bool{}
+	add := func(from, to string) {
+		if from == to {
+			return
+		}
+		if edges[from] == nil {
+			edges[from] = map[string]bool{}
+		}
+		edges[from][to] = true
+	}
+	for _, im := range imports {
+		if im.IsInternal && im.ResolvedPath != "" {
+			add(im.File, im.ResolvedPath)
+		}
+	}
+	addGoPackageEdges(add, relPaths, contents, imports)
+
+	g := FileGraph{Imports: map[string][]string{}, ImportedBy: map[string][]string{}}
+	for from, tos := range edges {
+		for to := range tos {
+			g.Imports[from] = append(g.Imports[from], to)
+			g.ImportedBy[to] = append(g.ImportedBy[to], from)
+		}
+	}
+	for k := range g.Imports {
+		sort.Strings(g.Imports[k])
+	}
+	for k := range g.ImportedBy {
+		sort.Strings(g.ImportedBy[k])
+	}
+	return g
+}
+
+// addGoPackageEdges links Go imports of the module's own packages.
+// BuildImportGraph treats module-path imports as external, so they are resolved here
+// against the root go.mod: the importer depends on every non-test .go file of the package.
+func addGoPackageEdges(add func(from, to string), relPaths []string, contents map[string]string, imports []contractdoc.DocImportV1) {
+	module := ""
+	for _, line := range strings.Split(contents["go.mod"], "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "module ") {
+			module = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module")), `"`)
+			break
+		}
+	}
+	if module == "" {
+		return
+	}
+
+	filesByDir := map[string][]string{}
+	for _, rel := range relPaths {
+		if strings.HasSuffix(rel, ".go") && !strings.HasSuffix(rel, "_test.go") {
+			filesByDir[path.Dir(rel)] = append(filesByDir[path.Dir(rel)], rel)
+		}
+	}
+
+	for _, im := range imports {
+		if im.Language != "go" || !strings.HasPrefix(im.Raw, module+"/") {
+			continue
+		}
+		for _, rel := range filesByDir[strings.TrimPrefix(im.Raw, module+"/")] {
+			add(im.File, rel)
+		}
+	}
+}
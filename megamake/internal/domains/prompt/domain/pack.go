@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	contractprompt "github.com/megamake/megamake/internal/contracts/v1/prompt"
+)
+
+// Priority buckets, most important first.
+const (
+	PriorityManifest   = "manifest"
+	PriorityEntrypoint = "entrypoint"
+	PriorityReadme     = "readme"
+	PriorityImported   = "imported"
+	PriorityOther      = "other"
+)
+
+// TokenCountFunc estimates the number of tokens in text.
+type TokenCountFunc func(text string) int
+
+// RankedFile is a file with its packing priority.
+type RankedFile struct {
+	File       FileInput
+	Priority   string
+	ImportedBy int // number of files importing this file
+}
+
+// PackResult is the outcome of PackByTokenBudget.
+type PackResult struct {
+	// Kept preserves the input order so the <context> blob stays stable across budgets.
+	Kept    []FileInput
+	Dropped []contractprompt.DroppedFileV1
+	Tokens  int // estimated tokens of the packed blob (including the format's wrapper)
+}
+
+var manifestNames = map[string]bool{
+	"go.mod":           true,
+	"go.work":          true,
+	"package.json":     true,
+	"tsconfig.json":    true,
+	"cargo.toml":       true,
+	"pyproject.toml":   true,
+	"requirements.txt": true,
+	"pipfile":          true,
+	"setup.py":         true,
+	"setup.cfg":        true,
+	"pom.xml":          true,
+	"build.gradle":     true,
+	"build.gradle.kts": true,
+	"settings.gradle":  true,
+	"package.swift":    true,
+	"composer.json":    true,
+	"gemfile":          true,
+	"cmakelists.txt":   true,
+	"makefile":         true,
+	"dockerfile":       true,
+	"lakefile.lean":    true,
+}
+
+var entrypointNames = map[string]bool{
+	"main.go":     true,
+	"main.py":     true,
+	"__main__.py": true,
+	"app.py":      true,
+	"manage.py":   true,
+	"main.rs":     true,
+	"lib.rs":      true,
+	"main.ts":     true,
+	"main.js":     true,
+	"index.ts":    true,
+	"index.tsx":   true,
+	"index.js":    true,
+	"server.ts":   true,
+	"server.js":   true,
+	"main.swift":  true,
+	"main.java":   true,
+	"main.kt":     true,
+	"program.cs":  true,
+}
+
+// RankFiles orders files for packing: manifests, entrypoints and READMEs first, then
+// source files by import centrality (how many files import them), then the rest.
+// Ties keep a stable path order. Entrypoints are only recognized near the root
+// (at most two directories deep, e.g. cmd/tool/main.go or src/main.rs).
+func RankFiles(files []FileInput) []RankedFile {
+	g := BuildFileGraph(files)
+
+	ranked := make([]RankedFile, 0, len(files))
+	for _, f := range files {
+		ranked = append(ranked, RankedFile{
+			File:       f,
+			Priority:   priorityFor(f.RelPath, len(g.ImportedBy[f.RelPath])),
+			ImportedBy: len(g.ImportedBy[f.RelPath]),
+		})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		pi, pj := priorityRank(ranked[i].Priority), priorityRank(ranked[j].Priority)
+		if pi != pj {
+			return pi < pj
+		}
+		if ranked[i].ImportedBy != ranked[j].ImportedBy {
+			return ranked[i].ImportedBy > ranked[j].ImportedBy
+		}
+		return ranked[i].File.RelPath < ranked[j].File.RelPath
+	})
+	return ranked
+}
+
+// PackByTokenBudget keeps ranked files while they fit in maxTokens.
+// A file that does not fit is dropped, but smaller lower-ranked files may still fill the
+// remaining budget. Costs are measured in the output format. Non-UTF-8 files are passed
+// through untouched; BuildContext skips them.
+func PackByTokenBudget(files []FileInput, maxTokens int, format ContextFormat, count TokenCountFunc) PackResult {
+	l := layoutFor(format)
+	overhead := count(l.open + l.close)
+	used := overhead
+	ranked := RankFiles(files)
+
+	keep := map[string]bool{}
+	var dropped []contractprompt.DroppedFileV1
+	for _, rf := range ranked {
+		if !utf8.Valid(rf.File.Content) {
+			keep[rf.File.RelPath] = true
+			continue
+		}
+		// Per-file estimates are rounded down individually; pad by one token so the
+		// sum never undercounts the estimate for the whole blob.
+		cost := count(l.file(rf.File)+l.sep) + 1
+		left := maxTokens - used
+		if cost <= left {
+			used += cost
+			keep[rf.File.RelPath] = true
+			continue
+		}
+
+		reason := "exceeds remaining budget (" + itoa(left) + " tokens left)"
+		if cost > maxTokens-overhead {
+			reason = "larger than the whole budget"
+		}
+		dropped = append(dropped, contractprompt.DroppedFileV1{
+			Path:     rf.File.RelPath,
+			Tokens:   cost,
+			Priority: rf.Priority,
+			Reason:   reason,
+		})
+	}
+
+	kept := make([]FileInput, 0, len(keep))
+	for _, f := range files {
+		if keep[f.RelPath] {
+			kept = append(kept, f)
+		}
+	}
+	return PackResult{Kept: kept, Dropped: dropped, Tokens: used}
+}
+
+func priorityFor(rel string, importedBy int) string {
+	base := strings.ToLower(path.Base(rel))
+	depth := strings.Count(rel, "/")
+
+	switch {
+	case manifestNames[base] || strings.HasSuffix(base, ".csproj"):
+		return PriorityManifest
+	case entrypointNames[base] && depth <= 2:
+		return PriorityEntrypoint
+	case strings.HasPrefix(base, "readme"):
+		return PriorityReadme
+	case importedBy > 0:
+		return PriorityImported
+	default:
+		return PriorityOther
+	}
+}
+
+func priorityRank(p string) int {
+	switch p {
+	case PriorityManifest:
+		return 0
+	case PriorityEntrypoint:
+		return 1
+	case PriorityReadme:
+		return 2
+	case PriorityImported:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	var buf [32]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = byte('0' + (n % 10))
+		n /= 10
+	}
+	return sign + string(buf[i:])
+}
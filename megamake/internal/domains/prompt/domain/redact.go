@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+
+	securedomain "github.com/megamake/megamake/internal/domains/secure/domain"
+)
+
+// RedactSecrets replaces every secret the secure rules detect in content (cloud and API
+// keys, tokens, JWTs, private key material, random-looking assigned values) with a
+// placeholder such as [REDACTED:secret.aws-access-key-id:1a2b3c4d].
+//
+// The hash suffix is derived from the secret itself, so the same secret maps to the same
+// placeholder in every file and every run; the model can still tell two keys apart.
+// Non-UTF-8 content is returned unchanged.
+func RedactSecrets(content []byte) ([]byte, int) {
+	if !utf8.Valid(content) {
+		return content, 0
+	}
+	text := string(content)
+	spans := securedomain.FindSecrets(text)
+	if len(spans) == 0 {
+		return content, 0
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+	last := 0
+	for _, sp := range spans {
+		b.WriteString(text[last:sp.Start])
+		b.WriteString(secretPlaceholder(sp.RuleID, text[sp.Start:sp.End]))
+		last = sp.End
+	}
+	b.WriteString(text[last:])
+	return []byte(b.String()), len(spans)
+}
+
+func secretPlaceholder(ruleID string, secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return "[REDACTED:" + ruleID + ":" + hex.EncodeToString(sum[:4]) + "]"
+}
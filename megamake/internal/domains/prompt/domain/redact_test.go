@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+const testToken = "Zq8xK2mP9vL4nR7tW3yB6cD1"
+
+func TestRedactSecretsUnquotedAssignments(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+	}{
+		{"yaml", "db:\n  host: localhost\n  password: " + testToken + "\n"},
+		{"dotenv", "DEBUG=true\nAPI_TOKEN=" + testToken + "\n"},
+		{"dotenv export with comment", "export API_TOKEN=" + testToken + "  # rotated monthly\n"},
+		{"quoted", "token = \"" + testToken + "\"\n"},
+	}
+	for _, c := range cases {
+		out, n := RedactSecrets([]byte(c.text))
+		if n != 1 || strings.Contains(string(out), testToken) {
+			t.Errorf("%s: redacted %d, output %q", c.name, n, out)
+		}
+		if !strings.Contains(string(out), "[REDACTED:secret.") {
+			t.Errorf("%s: no placeholder in %q", c.name, out)
+		}
+	}
+}
+
+func TestRedactSecretsKeepsOrdinaryUnquotedValues(t *testing.T) {
+	for _, text := range []string{
+		"image: registry.example.com/team/app-server\n",
+		"checksum: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08\n",
+		"name: AVeryLongButOrdinaryCamelCaseName\n",
+		"DATABASE_URL=postgres://localhost:5432/app\n",
+	} {
+		if out, n := RedactSecrets([]byte(text)); n != 0 {
+			t.Errorf("redacted %d in %q: %q", n, text, out)
+		}
+	}
+}
@@ -0,0 +1,590 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	testplandomain "github.com/megamake/megamake/internal/domains/testplan/domain"
+	"github.com/megamake/megamake/internal/platform/glob"
+)
+
+// SkeletonOptions controls SkeletonizeFiles.
+type SkeletonOptions struct {
+	// Except lists relpath globs whose files keep their full bodies.
+	Except []string
+}
+
+// SkeletonizeFiles replaces function and method bodies with a short elision comment in every
+// file of a supported language (the languages testplan analyzes, except Lean), keeping
+// imports, type declarations, fields and signatures. Skeletonized files are marked so the
+// renderers can tell the model the bodies were elided. Returns the skeletonized relpaths.
+func SkeletonizeFiles(files []FileInput, opt SkeletonOptions) ([]FileInput, []string) {
+	out := make([]FileInput, 0, len(files))
+	var done []string
+	for _, f := range files {
+		if matchesAnyGlob(f.RelPath, opt.Except) || !utf8.Valid(f.Content) {
+			out = append(out, f)
+			continue
+		}
+		skel, n := Skeletonize(f.RelPath, string(f.Content))
+		if n == 0 {
+			out = append(out, f)
+			continue
+		}
+		out = append(out, FileInput{RelPath: f.RelPath, Content: []byte(skel), Skeleton: true})
+		done = append(done, f.RelPath)
+	}
+	return out, done
+}
+
+// Skeletonize elides the bodies in content and returns how many were elided. Functions are
+// found with testplan's extractors (testplandomain.FunctionDecls) where it has them; Rust,
+// Swift, Java and Kotlin fall back to a brace walker. Unsupported languages are returned
+// unchanged with a count of 0.
+func Skeletonize(rel string, content string) (string, int) {
+	lang := testplandomain.LanguageForRel(rel)
+	decls, ok := testplandomain.FunctionDecls(content, lang)
+	switch {
+	case ok && lang == "python":
+		return skeletonPython(content, decls)
+	case ok:
+		return skeletonDecls(content, decls, lang)
+	}
+	switch lang {
+	case "rust", "swift", "java", "kotlin":
+		return skeletonBraces(content, lang)
+	default:
+		return content, 0
+	}
+}
+
+func matchesAnyGlob(rel string, patterns []string) bool {
+	for _, p := range patterns {
+		if strings.TrimSpace(p) != "" && glob.Match(rel, p) {
+			return true
+		}
+	}
+	return false
+}
+
+const elidedBody = "/* … body elided … */"
+
+// skeletonDecls replaces the body of each declared function with an elision comment. Decls
+// inside a comment, a string or an already elided body are skipped, as are declarations
+// without a body (interface members, overloads).
+func skeletonDecls(src string, decls []testplandomain.FunctionDecl, lang string) (string, int) {
+	literals := literalRanges(src, lang)
+	var b strings.Builder
+	b.Grow(len(src))
+	emitted, count, lit := 0, 0, 0
+
+	for _, d := range decls {
+		if d.Start < emitted {
+			continue
+		}
+		for lit < len(literals) && literals[lit][1] <= d.Params {
+			lit++
+		}
+		if lit < len(literals) && literals[lit][0] <= d.Params {
+			continue
+		}
+		closeParen := matchParen(src, d.Params, lang)
+		if closeParen < 0 {
+			continue
+		}
+		open := bodyOpen(src, closeParen+1, d.Kind, lang)
+		if open < 0 {
+			continue
+		}
+		end := matchBrace(src, open, lang)
+		if end < 0 {
+			// Unbalanced braces: keep the rest verbatim.
+			break
+		}
+		b.WriteString(src[emitted : open+1])
+		b.WriteString(" " + elidedBody + " }")
+		count++
+		emitted = end + 1
+	}
+	b.WriteString(src[emitted:])
+	return b.String(), count
+}
+
+// literalRanges returns the [start, end) offsets of the comments and strings in src.
+func literalRanges(src string, lang string) [][2]int {
+	var out [][2]int
+	for i := 0; i < len(src); {
+		if j := skipLiteral(src, i, lang); j > i {
+			out = append(out, [2]int{i, j})
+			i = j
+			continue
+		}
+		i++
+	}
+	return out
+}
+
+// matchParen returns the index of the ")" closing the "(" at open, or -1.
+func matchParen(src string, open int, lang string) int {
+	depth := 0
+	for i := open; i < len(src); {
+		if j := skipLiteral(src, i, lang); j > i {
+			i = j
+			continue
+		}
+		switch src[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+		i++
+	}
+	return -1
+}
+
+// bodyOpen returns the index of the "{" opening the body of the function whose parameter
+// list ends just before i, or -1 if it has none. Braces of types in the result (Go
+// interface{} and struct{}, TypeScript object types) are skipped.
+func bodyOpen(src string, i int, kind string, lang string) int {
+	if kind == testplandomain.DeclArrow {
+		k := strings.Index(src[i:], "=>")
+		if k < 0 {
+			return -1
+		}
+		i += k + 2
+		for i < len(src) && isSpace(src[i]) {
+			i++
+		}
+		if i < len(src) && src[i] == '{' {
+			return i
+		}
+		return -1
+	}
+	if lang != "go" {
+		// A body or a return type annotation follows the parameters directly.
+		k := i
+		for k < len(src) && isSpace(src[k]) {
+			k++
+		}
+		if k >= len(src) || (src[k] != '{' && src[k] != ':') {
+			return -1
+		}
+	}
+
+	depth := 0
+	for ; i < len(src); i++ {
+		if j := skipLiteral(src, i, lang); j > i {
+			i = j - 1
+			continue
+		}
+		switch c := src[i]; c {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case '<', '>':
+			// TypeScript generics; Go only has "<-" here, and "=>" is not a bracket.
+			if lang == "go" || src[i-1] == '=' {
+				continue
+			}
+			if c == '<' {
+				depth++
+			} else {
+				depth--
+			}
+		case ';', '}', '=':
+			if c == '=' && i+1 < len(src) && src[i+1] == '>' {
+				continue
+			}
+			if depth <= 0 {
+				return -1
+			}
+		case '\n':
+			if lang == "go" && depth <= 0 {
+				return -1
+			}
+		case '{':
+			if depth > 0 || isTypeBrace(src[:i], lang) {
+				end := matchBrace(src, i, lang)
+				if end < 0 {
+					return -1
+				}
+				i = end
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// isTypeBrace reports whether a "{" preceded by before opens a type rather than a body.
+func isTypeBrace(before string, lang string) bool {
+	t := strings.TrimRight(before, " \t\r\n")
+	if lang == "go" {
+		return strings.HasSuffix(t, "interface") || strings.HasSuffix(t, "struct")
+	}
+	for _, p := range []string{":", "|", "&", ",", "=>"} {
+		if strings.HasSuffix(t, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// skeletonBraces walks brace-delimited code, skipping strings and comments. A "{" whose
+// header (the code since the previous statement) looks like a function signature has its
+// whole block replaced; type, class and namespace blocks are kept and walked into, so
+// methods inside them are elided too.
+func skeletonBraces(src string, lang string) (string, int) {
+	var b strings.Builder
+	b.Grow(len(src))
+	emitted, headerStart, count := 0, 0, 0
+
+	for i := 0; i < len(src); {
+		if j := skipLiteral(src, i, lang); j > i {
+			i = j
+			continue
+		}
+		switch src[i] {
+		case ';', '}':
+			headerStart = i + 1
+		case '{':
+			if isFuncHeader(src[headerStart:i], lang) {
+				end := matchBrace(src, i, lang)
+				if end < 0 {
+					// Unbalanced braces: keep the rest verbatim.
+					b.WriteString(src[emitted:])
+					return b.String(), count
+				}
+				b.WriteString(src[emitted : i+1])
+				b.WriteString(" " + elidedBody + " }")
+				count++
+				emitted, headerStart, i = end+1, end+1, end+1
+				continue
+			}
+			headerStart = i + 1
+		}
+		i++
+	}
+	b.WriteString(src[emitted:])
+	return b.String(), count
+}
+
+// blockKeywords open blocks whose contents are declarations, not statements.
+var blockKeywords = map[string]bool{
+	"class": true, "interface": true, "struct": true, "enum": true, "trait": true, "impl": true,
+	"object": true, "namespace": true, "module": true, "mod": true, "protocol": true,
+	"extension": true, "record": true, "type": true, "union": true, "extern": true,
+}
+
+var headerWordRe = regexp.MustCompile(`[A-Za-z_]\w*`)
+
+// isFuncHeader reports whether the code before a "{" ends in a callable's signature:
+// the last statement has a parameter list and no block keyword outside its parentheses.
+func isFuncHeader(header string, lang string) bool {
+	tail := lastStatement(stripComments(header, lang))
+	if !strings.Contains(tail, ")") {
+		return false
+	}
+	for _, w := range headerWordRe.FindAllString(stripParenGroups(tail), -1) {
+		if blockKeywords[w] {
+			return false
+		}
+	}
+	return true
+}
+
+// lastStatement returns the header's last line outside parentheses, extended backwards
+// over continuation lines such as "throws X" or an Allman-style lone brace line.
+func lastStatement(header string) string {
+	var starts []int // offsets right after depth-0 newlines
+	depth := 0
+	for i := 0; i < len(header); i++ {
+		switch header[i] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			if depth > 0 {
+				depth--
+			}
+		case '\n':
+			if depth == 0 {
+				starts = append(starts, i+1)
+			}
+		}
+	}
+	for k := len(starts) - 1; k >= 0; k-- {
+		tail := strings.TrimSpace(header[starts[k]:])
+		if tail == "" || isContinuation(tail) {
+			continue
+		}
+		// Inside a where clause (Rust, Swift) the lines before end in "," or "where".
+		prevStart := 0
+		if k > 0 {
+			prevStart = starts[k-1]
+		}
+		prev := strings.TrimSpace(header[prevStart:starts[k]])
+		if strings.HasSuffix(prev, ",") || prev == "where" || strings.HasSuffix(prev, " where") {
+			continue
+		}
+		return header[starts[k]:]
+	}
+	return header
+}
+
+func isContinuation(line string) bool {
+	for _, p := range []string{"throws", "where", ":", "->", "=>", "extends", "implements", ",", ")"} {
+		if strings.HasPrefix(line, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func stripParenGroups(s string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			if depth > 0 {
+				depth--
+			}
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func stripComments(s string, lang string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		j := skipLiteral(s, i, lang)
+		if j == i {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		if s[i] != '/' {
+			b.WriteString(s[i:j])
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// matchBrace returns the index of the "}" closing the "{" at open, or -1.
+func matchBrace(src string, open int, lang string) int {
+	depth := 0
+	for i := open; i < len(src); {
+		if j := skipLiteral(src, i, lang); j > i {
+			i = j
+			continue
+		}
+		switch src[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+		i++
+	}
+	return -1
+}
+
+// skipLiteral returns the end of the comment or string literal starting at i, or i if none does.
+func skipLiteral(src string, i int, lang string) int {
+	rest := src[i:]
+	switch {
+	case strings.HasPrefix(rest, "//"):
+		if k := strings.IndexByte(rest, '\n'); k >= 0 {
+			return i + k
+		}
+		return len(src)
+	case strings.HasPrefix(rest, "/*"):
+		if k := strings.Index(rest[2:], "*/"); k >= 0 {
+			return i + 2 + k + 2
+		}
+		return len(src)
+	case strings.HasPrefix(rest, `"""`):
+		if k := strings.Index(rest[3:], `"""`); k >= 0 {
+			return i + 3 + k + 3
+		}
+		return len(src)
+	case rest[0] == '"':
+		return skipQuoted(src, i, '"', false, true)
+	case rest[0] == '`':
+		// Go raw strings and Kotlin identifiers have no escapes; JS template literals do.
+		return skipQuoted(src, i, '`', true, lang == "javascript" || lang == "typescript")
+	case rest[0] == '\'':
+		// Rust lifetimes ('a) and generics are not char literals.
+		if lang == "rust" && !(len(rest) > 2 && (rest[1] == '\\' || rest[2] == '\'')) {
+			return i
+		}
+		return skipQuoted(src, i, '\'', false, true)
+	}
+	return i
+}
+
+// skipQuoted skips a quoted literal. Unless multiline, an unclosed literal ends at the
+// newline so one stray quote cannot swallow the rest of the file.
+func skipQuoted(src string, i int, q byte, multiline bool, escapes bool) int {
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			if escapes {
+				j++
+			}
+		case q:
+			return j + 1
+		case '\n':
+			if !multiline {
+				return j
+			}
+		}
+	}
+	return len(src)
+}
+
+// skeletonPython keeps each def's signature (which may span lines) and docstring, and
+// replaces the rest of its indented body with "...". Class bodies are kept, so their
+// methods are elided individually.
+func skeletonPython(src string, decls []testplandomain.FunctionDecl) (string, int) {
+	lines := strings.SplitAfter(src, "\n")
+	defAt := make(map[int]bool, len(decls)) // line start offsets
+	for _, d := range decls {
+		defAt[d.Start] = true
+	}
+	offsets := make([]int, len(lines))
+	for k, off := 0, 0; k < len(lines); k++ {
+		offsets[k] = off
+		off += len(lines[k])
+	}
+	var out []string
+	count := 0
+
+	for i := 0; i < len(lines); {
+		if !defAt[offsets[i]] {
+			out = append(out, lines[i])
+			i++
+			continue
+		}
+		indent := leadingWidth(lines[i])
+
+		// Signature: until the brackets opened by the def line are balanced again.
+		depth := 0
+		j := i
+		for j < len(lines) {
+			code := stripPyComment(lines[j])
+			depth += strings.Count(code, "(") + strings.Count(code, "[") - strings.Count(code, ")") - strings.Count(code, "]")
+			out = append(out, lines[j])
+			j++
+			if depth <= 0 {
+				break
+			}
+		}
+
+		// Body: blank lines and lines indented deeper than the def.
+		bodyStart := j
+		for j < len(lines) && (strings.TrimSpace(lines[j]) == "" || leadingWidth(lines[j]) > indent) {
+			j++
+		}
+		// Trailing blank lines belong to what follows.
+		for j > bodyStart && strings.TrimSpace(lines[j-1]) == "" {
+			j--
+		}
+		body := lines[bodyStart:j]
+		if len(body) == 0 {
+			i = j
+			continue
+		}
+
+		pad := strings.Repeat(" ", leadingWidth(firstNonBlank(body)))
+		kept := docstringLines(body)
+		out = append(out, kept...)
+		if len(kept) < len(body) {
+			out = append(out, pad+"...  # body elided\n")
+		}
+		count++
+		i = j
+	}
+	return strings.Join(out, ""), count
+}
+
+// docstringLines returns the leading docstring lines of a body, if it starts with one.
+func docstringLines(body []string) []string {
+	for k, l := range body {
+		t := strings.TrimSpace(l)
+		if t == "" {
+			continue
+		}
+		var q string
+		for _, p := range []string{`"""`, `'''`, `r"""`, `r'''`} {
+			if strings.HasPrefix(t, p) {
+				q = p[len(p)-3:]
+				t = t[len(p):]
+				break
+			}
+		}
+		if q == "" {
+			return nil
+		}
+		if strings.Contains(t, q) {
+			return body[:k+1]
+		}
+		for e := k + 1; e < len(body); e++ {
+			if strings.Contains(body[e], q) {
+				return body[:e+1]
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+func stripPyComment(line string) string {
+	if k := strings.Index(line, " #"); k >= 0 {
+		return line[:k]
+	}
+	return line
+}
+
+func leadingWidth(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+func firstNonBlank(lines []string) string {
+	for _, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			return l
+		}
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	contractprompt "github.com/megamake/megamake/internal/contracts/v1/prompt"
+)
+
+// ContextPart is one part of a split context: a complete blob in the context format,
+// holding whole files and, in the last part, the diff section.
+type ContextPart struct {
+	Files []string
+	Diff  bool
+	Blob  string
+}
+
+// SplitContext packs files, in order, into consecutive parts whose size (header + blob,
+// measured in bytes or tokens by measure) stays within limit. Files are never cut: a file
+// larger than the limit gets a part of its own and a warning. Files BuildContext would skip
+// (empty relpath, non-UTF-8) are left out; BuildContext already warns about them.
+func SplitContext(files []FileInput, diff string, format ContextFormat, limit int, measure func(string) int) ([]ContextPart, []string, error) {
+	l := layoutFor(format)
+	// Reserve room for the longest header any part can get.
+	reserve := measure(PartHeader(999, 1000))
+	if h := measure(PartHeader(1000, 1000)); h > reserve {
+		reserve = h
+	}
+	budget := limit - reserve - measure(l.open+l.close)
+	if budget <= 0 {
+		return nil, nil, fmt.Errorf("split limit %d is too small for a part header", limit)
+	}
+
+	var parts []ContextPart
+	var warnings []string
+	var cur []FileInput
+	curDiff := false
+	used := 0
+
+	flush := func() {
+		if len(cur) == 0 && !curDiff {
+			return
+		}
+		d := ""
+		if curDiff {
+			d = diff
+		}
+		blob, _ := BuildContext(cur, d, format)
+		p := ContextPart{Diff: curDiff, Blob: blob}
+		for _, f := range cur {
+			p.Files = append(p.Files, f.RelPath)
+		}
+		parts = append(parts, p)
+		cur, curDiff, used = nil, false, 0
+	}
+	add := func(name string, entry string) {
+		// Pad by one so per-entry rounding never undercounts the part (see PackByTokenBudget).
+		cost := measure(entry+l.sep) + 1
+		if used > 0 && used+cost > budget {
+			flush()
+		}
+		if cost > budget {
+			warnings = append(warnings, name+" alone exceeds the split limit ("+itoa(cost)+" > "+itoa(budget)+" available per part); it gets a part of its own")
+		}
+		used += cost
+	}
+
+	for _, f := range files {
+		if strings.TrimSpace(f.RelPath) == "" || !utf8.Valid(f.Content) {
+			continue
+		}
+		add(f.RelPath, l.file(f))
+		cur = append(cur, f)
+	}
+	if strings.TrimSpace(diff) != "" {
+		add("the diff section", l.diff(diff))
+		curDiff = true
+	}
+	flush()
+	return parts, warnings, nil
+}
+
+// PartHeader is the plain-text preamble of part k of n. It tells the model the context
+// arrives in several messages and, except for the last part, to wait for the rest.
+func PartHeader(k int, n int) string {
+	if k < n {
+		return "[megaprompt part " + itoa(k) + "/" + itoa(n) + "]\n" +
+			"This is part " + itoa(k) + " of " + itoa(n) + " of one codebase context. Files are never cut across parts.\n" +
+			"Do not act on it yet; reply only \"received part " + itoa(k) + "/" + itoa(n) + "\".\n\n"
+	}
+	return "[megaprompt part " + itoa(k) + "/" + itoa(n) + "]\n" +
+		"This is the last part (" + itoa(k) + " of " + itoa(n) + ") of one codebase context. All parts are now available;\n" +
+		"treat them as a single context and follow the instructions that come next.\n\n"
+}
+
+// RenderManifest renders the split manifest stored in the main artifact:
+//
+//	<manifest unit="tokens" limit="100000" parts="2">
+//	<part index="1" file="MEGAPROMPT_..._part01.txt" size="98211">
+//	go.mod
+//	cmd/tool/main.go
+//	</part>
+//	...
+//	</manifest>
+func RenderManifest(split contractprompt.SplitReportV1) string {
+	var b strings.Builder
+	b.WriteString("<manifest unit=\"" + escapeXMLAttr(split.Unit) + "\" limit=\"" + itoa(split.Limit) + "\" parts=\"" + itoa(len(split.Parts)) + "\">\n")
+	for _, p := range split.Parts {
+		b.WriteString("<part index=\"" + itoa(p.Index) + "\" file=\"" + escapeXMLAttr(p.File) + "\" size=\"" + itoa(p.Size) + "\"")
+		if p.Diff {
+			b.WriteString(" diff=\"true\"")
+		}
+		b.WriteString(">\n")
+		for _, f := range p.Files {
+			b.WriteString(f + "\n")
+		}
+		b.WriteString("</part>\n")
+	}
+	b.WriteString("</manifest>\n")
+	return b.String()
+}
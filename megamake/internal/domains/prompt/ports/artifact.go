@@ -1,6 +1,7 @@
 package ports
 
 import (
+	"io"
 	"time"
 
 	contractartifact "github.com/megamake/megamake/internal/contracts/v1/artifact"
@@ -14,6 +15,27 @@ type WriteArtifactRequest struct {
 	GeneratedAtUTC *time.Time
 }
 
+// WriteArtifactPartsRequest writes the part files of a split artifact (<prefix>_<ts>_partNN.txt).
+type WriteArtifactPartsRequest struct {
+	ArtifactDir    string
+	ToolPrefix     string
+	Parts          []string
+	GeneratedAtUTC *time.Time
+}
+
+// StreamArtifactRequest writes an artifact whose XML block is produced by XML while the file
+// is written, so the context never has to be held in memory as one string. XML may set
+// Envelope.JSON and Envelope.Prompt, which are written after the XML block.
+type StreamArtifactRequest struct {
+	ArtifactDir    string
+	ToolPrefix     string
+	Envelope       *contractartifact.ArtifactEnvelopeV1
+	GeneratedAtUTC *time.Time
+	XML            func(w io.Writer) error
+}
+
 type ArtifactWriter interface {
 	WriteToolArtifact(req WriteArtifactRequest) (artifactPath string, latestPointerPath string, err error)
+	StreamToolArtifact(req StreamArtifactRequest) (artifactPath string, latestPointerPath string, err error)
+	WriteArtifactParts(req WriteArtifactPartsRequest) (partPaths []string, err error)
 }
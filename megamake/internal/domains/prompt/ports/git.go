@@ -0,0 +1,8 @@
+package ports
+
+type Git interface {
+	ChangedFilesSince(root string, ref string) []string
+	ChangedFilesInRange(root string, rng string) []string
+	DiffSince(root string, ref string, paths []string) string
+	DiffInRange(root string, rng string, paths []string) string
+}
@@ -18,7 +18,22 @@ func NewOSDetector() OSDetector {
 	return OSDetector{}
 }
 
+// Detect profiles rootPath and, when it is a monorepo workspace root (go.work, npm/yarn/pnpm
+// workspaces, a Cargo workspace or a Gradle multi-project build), each member package.
 func (d OSDetector) Detect(rootPath string) (project.ProjectProfileV1, error) {
+	profile, err := d.detectOne(rootPath)
+	if err != nil || !dirExists(rootPath) {
+		return profile, err
+	}
+	profile.Workspaces, profile.Members = d.detectMembers(rootPath, profile)
+	if len(profile.Members) > 0 && !profile.IsCodeProject {
+		profile.IsCodeProject = true
+		profile.Why = append(profile.Why, "workspace root: "+strings.Join(profile.Workspaces, ", "))
+	}
+	return profile, nil
+}
+
+func (d OSDetector) detectOne(rootPath string) (project.ProjectProfileV1, error) {
 	rootAbs, err := filepath.Abs(rootPath)
 	if err != nil {
 		return project.ProjectProfileV1{}, err
@@ -38,32 +53,14 @@ func (d OSDetector) Detect(rootPath string) (project.ProjectProfileV1, error) {
 		}, nil
 	}
 
-	// Markers (close to Swift ProjectDetector)
-	markerFiles := map[string][]string{
-		"typescript": {"tsconfig.json"},
-		"javascript": {"package.json"},
-		"python":     {"pyproject.toml", "requirements.txt", "Pipfile", "setup.py", "setup.cfg", "tox.ini"},
-		"go":         {"go.mod"},
-		"rust":       {"Cargo.toml"},
-		"java":       {"pom.xml", "build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"},
-		"kotlin":     {"build.gradle.kts"},
-		"csharp":     {"*.sln", "*.csproj"},
-		"cpp":        {"CMakeLists.txt"},
-		"php":        {"composer.json"},
-		"ruby":       {"Gemfile"},
-		"swift":      {"Package.swift", "*.xcodeproj"},
-		"terraform":  {"*.tf"},
-		"docker":     {"Dockerfile"},
-		"lean":       {"lakefile.lean", "lean-toolchain", "*.lean"},
-		"latex":      {"latexmkrc", "*.tex"},
-	}
-
 	languageSet := map[string]bool{}
 	markersSet := map[string]bool{}
 	var why []string
 
-	for lang, patterns := range markerFiles {
-		for _, pat := range patterns {
+	// Markers and source extensions come from the language-pack registry.
+	for _, pack := range domain.LanguagePacks() {
+		lang := pack.Name
+		for _, pat := range pack.Markers {
 			hits, _ := glob.FindMatches(rootAbs, pat)
 			if len(hits) == 0 {
 				continue
@@ -86,24 +83,6 @@ func (d OSDetector) Detect(rootPath string) (project.ProjectProfileV1, error) {
 	}
 
 	// Source file heuristic (>= 8 recognizable source files).
-	sourceExtToLang := map[string]string{
-		".ts": "typescript", ".tsx": "typescript",
-		".js": "javascript", ".jsx": "javascript", ".mjs": "javascript", ".cjs": "javascript",
-		".py":   "python",
-		".go":   "go",
-		".rs":   "rust",
-		".java": "java",
-		".kt":   "kotlin", ".kts": "kotlin",
-		".c": "cpp", ".cc": "cpp", ".cpp": "cpp", ".cxx": "cpp", ".h": "cpp", ".hpp": "cpp", ".hh": "cpp",
-		".cs":    "csharp",
-		".php":   "php",
-		".rb":    "ruby",
-		".swift": "swift",
-		".tf":    "terraform",
-		".lean":  "lean",
-		".tex":   "latex", ".cls": "latex", ".sty": "latex", ".bib": "latex",
-	}
-
 	sourceFileCount := 0
 
 	_ = filepath.WalkDir(rootAbs, func(path string, entry fs.DirEntry, walkErr error) error {
@@ -128,7 +107,7 @@ func (d OSDetector) Detect(rootPath string) (project.ProjectProfileV1, error) {
 
 		if entry.Type().IsRegular() {
 			ext := strings.ToLower(filepath.Ext(path))
-			if lang, ok := sourceExtToLang[ext]; ok {
+			if lang := domain.LanguageForExt(ext); lang != "" {
 				languageSet[lang] = true
 				sourceFileCount++
 			}
@@ -4,8 +4,10 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/megamake/megamake/internal/contracts/v1/project"
 	"github.com/megamake/megamake/internal/domains/repo/domain"
@@ -13,18 +15,16 @@ import (
 	"github.com/megamake/megamake/internal/platform/glob"
 )
 
-var _ = os.PathSeparator
-
 type OSScanner struct{}
 
 func NewOSScanner() OSScanner {
 	return OSScanner{}
 }
 
-func (s OSScanner) Scan(req ports.ScanRequest) ([]project.FileRefV1, error) {
+func (s OSScanner) Scan(req ports.ScanRequest) (ports.ScanResult, error) {
 	rootAbs, err := filepath.Abs(req.RootPath)
 	if err != nil {
-		return nil, err
+		return ports.ScanResult{}, err
 	}
 
 	// Build a language set for rules.
@@ -37,7 +37,7 @@ func (s OSScanner) Scan(req ports.ScanRequest) ([]project.FileRefV1, error) {
 		langSet["typescript"] = true
 	}
 
-	rules := domain.BuildRules(langSet)
+	rules := domain.BuildRules(langSet).WithOverrides(req.IncludeExts, req.IncludeGlobs, req.ExcludeExts)
 
 	ignoreNames := toSet(req.IgnoreNames)
 	ignoreGlobs := normalizeGlobs(req.IgnoreGlobs)
@@ -47,99 +47,260 @@ func (s OSScanner) Scan(req ports.ScanRequest) ([]project.FileRefV1, error) {
 		maxBytes = 1_500_000
 	}
 
-	var files []project.FileRefV1
+	// .gitignore/.megamakeignore rules: each directory gets its parent's rules plus its own.
+	var rootIgnores domain.IgnoreMatcher
+	loadAncestorIgnoreFiles(&rootIgnores, rootAbs)
+	loadIgnoreFiles(&rootIgnores, rootAbs, "")
+
+	w := &scanWalk{
+		req:         req,
+		rules:       rules,
+		ignoreNames: ignoreNames,
+		ignoreGlobs: ignoreGlobs,
+		maxBytes:    maxBytes,
+		sem:         make(chan struct{}, scanWorkers()),
+	}
+	w.visitDir(rootAbs, "", &rootIgnores)
+	w.wg.Wait()
 
-	_ = filepath.WalkDir(rootAbs, func(path string, entry fs.DirEntry, walkErr error) error {
-		if walkErr != nil {
-			return nil
-		}
+	// Directories finish in any order; sorting keeps the output deterministic.
+	sort.Slice(w.files, func(i, j int) bool {
+		return w.files[i].RelPath < w.files[j].RelPath
+	})
+	sort.Slice(w.ignored, func(i, j int) bool {
+		return w.ignored[i].RelPath < w.ignored[j].RelPath
+	})
+	return ports.ScanResult{Files: w.files, Ignored: w.ignored}, nil
+}
+
+// scanWorkers bounds the directories read concurrently.
+func scanWorkers() int {
+	n := runtime.GOMAXPROCS(0)
+	if n < 4 {
+		n = 4
+	}
+	if n > 32 {
+		n = 32
+	}
+	return n
+}
+
+// scanWalk walks the tree with one goroutine per directory; sem bounds how many read a
+// directory at once, and results are collected under mu.
+type scanWalk struct {
+	req         ports.ScanRequest
+	rules       domain.IncludeRules
+	ignoreNames map[string]bool
+	ignoreGlobs []string
+	maxBytes    int64
+
+	sem chan struct{}
+	wg  sync.WaitGroup
+
+	mu      sync.Mutex
+	files   []project.FileRefV1
+	ignored []project.IgnoredPathV1
+}
 
-		rel, relErr := filepath.Rel(rootAbs, path)
-		if relErr != nil {
-			return nil
+// visitDir schedules dirAbs (relpath rel, "" for the root), whose ignore files are
+// already in ignores.
+func (w *scanWalk) visitDir(dirAbs string, rel string, ignores *domain.IgnoreMatcher) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.sem <- struct{}{}
+		entries, err := os.ReadDir(dirAbs)
+		if err != nil {
+			<-w.sem
+			return
 		}
-		rel = filepath.ToSlash(rel)
-		rel = strings.TrimPrefix(rel, "./")
-		if rel == "." {
-			return nil
+		var files []project.FileRefV1
+		var ignored []project.IgnoredPathV1
+		for _, entry := range entries {
+			childRel := entry.Name()
+			if rel != "" {
+				childRel = rel + "/" + entry.Name()
+			}
+			childAbs := filepath.Join(dirAbs, entry.Name())
+			if entry.IsDir() {
+				if ig, ok := w.dirIgnored(entry.Name(), childRel, ignores); ok {
+					if ig != nil {
+						ignored = append(ignored, *ig)
+					}
+					continue
+				}
+				child := ignores.Clone()
+				loadIgnoreFiles(&child, childAbs, childRel)
+				w.visitDir(childAbs, childRel, &child)
+				continue
+			}
+			f, ig, ok := w.considerFile(entry, childRel, ignores)
+			if ig != nil {
+				ignored = append(ignored, *ig)
+			}
+			if ok {
+				files = append(files, f)
+			}
 		}
+		<-w.sem
 
-		if entry.IsDir() {
-			name := entry.Name()
-			low := strings.ToLower(name)
+		w.mu.Lock()
+		w.files = append(w.files, files...)
+		w.ignored = append(w.ignored, ignored...)
+		w.mu.Unlock()
+	}()
+}
 
-			// prune by built-in names + user ignore names
-			if rules.PruneDirs[name] || rules.PruneDirs[low] || ignoreNames[name] || ignoreNames[low] {
-				return fs.SkipDir
-			}
+// dirIgnored reports whether a directory is pruned, with the ignore-file rule responsible
+// when ExplainIgnores is set.
+func (w *scanWalk) dirIgnored(name string, rel string, ignores *domain.IgnoreMatcher) (*project.IgnoredPathV1, bool) {
+	low := strings.ToLower(name)
 
-			// prune by ignore globs (if rel path matches a glob, skip entire dir)
-			if matchAnyGlob(rel, ignoreGlobs) {
-				return fs.SkipDir
-			}
+	// prune by built-in names + user ignore names
+	if w.rules.PruneDirs[name] || w.rules.PruneDirs[low] || w.ignoreNames[name] || w.ignoreNames[low] {
+		return nil, true
+	}
 
-			return nil
-		}
+	// prune by ignore globs (if rel path matches a glob, skip entire dir)
+	if matchAnyGlob(rel, w.ignoreGlobs) {
+		return nil, true
+	}
 
-		// file-level ignore by name segments/globs
-		if isInIgnoredPath(rel, rules.PruneDirs, ignoreNames, ignoreGlobs) {
-			return nil
-		}
+	if skip, rule := ignores.Match(rel, true); skip {
+		return w.explain(rel+"/", rule), true
+	}
+	return nil, false
+}
 
-		info, err := entry.Info()
-		if err != nil {
-			return nil
-		}
-		if !info.Mode().IsRegular() {
-			return nil
-		}
+// considerFile applies the include rules to one non-directory entry.
+func (w *scanWalk) considerFile(entry fs.DirEntry, rel string, ignores *domain.IgnoreMatcher) (project.FileRefV1, *project.IgnoredPathV1, bool) {
+	rules := w.rules
+	secret := w.req.IncludeSecretFiles && domain.IsSecretFile(strings.ToLower(entry.Name()))
+
+	// file-level ignore by name segments/globs; the built-in prune names are directory names
+	// (".env" is also a virtualenv), so a wanted secret file is only checked against the user's.
+	pruneDirs := rules.PruneDirs
+	if secret {
+		pruneDirs = nil
+	}
+	if isInIgnoredPath(rel, pruneDirs, w.ignoreNames, w.ignoreGlobs) {
+		return project.FileRefV1{}, nil, false
+	}
+	if skip, rule := ignores.Match(rel, false); skip {
+		return project.FileRefV1{}, w.explain(rel, rule), false
+	}
 
-		base := info.Name()
-		baseLower := strings.ToLower(base)
-		ext := strings.ToLower(filepath.Ext(baseLower))
-
-		// Auto-ignore megamake tool artifacts (since we now default to writing them into CWD).
-		// Examples:
-		//   MEGAPROMPT_20260207_225153Z.txt
-		//   MEGAPROMPT_latest.txt
-		//   MEGADOC_....txt, MEGATEST_....txt, MEGADIAG_....txt, etc.
-		if strings.HasPrefix(baseLower, "mega") && strings.HasSuffix(baseLower, ".txt") {
-			return nil
+	info, err := entry.Info()
+	if err != nil {
+		return project.FileRefV1{}, nil, false
+	}
+	if !info.Mode().IsRegular() {
+		return project.FileRefV1{}, nil, false
+	}
+
+	base := info.Name()
+	baseLower := strings.ToLower(base)
+	ext := strings.ToLower(filepath.Ext(baseLower))
+
+	// Auto-ignore megamake tool artifacts (since we now default to writing them into CWD).
+	// Examples:
+	//   MEGAPROMPT_20260207_225153Z.txt
+	//   MEGAPROMPT_latest.txt
+	//   MEGADOC_....txt, MEGATEST_....txt, MEGADIAG_....txt, etc.
+	if strings.HasPrefix(baseLower, "mega") && strings.HasSuffix(baseLower, ".txt") {
+		return project.FileRefV1{}, nil, false
+	}
+
+	// Secret files bypass the language include rules; only the size cap applies.
+	if secret {
+		if info.Size() > w.maxBytes {
+			return project.FileRefV1{}, nil, false
 		}
+		return project.FileRefV1{RelPath: rel, SizeBytes: info.Size()}, nil, true
+	}
+
+	// explicit noisy / secret-ish excludes
+	if strings.HasPrefix(baseLower, ".env") {
+		return project.FileRefV1{}, nil, false
+	}
+	if strings.HasSuffix(baseLower, ".min.js") {
+		return project.FileRefV1{}, nil, false
+	}
+	if rules.ExcludeNames[baseLower] {
+		return project.FileRefV1{}, nil, false
+	}
+	// An explicit glob (CI files, --include-glob) beats the extension deny-list.
+	if rules.ExcludeExts[ext] && !matchAnyGlob(rel, rules.ForceIncludeGlobs) {
+		return project.FileRefV1{}, nil, false
+	}
+
+	// size cap
+	if info.Size() > w.maxBytes {
+		return project.FileRefV1{}, nil, false
+	}
+
+	if !shouldIncludeFile(rel, baseLower, ext, rules) {
+		return project.FileRefV1{}, nil, false
+	}
+	return project.FileRefV1{
+		RelPath:   rel,
+		SizeBytes: info.Size(),
+		IsTest:    domain.IsTestFile(rel),
+	}, nil, true
+}
+
+func (w *scanWalk) explain(rel string, rule *domain.IgnoreRule) *project.IgnoredPathV1 {
+	if !w.req.ExplainIgnores {
+		return nil
+	}
+	return &project.IgnoredPathV1{RelPath: rel, Source: rule.Source, Line: rule.Line, Pattern: rule.Pattern}
+}
 
-		// explicit noisy / secret-ish excludes
-		if strings.HasPrefix(baseLower, ".env") {
-			return nil
+// loadAncestorIgnoreFiles adds the ignore files between the enclosing git work tree's top
+// and rootAbs (outermost first), as git applies them. Outside a git work tree it adds nothing.
+func loadAncestorIgnoreFiles(m *domain.IgnoreMatcher, rootAbs string) {
+	if _, err := os.Stat(filepath.Join(rootAbs, ".git")); err == nil {
+		return
+	}
+	var dirs []string
+	for dir := filepath.Dir(rootAbs); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
 		}
-		if strings.HasSuffix(baseLower, ".min.js") {
-			return nil
+		if filepath.Dir(dir) == dir {
+			return // no work tree above
 		}
-		if rules.ExcludeNames[baseLower] {
-			return nil
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rootFromDir, err := filepath.Rel(dirs[i], rootAbs)
+		if err != nil {
+			continue
 		}
-		if rules.ExcludeExts[ext] {
-			return nil
+		up := strings.Repeat("../", strings.Count(filepath.ToSlash(rootFromDir), "/")+1)
+		for _, name := range domain.IgnoreFileNames {
+			b, err := os.ReadFile(filepath.Join(dirs[i], name))
+			if err != nil {
+				continue
+			}
+			m.Add(domain.ParseIgnoreFileAbove(filepath.ToSlash(rootFromDir), up+name, string(b)))
 		}
+	}
+}
 
-		// size cap
-		if info.Size() > maxBytes {
-			return nil
+// loadIgnoreFiles adds the rules of the ignore files in dirAbs (relpath rel, "" for the root).
+func loadIgnoreFiles(m *domain.IgnoreMatcher, dirAbs string, rel string) {
+	for _, name := range domain.IgnoreFileNames {
+		b, err := os.ReadFile(filepath.Join(dirAbs, name))
+		if err != nil {
+			continue
 		}
-
-		if shouldIncludeFile(rel, baseLower, ext, rules) {
-			files = append(files, project.FileRefV1{
-				RelPath:   rel,
-				SizeBytes: info.Size(),
-				IsTest:    domain.IsTestFile(rel),
-			})
+		source := name
+		if rel != "" {
+			source = rel + "/" + name
 		}
-		return nil
-	})
-
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].RelPath < files[j].RelPath
-	})
-	return files, nil
+		m.Add(domain.ParseIgnoreFile(rel, source, string(b)))
+	}
 }
 
 func shouldIncludeFile(rel string, baseLower string, ext string, rules domain.IncludeRules) bool {
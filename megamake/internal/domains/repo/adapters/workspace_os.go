@@ -0,0 +1,175 @@
+package adapters
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/megamake/megamake/internal/contracts/v1/project"
+	"github.com/megamake/megamake/internal/domains/repo/domain"
+	"github.com/megamake/megamake/internal/platform/glob"
+)
+
+// detectMembers reads the workspace files at the root and profiles every member directory.
+// A directory declared by several workspace files is listed once, under the first kind.
+func (d OSDetector) detectMembers(rootPath string, rootProfile project.ProjectProfileV1) ([]string, []project.WorkspaceMemberV1) {
+	rootAbs, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, nil
+	}
+
+	var files []string
+	var members []project.WorkspaceMemberV1
+	seen := map[string]bool{}
+
+	for _, name := range domain.WorkspaceFiles {
+		data, err := os.ReadFile(filepath.Join(rootAbs, name))
+		if err != nil {
+			continue
+		}
+		spec, ok := domain.ParseWorkspaceFile(name, string(data))
+		if !ok {
+			continue
+		}
+		files = append(files, name)
+
+		rels := expandMemberPatterns(rootAbs, spec)
+		// A Cargo workspace root that is also a crate is a member too.
+		if spec.Kind == domain.WorkspaceCargo && domain.CargoHasPackage(string(data)) {
+			rels = append([]string{"."}, rels...)
+		}
+
+		for _, rel := range rels {
+			if seen[rel] {
+				continue
+			}
+			seen[rel] = true
+
+			var profile project.ProjectProfileV1
+			if rel == "." {
+				profile = rootProfile
+			} else {
+				profile, err = d.detectOne(filepath.Join(rootPath, filepath.FromSlash(rel)))
+				if err != nil {
+					continue
+				}
+			}
+
+			name := ""
+			if spec.Manifest != "" {
+				if b, err := os.ReadFile(filepath.Join(rootAbs, filepath.FromSlash(rel), spec.Manifest)); err == nil {
+					name = domain.MemberName(spec.Manifest, string(b))
+				}
+			}
+			if name == "" {
+				name = rel
+			}
+
+			members = append(members, project.WorkspaceMemberV1{
+				Name:    name,
+				RelPath: rel,
+				Kind:    string(spec.Kind),
+				Profile: profile,
+			})
+		}
+	}
+
+	sort.Slice(members, func(i, j int) bool { return members[i].RelPath < members[j].RelPath })
+	return files, members
+}
+
+// expandMemberPatterns resolves a spec's member patterns to existing member directories
+// (POSIX relpaths), dropping excluded ones and directories without the spec's manifest.
+func expandMemberPatterns(rootAbs string, spec domain.WorkspaceSpec) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, pat := range spec.Members {
+		for _, rel := range expandMemberPattern(rootAbs, pat) {
+			if seen[rel] || excludedMember(rel, spec.Excludes) {
+				continue
+			}
+			abs := filepath.Join(rootAbs, filepath.FromSlash(rel))
+			if spec.Manifest != "" && !fileExists(filepath.Join(abs, spec.Manifest)) {
+				continue
+			}
+			seen[rel] = true
+			out = append(out, rel)
+		}
+	}
+	return out
+}
+
+func expandMemberPattern(rootAbs string, pat string) []string {
+	if !strings.ContainsAny(pat, "*?[") {
+		abs := filepath.Join(rootAbs, filepath.FromSlash(pat))
+		if abs == rootAbs {
+			return []string{"."}
+		}
+		// Like glob hits, literal members outside the root ("../sibling") are not walked.
+		if rel, ok := relUnder(rootAbs, abs); ok && dirExists(abs) {
+			return []string{rel}
+		}
+		return nil
+	}
+
+	var out []string
+	if !strings.Contains(pat, "**") {
+		hits, _ := filepath.Glob(filepath.Join(rootAbs, filepath.FromSlash(pat)))
+		for _, h := range hits {
+			if rel, ok := relUnder(rootAbs, h); ok && dirExists(h) {
+				out = append(out, rel)
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	// "**" patterns: walk the tree, skipping the directories the scanner prunes.
+	prune := domain.BuildRules(nil).PruneDirs
+	_ = filepath.WalkDir(rootAbs, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil || !entry.IsDir() {
+			return nil
+		}
+		rel, ok := relUnder(rootAbs, path)
+		if !ok {
+			return nil
+		}
+		if prune[entry.Name()] {
+			return fs.SkipDir
+		}
+		if glob.Match(rel, pat) {
+			out = append(out, rel)
+		}
+		return nil
+	})
+	return out
+}
+
+func excludedMember(rel string, excludes []string) bool {
+	for _, ex := range excludes {
+		if rel == ex || glob.Match(rel, ex) {
+			return true
+		}
+	}
+	return false
+}
+
+func relUnder(rootAbs string, path string) (string, bool) {
+	rel, err := filepath.Rel(rootAbs, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func dirExists(p string) bool {
+	fi, err := os.Stat(p)
+	return err == nil && fi.IsDir()
+}
+
+func fileExists(p string) bool {
+	fi, err := os.Stat(p)
+	return err == nil && !fi.IsDir()
+}
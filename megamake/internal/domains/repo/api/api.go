@@ -10,9 +10,20 @@ import (
 type API interface {
 	Detect(rootPath string) (project.ProjectProfileV1, error)
 	Scan(rootPath string, profile project.ProjectProfileV1, opts ScanOptions) ([]project.FileRefV1, error)
+	// ScanExplained also lists the paths excluded by .gitignore/.megamakeignore rules.
+	ScanExplained(rootPath string, profile project.ProjectProfileV1, opts ScanOptions) ([]project.FileRefV1, []project.IgnoredPathV1, error)
 	ReadFileRel(rootPath string, relPath string, maxBytes int64) ([]byte, error)
+	// ReadTextRel reads a file as UTF-8, transcoding UTF-16/32 and legacy encodings; encoding
+	// is the source encoding ("" when already UTF-8). Binary files are rejected.
+	ReadTextRel(rootPath string, relPath string, maxBytes int64) (text []byte, encoding string, err error)
+	// ReadTextFileRel reads like ReadTextRel and also returns the SHA-256 and size of the
+	// bytes on disk, for artifact manifests.
+	ReadTextFileRel(rootPath string, relPath string, maxBytes int64) (TextFile, error)
 }
 
+// TextFile is a file read as UTF-8 text plus the digest of its bytes on disk.
+type TextFile = app.TextFile
+
 // Dependencies are the OS adapters (or mocks) injected by the composition root.
 type Dependencies struct {
 	Detector ports.Detector
@@ -25,6 +36,15 @@ type ScanOptions struct {
 	MaxFileBytes int64
 	IgnoreNames  []string
 	IgnoreGlobs  []string
+
+	// IncludeExts, IncludeGlobs and ExcludeExts override the language-aware include rules.
+	IncludeExts  []string
+	IncludeGlobs []string
+	ExcludeExts  []string
+
+	// IncludeSecretFiles keeps the credential-bearing files (.env*, .npmrc, *.pem, ...) that
+	// are otherwise excluded so they never reach a prompt. Only the secret scan sets it.
+	IncludeSecretFiles bool
 }
 
 func New(deps Dependencies) API {
@@ -47,9 +67,35 @@ func (r *repoAPI) Scan(rootPath string, profile project.ProjectProfileV1, opts S
 		MaxFileBytes: opts.MaxFileBytes,
 		IgnoreNames:  opts.IgnoreNames,
 		IgnoreGlobs:  opts.IgnoreGlobs,
+		IncludeExts:  opts.IncludeExts,
+		IncludeGlobs: opts.IncludeGlobs,
+		ExcludeExts:  opts.ExcludeExts,
+
+		IncludeSecretFiles: opts.IncludeSecretFiles,
+	})
+}
+
+func (r *repoAPI) ScanExplained(rootPath string, profile project.ProjectProfileV1, opts ScanOptions) ([]project.FileRefV1, []project.IgnoredPathV1, error) {
+	return r.svc.ScanExplained(rootPath, profile, app.ScanOptions{
+		MaxFileBytes: opts.MaxFileBytes,
+		IgnoreNames:  opts.IgnoreNames,
+		IgnoreGlobs:  opts.IgnoreGlobs,
+		IncludeExts:  opts.IncludeExts,
+		IncludeGlobs: opts.IncludeGlobs,
+		ExcludeExts:  opts.ExcludeExts,
+
+		IncludeSecretFiles: opts.IncludeSecretFiles,
 	})
 }
 
 func (r *repoAPI) ReadFileRel(rootPath string, relPath string, maxBytes int64) ([]byte, error) {
 	return r.svc.ReadFileRel(rootPath, relPath, maxBytes)
 }
+
+func (r *repoAPI) ReadTextRel(rootPath string, relPath string, maxBytes int64) ([]byte, string, error) {
+	return r.svc.ReadTextRel(rootPath, relPath, maxBytes)
+}
+
+func (r *repoAPI) ReadTextFileRel(rootPath string, relPath string, maxBytes int64) (TextFile, error) {
+	return r.svc.ReadTextFileRel(rootPath, relPath, maxBytes)
+}
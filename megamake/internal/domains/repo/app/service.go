@@ -1,8 +1,13 @@
 package app
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"path/filepath"
 
+	"github.com/megamake/megamake/internal/domains/repo/domain"
+	"github.com/megamake/megamake/internal/platform/errors"
+
 	"github.com/megamake/megamake/internal/contracts/v1/project"
 	"github.com/megamake/megamake/internal/domains/repo/ports"
 )
@@ -29,15 +34,41 @@ type ScanOptions struct {
 	MaxFileBytes int64
 	IgnoreNames  []string
 	IgnoreGlobs  []string
+
+	// IncludeExts, IncludeGlobs and ExcludeExts override the language-aware include rules.
+	IncludeExts  []string
+	IncludeGlobs []string
+	ExcludeExts  []string
+
+	// IncludeSecretFiles keeps the credential-bearing files (.env*, .npmrc, *.pem, ...) that
+	// are otherwise excluded so they never reach a prompt. Only the secret scan sets it.
+	IncludeSecretFiles bool
 }
 
 func (s *Service) Scan(rootPath string, profile project.ProjectProfileV1, opts ScanOptions) ([]project.FileRefV1, error) {
+	res, err := s.scan(rootPath, profile, opts, false)
+	return res.Files, err
+}
+
+// ScanExplained scans like Scan and also reports the paths excluded by ignore files.
+func (s *Service) ScanExplained(rootPath string, profile project.ProjectProfileV1, opts ScanOptions) ([]project.FileRefV1, []project.IgnoredPathV1, error) {
+	res, err := s.scan(rootPath, profile, opts, true)
+	return res.Files, res.Ignored, err
+}
+
+func (s *Service) scan(rootPath string, profile project.ProjectProfileV1, opts ScanOptions, explain bool) (ports.ScanResult, error) {
 	return s.scn.Scan(ports.ScanRequest{
-		RootPath:     rootPath,
-		Profile:      profile,
-		MaxFileBytes: opts.MaxFileBytes,
-		IgnoreNames:  opts.IgnoreNames,
-		IgnoreGlobs:  opts.IgnoreGlobs,
+		RootPath:       rootPath,
+		Profile:        profile,
+		MaxFileBytes:   opts.MaxFileBytes,
+		IgnoreNames:    opts.IgnoreNames,
+		IgnoreGlobs:    opts.IgnoreGlobs,
+		IncludeExts:    opts.IncludeExts,
+		IncludeGlobs:   opts.IncludeGlobs,
+		ExcludeExts:    opts.ExcludeExts,
+		ExplainIgnores: explain,
+
+		IncludeSecretFiles: opts.IncludeSecretFiles,
 	})
 }
 
@@ -46,3 +77,37 @@ func (s *Service) ReadFileRel(rootPath string, relPath string, maxBytes int64) (
 	abs := filepath.Join(rootPath, filepath.FromSlash(relPath))
 	return s.rdr.ReadFile(abs, maxBytes)
 }
+
+// ReadTextRel reads a file as UTF-8 text. UTF-16/32 and legacy single-byte content is
+// transcoded, a UTF-8 BOM stripped, and the source encoding returned ("" for plain UTF-8).
+// Binary content is rejected with an error.
+func (s *Service) ReadTextRel(rootPath string, relPath string, maxBytes int64) ([]byte, string, error) {
+	tf, err := s.ReadTextFileRel(rootPath, relPath, maxBytes)
+	return tf.Text, tf.Encoding, err
+}
+
+// TextFile is a file read as UTF-8 text, with the digest of the bytes on disk so a caller
+// can record exactly what it read.
+type TextFile struct {
+	Text     []byte
+	Encoding string // source encoding ("" for plain UTF-8)
+	SHA256   string // hex SHA-256 of the bytes on disk, before transcoding
+	Bytes    int64  // size on disk
+}
+
+// ReadTextFileRel reads a file like ReadTextRel and hashes the bytes it read.
+func (s *Service) ReadTextFileRel(rootPath string, relPath string, maxBytes int64) (TextFile, error) {
+	b, err := s.ReadFileRel(rootPath, relPath, maxBytes)
+	if err != nil {
+		return TextFile{}, err
+	}
+	sum := sha256.Sum256(b)
+	text, enc, ok := domain.DecodeText(b)
+	if !ok {
+		return TextFile{}, errors.New(errors.KindIO, "binary content (NUL bytes or high entropy)", nil)
+	}
+	if enc == domain.EncodingUTF8 {
+		enc = ""
+	}
+	return TextFile{Text: text, Encoding: enc, SHA256: hex.EncodeToString(sum[:]), Bytes: int64(len(b))}, nil
+}
@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Source encodings reported by DecodeText.
+const (
+	EncodingUTF8        = "utf-8"
+	EncodingUTF8BOM     = "utf-8-bom"
+	EncodingUTF16LE     = "utf-16le"
+	EncodingUTF16BE     = "utf-16be"
+	EncodingUTF32LE     = "utf-32le"
+	EncodingUTF32BE     = "utf-32be"
+	EncodingWindows1252 = "windows-1252"
+	EncodingLatin1      = "iso-8859-1"
+)
+
+const (
+	// sniffBytes bounds the prefix used for the NUL-byte and BOM-less UTF-16 checks.
+	sniffBytes = 8000
+	// binaryEntropy is the Shannon entropy (bits/byte) above which content is treated as
+	// compressed or encrypted. Source text, even minified, stays well below it.
+	binaryEntropy = 7.2
+	// minEntropySample avoids judging tiny files by entropy.
+	minEntropySample = 1024
+)
+
+// DecodeText returns content as UTF-8 along with its source encoding, transcoding
+// UTF-16/UTF-32 (with a BOM, or BOM-less UTF-16 recognized by its zero-byte pattern) and
+// legacy single-byte text (Windows-1252, or ISO-8859-1 when no 0x80-0x9F byte is used).
+// A UTF-8 BOM is stripped. ok is false for binary content: NUL bytes outside a UTF-16/32
+// encoding, or non-UTF-8 bytes with the entropy of compressed or encrypted data.
+func DecodeText(raw []byte) (text []byte, encoding string, ok bool) {
+	switch {
+	case bytes.HasPrefix(raw, []byte{0xFF, 0xFE, 0x00, 0x00}):
+		return decodeUTF32(raw[4:], binary.LittleEndian), EncodingUTF32LE, true
+	case bytes.HasPrefix(raw, []byte{0x00, 0x00, 0xFE, 0xFF}):
+		return decodeUTF32(raw[4:], binary.BigEndian), EncodingUTF32BE, true
+	case bytes.HasPrefix(raw, []byte{0xEF, 0xBB, 0xBF}):
+		raw = raw[3:]
+		if !utf8.Valid(raw) || hasNUL(raw) {
+			return nil, "", false
+		}
+		return raw, EncodingUTF8BOM, true
+	case bytes.HasPrefix(raw, []byte{0xFF, 0xFE}):
+		return decodeUTF16(raw[2:], binary.LittleEndian), EncodingUTF16LE, true
+	case bytes.HasPrefix(raw, []byte{0xFE, 0xFF}):
+		return decodeUTF16(raw[2:], binary.BigEndian), EncodingUTF16BE, true
+	}
+
+	if order, enc, found := sniffUTF16(raw); found {
+		return decodeUTF16(raw, order), enc, true
+	}
+	if hasNUL(raw) {
+		return nil, "", false
+	}
+	if utf8.Valid(raw) {
+		return raw, EncodingUTF8, true
+	}
+	// Compressed or encrypted data is never valid UTF-8 and has near-maximal entropy;
+	// legacy single-byte text stays far below it.
+	if len(raw) >= minEntropySample && shannonEntropy(raw) > binaryEntropy {
+		return nil, "", false
+	}
+	return decodeSingleByte(raw)
+}
+
+// hasNUL reports a NUL byte in the first sniffBytes, git's test for binary content.
+func hasNUL(b []byte) bool {
+	if len(b) > sniffBytes {
+		b = b[:sniffBytes]
+	}
+	return bytes.IndexByte(b, 0) >= 0
+}
+
+func shannonEntropy(b []byte) float64 {
+	var counts [256]int
+	for _, c := range b {
+		counts[c]++
+	}
+	n := float64(len(b))
+	h := 0.0
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// sniffUTF16 recognizes BOM-less UTF-16 text: mostly-ASCII content has a zero in every
+// other byte, on the odd offsets for little endian and the even ones for big endian.
+func sniffUTF16(b []byte) (binary.ByteOrder, string, bool) {
+	head := b
+	if len(head) > sniffBytes {
+		head = head[:sniffBytes]
+	}
+	if len(head) < 4 {
+		return nil, "", false
+	}
+	pairs := len(head) / 2
+	evenZero, oddZero := 0, 0
+	for i := 0; i+1 < len(head); i += 2 {
+		if head[i] == 0 {
+			evenZero++
+		}
+		if head[i+1] == 0 {
+			oddZero++
+		}
+	}
+	switch {
+	case oddZero*10 >= pairs*7 && evenZero*20 <= pairs:
+		return binary.LittleEndian, EncodingUTF16LE, true
+	case evenZero*10 >= pairs*7 && oddZero*20 <= pairs:
+		return binary.BigEndian, EncodingUTF16BE, true
+	}
+	return nil, "", false
+}
+
+func decodeUTF16(b []byte, order binary.ByteOrder) []byte {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, order.Uint16(b[i:]))
+	}
+	return []byte(string(utf16.Decode(units)))
+}
+
+func decodeUTF32(b []byte, order binary.ByteOrder) []byte {
+	var out bytes.Buffer
+	out.Grow(len(b) / 4)
+	for i := 0; i+3 < len(b); i += 4 {
+		r := rune(order.Uint32(b[i:]))
+		if !utf8.ValidRune(r) {
+			r = utf8.RuneError
+		}
+		out.WriteRune(r)
+	}
+	return out.Bytes()
+}
+
+// windows1252High maps bytes 0x80-0x9F; the five unassigned ones keep their Latin-1
+// (C1 control) meaning.
+var windows1252High = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+func decodeSingleByte(b []byte) ([]byte, string, bool) {
+	enc := EncodingLatin1
+	var out bytes.Buffer
+	out.Grow(len(b) + len(b)/8)
+	for _, c := range b {
+		switch {
+		case c < 0x80:
+			out.WriteByte(c)
+		case c < 0xA0:
+			enc = EncodingWindows1252
+			out.WriteRune(windows1252High[c-0x80])
+		default:
+			out.WriteRune(rune(c))
+		}
+	}
+	return out.Bytes(), enc, true
+}
@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// IgnoreFileNames are read from every scanned directory, in this order. Within a directory
+// .megamakeignore rules come last, so they can override (or re-include) .gitignore rules.
+var IgnoreFileNames = []string{".gitignore", ".megamakeignore"}
+
+// IgnoreRule is one pattern line of an ignore file, with gitignore semantics.
+type IgnoreRule struct {
+	Source  string // POSIX relpath of the ignore file, e.g. "web/.gitignore"
+	Line    int
+	Pattern string // the line as written
+
+	base    string // directory of the ignore file ("" for the root)
+	above   string // for ignore files above the root: the root's path from their directory, plus "/"
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// Origin renders "source:line pattern", as shown by --explain-ignores.
+func (r IgnoreRule) Origin() string {
+	return r.Source + ":" + strconv.Itoa(r.Line) + " " + r.Pattern
+}
+
+// ParseIgnoreFileAbove parses an ignore file from an ancestor of the scan root (within the
+// same git repository). rootFromDir is the root's POSIX path relative to that ancestor.
+func ParseIgnoreFileAbove(rootFromDir string, source string, content string) []IgnoreRule {
+	rules := ParseIgnoreFile("", source, content)
+	for i := range rules {
+		rules[i].above = strings.Trim(rootFromDir, "/") + "/"
+	}
+	return rules
+}
+
+// ParseIgnoreFile parses gitignore-format content found in directory base (a POSIX relpath,
+// "" for the root). Blank lines and comments are skipped; invalid patterns are dropped.
+func ParseIgnoreFile(base string, source string, content string) []IgnoreRule {
+	var rules []IgnoreRule
+	for i, raw := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		r, ok := parseIgnoreLine(raw)
+		if !ok {
+			continue
+		}
+		r.Source = source
+		r.Line = i + 1
+		r.Pattern = strings.TrimSpace(raw)
+		r.base = strings.Trim(base, "/")
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+func parseIgnoreLine(line string) (IgnoreRule, bool) {
+	// Trailing spaces are ignored unless escaped with a backslash.
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return IgnoreRule{}, false
+	}
+
+	var r IgnoreRule
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return IgnoreRule{}, false
+	}
+
+	// A slash at the start or in the middle anchors the pattern to the ignore file's
+	// directory; otherwise it matches a name at any depth below it.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := ignoreGlobToRegex(line)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return IgnoreRule{}, false
+	}
+	r.re = re
+	return r, true
+}
+
+// ignoreGlobToRegex translates gitignore wildcards: "*" and "?" stay within a path segment,
+// "**/" matches zero or more directories, a trailing "/**" everything inside, and
+// "[...]" character classes ("[!...]" negated) are kept.
+func ignoreGlobToRegex(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case c == '\\' && i+1 < len(p):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(p[i])))
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**") && i+2 == len(p):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(p[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := p[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// match reports whether the rule's pattern matches rel (a POSIX relpath from the scan root).
+func (r IgnoreRule) match(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	sub := r.above + rel
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		sub = rel[len(r.base)+1:]
+	}
+	return r.re.MatchString(sub)
+}
+
+// IgnoreMatcher evaluates ignore rules collected while walking a tree.
+// Rules must be added parents first; like git, the last matching rule wins, so deeper
+// ignore files override their parents and "!" re-includes what an earlier rule excluded.
+// Paths inside an excluded directory cannot be re-included because the walk never
+// enters that directory.
+type IgnoreMatcher struct {
+	rules []IgnoreRule
+}
+
+// Add appends rules (typically one ignore file's).
+func (m *IgnoreMatcher) Add(rules []IgnoreRule) {
+	m.rules = append(m.rules, rules...)
+}
+
+// Clone returns a matcher with the same rules that can be extended independently (one per
+// directory when subtrees are walked concurrently).
+func (m *IgnoreMatcher) Clone() IgnoreMatcher {
+	return IgnoreMatcher{rules: append([]IgnoreRule(nil), m.rules...)}
+}
+
+// Match reports whether rel is ignored and by which rule. A nil rule means no rule matched;
+// a non-nil rule with ignored == false is a negation that re-included the path.
+func (m *IgnoreMatcher) Match(rel string, isDir bool) (ignored bool, rule *IgnoreRule) {
+	for i := len(m.rules) - 1; i >= 0; i-- {
+		if m.rules[i].match(rel, isDir) {
+			return !m.rules[i].negate, &m.rules[i]
+		}
+	}
+	return false, nil
+}
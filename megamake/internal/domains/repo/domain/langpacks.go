@@ -0,0 +1,249 @@
+package domain
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LanguagePack is everything megamake knows about one language: which files belong to it,
+// which files prove a project uses it, how its tests are named and how it imports code.
+// The detector, the scanner's include rules, IsTestFile and the doc import graph all read
+// the registry, so supporting a language means adding one pack.
+type LanguagePack struct {
+	Name string
+	// Extensions are lowercase, with the dot (".go"). The first pack claiming an extension
+	// owns it in LanguageForExt.
+	Extensions []string
+	// Markers are root-relative file names or globs ("go.mod", "*.csproj").
+	Markers []string
+	// TestGlobs match base names of test files beyond the generic conventions in IsTestFile
+	// (e.g. "*Test.java").
+	TestGlobs []string
+	// Imports extract import specifiers from a file's content.
+	Imports []ImportPattern
+}
+
+// ImportPattern finds imports with Re; each match's first group is one import specifier.
+// With Inner set, the group is a block (Go's "import ( ... )") and every first group of
+// Inner within it is a specifier. Fields splits the group on whitespace (Lean's
+// "import A B").
+type ImportPattern struct {
+	Re     *regexp.Regexp
+	Inner  *regexp.Regexp
+	Fields bool
+}
+
+var jsImports = []ImportPattern{
+	{Re: regexp.MustCompile(`(?m)^\s*import\s+(?:[^'"]*\s+from\s+)?['"]([^'"]+)['"]`)},
+	{Re: regexp.MustCompile(`(?m)require\(\s*['"]([^'"]+)['"]\s*\)`)},
+	{Re: regexp.MustCompile(`(?m)import\(\s*['"]([^'"]+)['"]\s*\)`)},
+}
+
+// languagePacks is the built-in registry, in precedence order.
+var languagePacks = []LanguagePack{
+	{
+		Name:       "typescript",
+		Extensions: []string{".ts", ".tsx", ".mts", ".cts"},
+		Markers:    []string{"tsconfig.json"},
+		Imports:    jsImports,
+	},
+	{
+		Name:       "javascript",
+		Extensions: []string{".js", ".jsx", ".mjs", ".cjs"},
+		Markers:    []string{"package.json"},
+		Imports:    jsImports,
+	},
+	{
+		Name:       "vue",
+		Extensions: []string{".vue"},
+		Imports:    jsImports,
+	},
+	{
+		Name:       "svelte",
+		Extensions: []string{".svelte"},
+		Markers:    []string{"svelte.config.js"},
+		Imports:    jsImports,
+	},
+	{
+		Name:       "python",
+		Extensions: []string{".py"},
+		Markers:    []string{"pyproject.toml", "requirements.txt", "Pipfile", "setup.py", "setup.cfg", "tox.ini"},
+		Imports: []ImportPattern{
+			{Re: regexp.MustCompile(`(?m)^\s*import\s+([A-Za-z0-9_\.]+)`)},
+			{Re: regexp.MustCompile(`(?m)^\s*from\s+([A-Za-z0-9_\.]+)\s+import\s+`)},
+		},
+	},
+	{
+		Name:       "go",
+		Extensions: []string{".go"},
+		Markers:    []string{"go.mod"},
+		Imports: []ImportPattern{
+			{Re: regexp.MustCompile(`(?m)^\s*import\s+"([^"]+)"`)},
+			{Re: regexp.MustCompile(`(?s)import\s*\(\s*([^\)]+)\s*\)`), Inner: regexp.MustCompile(`(?m)"([^"]+)"`)},
+		},
+	},
+	{
+		Name:       "rust",
+		Extensions: []string{".rs"},
+		Markers:    []string{"Cargo.toml"},
+		Imports:    []ImportPattern{{Re: regexp.MustCompile(`(?m)^\s*use\s+([A-Za-z0-9_:]+)`)}},
+	},
+	{
+		Name:       "java",
+		Extensions: []string{".java"},
+		Markers:    []string{"pom.xml", "build.gradle", "build.gradle.kts", "settings.gradle", "settings.gradle.kts"},
+		TestGlobs:  []string{"*Test.java", "*Tests.java", "*IT.java"},
+		Imports:    []ImportPattern{{Re: regexp.MustCompile(`(?m)^\s*import\s+([A-Za-z0-9_\.]+)`)}},
+	},
+	{
+		Name:       "kotlin",
+		Extensions: []string{".kt", ".kts"},
+		Markers:    []string{"build.gradle.kts"},
+		TestGlobs:  []string{"*Test.kt", "*Tests.kt"},
+		Imports:    []ImportPattern{{Re: regexp.MustCompile(`(?m)^\s*import\s+([A-Za-z0-9_\.]+)`)}},
+	},
+	{
+		Name:       "csharp",
+		Extensions: []string{".cs"},
+		Markers:    []string{"*.sln", "*.csproj"},
+		TestGlobs:  []string{"*Tests.cs", "*Test.cs"},
+	},
+	{
+		Name:       "cpp",
+		Extensions: []string{".c", ".cc", ".cpp", ".cxx", ".h", ".hpp", ".hh"},
+		Markers:    []string{"CMakeLists.txt"},
+	},
+	{
+		Name:       "php",
+		Extensions: []string{".php"},
+		Markers:    []string{"composer.json"},
+		TestGlobs:  []string{"*Test.php"},
+	},
+	{
+		Name:       "ruby",
+		Extensions: []string{".rb"},
+		Markers:    []string{"Gemfile"},
+	},
+	{
+		Name:       "swift",
+		Extensions: []string{".swift"},
+		Markers:    []string{"Package.swift", "*.xcodeproj"},
+		TestGlobs:  []string{"*Tests.swift"},
+		Imports:    []ImportPattern{{Re: regexp.MustCompile(`(?m)^\s*import\s+([A-Za-z0-9_]+)`)}},
+	},
+	{
+		Name:       "dart",
+		Extensions: []string{".dart"},
+		Markers:    []string{"pubspec.yaml"},
+		Imports:    []ImportPattern{{Re: regexp.MustCompile(`(?m)^\s*(?:import|export)\s+['"]([^'"]+)['"]`)}},
+	},
+	{
+		Name:       "zig",
+		Extensions: []string{".zig"},
+		Markers:    []string{"build.zig"},
+		Imports:    []ImportPattern{{Re: regexp.MustCompile(`@import\(\s*"([^"]+)"\s*\)`)}},
+	},
+	{
+		Name:       "protobuf",
+		Extensions: []string{".proto"},
+		Markers:    []string{"buf.yaml"},
+		Imports:    []ImportPattern{{Re: regexp.MustCompile(`(?m)^\s*import\s+(?:public\s+|weak\s+)?"([^"]+)"`)}},
+	},
+	{
+		Name:       "terraform",
+		Extensions: []string{".tf", ".tfvars"},
+		Markers:    []string{"*.tf"},
+	},
+	{
+		Name:    "docker",
+		Markers: []string{"Dockerfile"},
+	},
+	{
+		Name:       "lean",
+		Extensions: []string{".lean"},
+		Markers:    []string{"lakefile.lean", "lean-toolchain", "*.lean"},
+		Imports:    []ImportPattern{{Re: regexp.MustCompile(`(?m)^\s*import\s+(.+)$`), Fields: true}},
+	},
+	{
+		Name:       "latex",
+		Extensions: []string{".tex", ".cls", ".sty", ".bib"},
+		Markers:    []string{"latexmkrc", "*.tex"},
+	},
+}
+
+// LanguagePacks returns the registry in precedence order.
+func LanguagePacks() []LanguagePack {
+	return languagePacks
+}
+
+// LanguagePackByName returns the pack with the given name.
+func LanguagePackByName(name string) (LanguagePack, bool) {
+	for _, p := range languagePacks {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return LanguagePack{}, false
+}
+
+// LanguageForExt returns the language owning a lowercase extension ("" if none).
+func LanguageForExt(ext string) string {
+	for _, p := range languagePacks {
+		for _, e := range p.Extensions {
+			if e == ext {
+				return p.Name
+			}
+		}
+	}
+	return ""
+}
+
+// LanguageForRel returns the language of a relpath by its extension ("" if none).
+func LanguageForRel(rel string) string {
+	return LanguageForExt(strings.ToLower(path.Ext(rel)))
+}
+
+// SourceExtensions returns every registered extension, sorted.
+func SourceExtensions() []string {
+	var out []string
+	for _, p := range languagePacks {
+		out = append(out, p.Extensions...)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ParseImports returns the import specifiers found in content by the pack's patterns,
+// in order of appearance per pattern.
+func (p LanguagePack) ParseImports(content string) []string {
+	var out []string
+	add := func(s string) {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	for _, ip := range p.Imports {
+		for _, m := range ip.Re.FindAllStringSubmatch(content, -1) {
+			if len(m) < 2 {
+				continue
+			}
+			switch {
+			case ip.Inner != nil:
+				for _, im := range ip.Inner.FindAllStringSubmatch(m[1], -1) {
+					if len(im) >= 2 {
+						add(im[1])
+					}
+				}
+			case ip.Fields:
+				for _, f := range strings.Fields(m[1]) {
+					add(f)
+				}
+			default:
+				add(m[1])
+			}
+		}
+	}
+	return out
+}
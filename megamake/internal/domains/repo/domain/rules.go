@@ -1,5 +1,7 @@
 package domain
 
+import "strings"
+
 // IncludeRules defines what the repo scanner includes/excludes.
 type IncludeRules struct {
 	AllowedExts       map[string]bool
@@ -14,21 +16,11 @@ type IncludeRules struct {
 // It is intentionally close to your Swift RulesFactory, with conservative defaults.
 func BuildRules(languages map[string]bool) IncludeRules {
 	allowed := map[string]bool{
-		// source
-		".ts": true, ".tsx": true, ".js": true, ".jsx": true, ".mjs": true, ".cjs": true,
-		".py": true, ".go": true, ".rs": true, ".java": true, ".kt": true, ".kts": true,
-		".c": true, ".cc": true, ".cpp": true, ".cxx": true, ".h": true, ".hpp": true, ".hh": true, ".cs": true,
-		".php": true, ".rb": true, ".swift": true,
-		".lean": true,
-
-		// configs/docs
+		// configs/docs (source extensions come from the language packs below)
 		".yml": true, ".yaml": true, ".json": true, ".toml": true, ".ini": true, ".cfg": true, ".conf": true,
 		".md": true, ".xml": true, ".sql": true, ".graphql": true, ".gql": true,
 		".sh": true, ".bash": true, ".zsh": true,
 		".html": true, ".css": true, ".scss": true, ".sass": true, ".less": true,
-
-		// latex
-		".tex": true, ".cls": true, ".sty": true, ".bib": true,
 	}
 
 	excludeNames := map[string]bool{
@@ -129,6 +121,18 @@ func BuildRules(languages map[string]bool) IncludeRules {
 		".direnv":  true,
 	}
 
+	// Every language pack contributes its extensions and its literal marker names.
+	for _, pack := range languagePacks {
+		for _, ext := range pack.Extensions {
+			allowed[ext] = true
+		}
+		for _, m := range pack.Markers {
+			if !strings.ContainsAny(m, "*?[") {
+				forceNames[strings.ToLower(m)] = true
+			}
+		}
+	}
+
 	// Prefer TypeScript over JS/JSX when TypeScript present.
 	if languages["typescript"] {
 		delete(allowed, ".js")
@@ -144,3 +148,77 @@ func BuildRules(languages map[string]bool) IncludeRules {
 		ExcludeExts:       excludeExts,
 	}
 }
+
+// secretFileNames are dotfiles that commonly hold credentials.
+var secretFileNames = map[string]bool{
+	".npmrc": true, ".pypirc": true, ".netrc": true, ".pgpass": true, ".git-credentials": true,
+}
+
+// secretFileExts are key material the scanner excludes by default.
+var secretFileExts = map[string]bool{
+	".pem": true, ".key": true,
+}
+
+// IsSecretFile reports whether a base name (lowercased) is a dotenv file (.env, .env.local,
+// prod.env) or another file that typically holds credentials. The scanner leaves these out
+// of prompts; the secret scan asks for them with IncludeSecretFiles.
+func IsSecretFile(baseLower string) bool {
+	if baseLower == ".env" || strings.HasPrefix(baseLower, ".env.") || strings.HasSuffix(baseLower, ".env") {
+		return true
+	}
+	if secretFileNames[baseLower] {
+		return true
+	}
+	i := strings.LastIndex(baseLower, ".")
+	return i > 0 && secretFileExts[baseLower[i:]]
+}
+
+// WithOverrides applies user include/exclude flags on top of the language-aware defaults.
+// includeExts are allowed even when a default excludes them; excludeExts win over both the
+// defaults and includeExts; includeGlobs force-include matching relpaths like the CI globs.
+// Extensions are accepted with or without the leading dot and in any case.
+func (r IncludeRules) WithOverrides(includeExts []string, includeGlobs []string, excludeExts []string) IncludeRules {
+	if len(includeExts) == 0 && len(includeGlobs) == 0 && len(excludeExts) == 0 {
+		return r
+	}
+	allowed := copySet(r.AllowedExts)
+	excluded := copySet(r.ExcludeExts)
+	for _, e := range includeExts {
+		if e = NormalizeExt(e); e != "" {
+			allowed[e] = true
+			delete(excluded, e)
+		}
+	}
+	for _, e := range excludeExts {
+		if e = NormalizeExt(e); e != "" {
+			delete(allowed, e)
+			excluded[e] = true
+		}
+	}
+	r.AllowedExts = allowed
+	r.ExcludeExts = excluded
+	if len(includeGlobs) > 0 {
+		r.ForceIncludeGlobs = append(append([]string(nil), r.ForceIncludeGlobs...), includeGlobs...)
+	}
+	return r
+}
+
+// NormalizeExt returns ext lowercased with a leading dot ("TF" -> ".tf"), or "" if empty.
+func NormalizeExt(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext == "" || ext == "." {
+		return ""
+	}
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+func copySet(m map[string]bool) map[string]bool {
+	out := make(map[string]bool, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
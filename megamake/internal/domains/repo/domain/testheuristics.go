@@ -1,15 +1,18 @@
 package domain
 
 import (
+	"path"
 	"path/filepath"
 	"strings"
 )
 
-// IsTestFile returns true if a path matches common test naming conventions.
+// IsTestFile returns true if a path matches common test naming conventions, or the test
+// globs of the language pack owning its extension (e.g. Java's "*Test.java").
 // It is applied to both base names and directories to mimic the Swift behavior.
 func IsTestFile(relOrPath string) bool {
 	p := filepath.ToSlash(relOrPath)
-	base := strings.ToLower(filepath.Base(p))
+	rawBase := path.Base(p)
+	base := strings.ToLower(rawBase)
 
 	if strings.Contains(base, ".test.") ||
 		strings.Contains(base, ".spec.") ||
@@ -20,6 +23,15 @@ func IsTestFile(relOrPath string) bool {
 		return true
 	}
 
+	if lang := LanguageForExt(strings.ToLower(path.Ext(rawBase))); lang != "" {
+		pack, _ := LanguagePackByName(lang)
+		for _, g := range pack.TestGlobs {
+			if ok, _ := path.Match(g, rawBase); ok {
+				return true
+			}
+		}
+	}
+
 	parts := strings.Split(p, "/")
 	for _, part := range parts {
 		x := strings.ToLower(strings.TrimSpace(part))
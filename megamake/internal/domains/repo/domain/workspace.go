@@ -0,0 +1,314 @@
+package domain
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// WorkspaceKind names the monorepo tooling that declared a workspace.
+type WorkspaceKind string
+
+const (
+	WorkspaceGo     WorkspaceKind = "go"     // go.work "use" directives
+	WorkspaceNPM    WorkspaceKind = "npm"    // package.json "workspaces" (npm, yarn)
+	WorkspacePNPM   WorkspaceKind = "pnpm"   // pnpm-workspace.yaml "packages"
+	WorkspaceCargo  WorkspaceKind = "cargo"  // Cargo.toml [workspace] members
+	WorkspaceGradle WorkspaceKind = "gradle" // settings.gradle(.kts) include(...)
+)
+
+// WorkspaceSpec is what a workspace file declares: member directory patterns relative to
+// the workspace root (globs allowed for npm, pnpm and cargo) and patterns to leave out.
+type WorkspaceSpec struct {
+	Kind     WorkspaceKind
+	File     string // the workspace file, e.g. "go.work"
+	Members  []string
+	Excludes []string
+	// Manifest is the file a member directory must contain ("" = any directory).
+	Manifest string
+}
+
+// WorkspaceFiles are checked at the root in this order.
+var WorkspaceFiles = []string{"go.work", "pnpm-workspace.yaml", "package.json", "Cargo.toml", "settings.gradle", "settings.gradle.kts"}
+
+// ParseWorkspaceFile extracts the workspace declaration from one of WorkspaceFiles.
+// ok is false when the file declares no workspace (e.g. a package.json without "workspaces").
+func ParseWorkspaceFile(name string, content string) (WorkspaceSpec, bool) {
+	var spec WorkspaceSpec
+	switch name {
+	case "go.work":
+		spec = WorkspaceSpec{Kind: WorkspaceGo, Members: ParseGoWorkUses(content), Manifest: "go.mod"}
+	case "pnpm-workspace.yaml":
+		inc, exc := splitNegated(ParsePnpmWorkspacePackages(content))
+		spec = WorkspaceSpec{Kind: WorkspacePNPM, Members: inc, Excludes: exc, Manifest: "package.json"}
+	case "package.json":
+		inc, exc := splitNegated(ParsePackageJSONWorkspaces(content))
+		spec = WorkspaceSpec{Kind: WorkspaceNPM, Members: inc, Excludes: exc, Manifest: "package.json"}
+	case "Cargo.toml":
+		members, excludes := ParseCargoWorkspace(content)
+		spec = WorkspaceSpec{Kind: WorkspaceCargo, Members: members, Excludes: excludes, Manifest: "Cargo.toml"}
+	case "settings.gradle", "settings.gradle.kts":
+		spec = WorkspaceSpec{Kind: WorkspaceGradle, Members: ParseGradleIncludes(content)}
+	default:
+		return WorkspaceSpec{}, false
+	}
+	spec.File = name
+	return spec, len(spec.Members) > 0
+}
+
+func splitNegated(patterns []string) (include []string, exclude []string) {
+	for _, p := range patterns {
+		if strings.HasPrefix(p, "!") {
+			exclude = append(exclude, strings.TrimPrefix(p, "!"))
+		} else {
+			include = append(include, p)
+		}
+	}
+	return include, exclude
+}
+
+// ParseGoWorkUses returns the directories of a go.work's "use" directives,
+// both the single-line and the parenthesized block form.
+func ParseGoWorkUses(content string) []string {
+	var out []string
+	inBlock := false
+	for _, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(stripLineComment(raw, "//"))
+		switch {
+		case inBlock:
+			if line == ")" {
+				inBlock = false
+			} else if line != "" {
+				out = append(out, cleanMemberPath(unquote(line)))
+			}
+		case line == "use (" || line == "use(":
+			inBlock = true
+		case strings.HasPrefix(line, "use "):
+			out = append(out, cleanMemberPath(unquote(strings.TrimSpace(line[4:]))))
+		}
+	}
+	return out
+}
+
+// ParsePackageJSONWorkspaces returns package.json "workspaces", given either as an array
+// or as {"packages": [...]} (yarn).
+func ParsePackageJSONWorkspaces(content string) []string {
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if json.Unmarshal([]byte(content), &pkg) != nil || len(pkg.Workspaces) == 0 {
+		return nil
+	}
+	var list []string
+	if json.Unmarshal(pkg.Workspaces, &list) == nil {
+		return cleanMemberPaths(list)
+	}
+	var obj struct {
+		Packages []string `json:"packages"`
+	}
+	if json.Unmarshal(pkg.Workspaces, &obj) == nil {
+		return cleanMemberPaths(obj.Packages)
+	}
+	return nil
+}
+
+// ParsePnpmWorkspacePackages returns the "packages:" list of a pnpm-workspace.yaml,
+// in block ("- 'apps/*'") or flow ("[a, b]") style.
+func ParsePnpmWorkspacePackages(content string) []string {
+	var out []string
+	inList := false
+	for _, raw := range strings.Split(content, "\n") {
+		line := stripLineComment(raw, "#")
+		t := strings.TrimSpace(line)
+		if t == "" {
+			continue
+		}
+		if strings.HasPrefix(t, "packages:") {
+			rest := strings.TrimSpace(strings.TrimPrefix(t, "packages:"))
+			if strings.HasPrefix(rest, "[") {
+				for _, item := range strings.Split(strings.Trim(rest, "[]"), ",") {
+					if item = unquote(strings.TrimSpace(item)); item != "" {
+						out = append(out, item)
+					}
+				}
+				return cleanMemberPaths(out)
+			}
+			inList = true
+			continue
+		}
+		if !inList {
+			continue
+		}
+		if !strings.HasPrefix(t, "-") {
+			// A new top-level key ends the list.
+			if line == strings.TrimLeft(line, " \t") {
+				break
+			}
+			continue
+		}
+		if item := unquote(strings.TrimSpace(t[1:])); item != "" {
+			out = append(out, item)
+		}
+	}
+	return cleanMemberPaths(out)
+}
+
+// ParseCargoWorkspace returns the members and exclude arrays of a Cargo.toml [workspace] table.
+func ParseCargoWorkspace(content string) (members []string, excludes []string) {
+	inWorkspace := false
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		t := strings.TrimSpace(stripLineComment(lines[i], "#"))
+		if strings.HasPrefix(t, "[") {
+			inWorkspace = t == "[workspace]"
+			continue
+		}
+		if !inWorkspace {
+			continue
+		}
+		eq := strings.Index(t, "=")
+		if eq < 0 {
+			continue
+		}
+		key := strings.TrimSpace(t[:eq])
+		if key != "members" && key != "exclude" {
+			continue
+		}
+		val := strings.TrimSpace(t[eq+1:])
+		for strings.Count(val, "[") > strings.Count(val, "]") && i+1 < len(lines) {
+			i++
+			val += " " + strings.TrimSpace(stripLineComment(lines[i], "#"))
+		}
+		items := tomlStringArray(val)
+		if key == "members" {
+			members = append(members, items...)
+		} else {
+			excludes = append(excludes, items...)
+		}
+	}
+	return cleanMemberPaths(members), cleanMemberPaths(excludes)
+}
+
+var (
+	gradleIncludeRe = regexp.MustCompile(`^include\b\s*\(?(.*?)\)?\s*$`)
+	gradleQuotedRe  = regexp.MustCompile(`["']([^"']+)["']`)
+)
+
+// ParseGradleIncludes returns the project directories of settings.gradle(.kts) include
+// statements: ":libs:core" becomes "libs/core".
+func ParseGradleIncludes(content string) []string {
+	var out []string
+	for _, raw := range strings.Split(content, "\n") {
+		t := strings.TrimSpace(stripLineComment(raw, "//"))
+		m := gradleIncludeRe.FindStringSubmatch(t)
+		if m == nil {
+			continue
+		}
+		for _, q := range gradleQuotedRe.FindAllStringSubmatch(m[1], -1) {
+			p := strings.Trim(q[1], ":")
+			if p != "" {
+				out = append(out, strings.ReplaceAll(p, ":", "/"))
+			}
+		}
+	}
+	return out
+}
+
+// MemberName returns the package name declared by a member's manifest
+// (go.mod module, package.json name, Cargo.toml [package] name), or "".
+func MemberName(manifest string, content string) string {
+	switch manifest {
+	case "go.mod":
+		for _, raw := range strings.Split(content, "\n") {
+			t := strings.TrimSpace(stripLineComment(raw, "//"))
+			if strings.HasPrefix(t, "module ") {
+				return unquote(strings.TrimSpace(t[len("module "):]))
+			}
+		}
+	case "package.json":
+		var pkg struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal([]byte(content), &pkg) == nil {
+			return strings.TrimSpace(pkg.Name)
+		}
+	case "Cargo.toml":
+		inPackage := false
+		for _, raw := range strings.Split(content, "\n") {
+			t := strings.TrimSpace(stripLineComment(raw, "#"))
+			if strings.HasPrefix(t, "[") {
+				inPackage = t == "[package]"
+				continue
+			}
+			if inPackage && strings.HasPrefix(t, "name") {
+				if eq := strings.Index(t, "="); eq >= 0 && strings.TrimSpace(t[:eq]) == "name" {
+					return unquote(strings.TrimSpace(t[eq+1:]))
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// CargoHasPackage reports whether a Cargo.toml declares a [package] (a workspace root
+// can be a crate itself).
+func CargoHasPackage(content string) bool {
+	for _, raw := range strings.Split(content, "\n") {
+		if strings.TrimSpace(stripLineComment(raw, "#")) == "[package]" {
+			return true
+		}
+	}
+	return false
+}
+
+func tomlStringArray(val string) []string {
+	var out []string
+	for _, q := range gradleQuotedRe.FindAllStringSubmatch(val, -1) {
+		out = append(out, q[1])
+	}
+	return out
+}
+
+func cleanMemberPaths(paths []string) []string {
+	var out []string
+	for _, p := range paths {
+		neg := strings.HasPrefix(p, "!")
+		p = strings.TrimSpace(strings.TrimPrefix(p, "!"))
+		if p == "" {
+			continue
+		}
+		p = cleanMemberPath(p)
+		if neg {
+			p = "!" + p
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// cleanMemberPath normalizes a member path to a POSIX relpath ("." for the root itself).
+func cleanMemberPath(p string) string {
+	p = strings.TrimSpace(strings.ReplaceAll(p, "\\", "/"))
+	for strings.HasPrefix(p, "./") {
+		p = p[2:]
+	}
+	p = strings.TrimRight(p, "/")
+	if p == "" || p == "." {
+		return "."
+	}
+	return p
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'' || s[0] == '`') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func stripLineComment(line string, marker string) string {
+	if k := strings.Index(line, marker); k >= 0 {
+		return line[:k]
+	}
+	return line
+}
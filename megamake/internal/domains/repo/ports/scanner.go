@@ -8,8 +8,24 @@ type ScanRequest struct {
 	MaxFileBytes int64
 	IgnoreNames  []string
 	IgnoreGlobs  []string
+
+	// IncludeExts, IncludeGlobs and ExcludeExts override the language-aware include rules.
+	IncludeExts  []string
+	IncludeGlobs []string
+	ExcludeExts  []string
+
+	// ExplainIgnores records every path excluded by a .gitignore/.megamakeignore rule.
+	ExplainIgnores bool
+
+	// IncludeSecretFiles keeps the files domain.IsSecretFile matches.
+	IncludeSecretFiles bool
+}
+
+type ScanResult struct {
+	Files   []project.FileRefV1
+	Ignored []project.IgnoredPathV1 // only with ExplainIgnores
 }
 
 type Scanner interface {
-	Scan(req ScanRequest) ([]project.FileRefV1, error)
+	Scan(req ScanRequest) (ScanResult, error)
 }
@@ -0,0 +1,36 @@
+package api
+
+import (
+	repoapi "github.com/megamake/megamake/internal/domains/repo/api"
+	secureapp "github.com/megamake/megamake/internal/domains/secure/app"
+	secureports "github.com/megamake/megamake/internal/domains/secure/ports"
+	"github.com/megamake/megamake/internal/platform/clock"
+)
+
+type API interface {
+	Scan(req secureapp.ScanRequest) (secureapp.ScanResult, error)
+}
+
+type Dependencies struct {
+	Clock          clock.Clock
+	Repo           repoapi.API
+	ArtifactWriter secureports.ArtifactWriter
+}
+
+func New(deps Dependencies) API {
+	return &secureAPI{
+		svc: &secureapp.Service{
+			Clock:          deps.Clock,
+			Repo:           deps.Repo,
+			ArtifactWriter: deps.ArtifactWriter,
+		},
+	}
+}
+
+type secureAPI struct {
+	svc *secureapp.Service
+}
+
+func (a *secureAPI) Scan(req secureapp.ScanRequest) (secureapp.ScanResult, error) {
+	return a.svc.Scan(req)
+}
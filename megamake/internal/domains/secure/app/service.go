@@ -0,0 +1,307 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	contractartifact "github.com/megamake/megamake/internal/contracts/v1/artifact"
+	project "github.com/megamake/megamake/internal/contracts/v1/project"
+	contract "github.com/megamake/megamake/internal/contracts/v1/secure"
+	repoapi "github.com/megamake/megamake/internal/domains/repo/api"
+	"github.com/megamake/megamake/internal/domains/secure/domain"
+	"github.com/megamake/megamake/internal/domains/secure/ports"
+	"github.com/megamake/megamake/internal/platform/clock"
+)
+
+type Service struct {
+	Clock          clock.Clock
+	Repo           repoapi.API
+	ArtifactWriter ports.ArtifactWriter
+}
+
+type ScanRequest struct {
+	RootPath    string
+	ArtifactDir string
+	Force       bool
+
+	// IncludeTests runs code-pattern rules on test files too (secret rules always run).
+	IncludeTests bool
+
+	// MinSeverity drops findings below this severity (default: low, i.e. keep everything).
+	MinSeverity contract.SeverityV1
+
+	MaxFileBytes    int64
+	MaxAnalyzeBytes int64
+	IgnoreNames     []string
+	IgnoreGlobs     []string
+	IncludeExts     []string
+	IncludeGlobs    []string
+	ExcludeExts     []string
+
+	NetEnabled   bool
+	AllowDomains []string
+	Args         []string
+}
+
+type ScanResult struct {
+	Report            contract.SecureReportV1
+	ReportXML         string
+	ReportJSON        string
+	RemediationPrompt string
+
+	ArtifactPath string
+	LatestPath   string
+}
+
+func (s *Service) Scan(req ScanRequest) (ScanResult, error) {
+	if s.Clock == nil {
+		return ScanResult{}, fmt.Errorf("internal error: Clock is nil")
+	}
+	if s.Repo == nil {
+		return ScanResult{}, fmt.Errorf("internal error: Repo is nil")
+	}
+	if s.ArtifactWriter == nil {
+		return ScanResult{}, fmt.Errorf("internal error: ArtifactWriter is nil")
+	}
+
+	if strings.TrimSpace(req.RootPath) == "" {
+		req.RootPath = "."
+	}
+	if strings.TrimSpace(req.ArtifactDir) == "" {
+		req.ArtifactDir = req.RootPath
+	}
+	if req.MaxFileBytes <= 0 {
+		req.MaxFileBytes = 1_500_000
+	}
+	if req.MaxAnalyzeBytes <= 0 {
+		req.MaxAnalyzeBytes = 1_000_000
+	}
+	if strings.TrimSpace(string(req.MinSeverity)) == "" {
+		req.MinSeverity = contract.SeverityLow
+	}
+
+	now := s.Clock.NowUTC()
+
+	profile, err := s.Repo.Detect(req.RootPath)
+	if err != nil {
+		return ScanResult{}, err
+	}
+	if !profile.IsCodeProject && !req.Force {
+		return ScanResult{}, fmt.Errorf(buildSafetyStopMessage(profile))
+	}
+
+	files, err := s.Repo.Scan(req.RootPath, profile, repoapi.ScanOptions{
+		MaxFileBytes: req.MaxFileBytes,
+		IgnoreNames:  req.IgnoreNames,
+		IgnoreGlobs:  req.IgnoreGlobs,
+		IncludeExts:  req.IncludeExts,
+		IncludeGlobs: req.IncludeGlobs,
+		ExcludeExts:  req.ExcludeExts,
+
+		// Secret rules must see the dotenv and key files prompts deliberately leave out.
+		IncludeSecretFiles: true,
+	})
+	if err != nil {
+		return ScanResult{}, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].RelPath < files[j].RelPath })
+
+	rules := domain.DefaultRules()
+	minRank := contract.SeverityRank(req.MinSeverity)
+
+	var findings []contract.FindingV1
+	var warnings []string
+	var analyzedPaths []string
+	var hashes []contractartifact.FileHashV1
+	analyzed := 0
+	suppressed := 0
+
+	for _, f := range files {
+		// Read as text so UTF-16/32 and legacy encodings are transcoded before the rules run.
+		// The scan admitted the file under MaxFileBytes; only the analyzed prefix is capped.
+		tf, err := s.Repo.ReadTextFileRel(req.RootPath, f.RelPath, req.MaxFileBytes)
+		if err != nil {
+			warnings = append(warnings, "failed to read "+f.RelPath+": "+err.Error())
+			continue
+		}
+		hashes = append(hashes, contractartifact.FileHashV1{Path: f.RelPath, SHA256: tf.SHA256, Bytes: tf.Bytes})
+		b := truncateUTF8(tf.Text, req.MaxAnalyzeBytes)
+		analyzed++
+		analyzedPaths = append(analyzedPaths, f.RelPath)
+
+		fs, sup := domain.AnalyzeFile(f.RelPath, string(b), f.IsTest, req.IncludeTests, rules)
+		suppressed += sup
+		for _, x := range fs {
+			if contract.SeverityRank(x.Severity) > minRank {
+				continue
+			}
+			findings = append(findings, x)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		ri, rj := contract.SeverityRank(findings[i].Severity), contract.SeverityRank(findings[j].Severity)
+		if ri != rj {
+			return ri < rj
+		}
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		if findings[i].Line != findings[j].Line {
+			return findings[i].Line < findings[j].Line
+		}
+		return findings[i].Column < findings[j].Column
+	})
+
+	report := contract.SecureReportV1{
+		GeneratedAt:   contractartifact.FormatRFC3339NanoUTC(now),
+		RootPath:      req.RootPath,
+		FilesScanned:  len(files),
+		FilesAnalyzed: analyzed,
+		Findings:      findings,
+		Summary:       summarize(findings, suppressed),
+		Warnings:      warnings,
+	}
+
+	// The XML groups by file; keep that view file-ordered while JSON stays severity-ordered.
+	xmlReport := report
+	xmlReport.Findings = append([]contract.FindingV1(nil), findings...)
+	sort.SliceStable(xmlReport.Findings, func(i, j int) bool {
+		if xmlReport.Findings[i].File != xmlReport.Findings[j].File {
+			return xmlReport.Findings[i].File < xmlReport.Findings[j].File
+		}
+		return xmlReport.Findings[i].Line < xmlReport.Findings[j].Line
+	})
+
+	reportXML := xmlReport.ToXML()
+	jb, _ := json.MarshalIndent(report, "", "  ")
+	reportJSON := string(jb)
+
+	remediationPrompt := domain.GenerateRemediationPrompt(report)
+
+	meta := contractartifact.ArtifactMetaV1{
+		Tool:         "megasecure",
+		Contract:     "v1",
+		GeneratedAt:  contractartifact.FormatRFC3339NanoUTC(now),
+		RootPath:     req.RootPath,
+		Args:         req.Args,
+		NetEnabled:   req.NetEnabled,
+		AllowDomains: cloneStrings(req.AllowDomains),
+		Warnings:     report.Warnings,
+	}
+
+	env := contractartifact.ArtifactEnvelopeV1{
+		Meta:   meta,
+		XML:    reportXML,
+		JSON:   reportJSON,
+		Prompt: remediationPrompt,
+
+		Manifest: contractartifact.NewManifest(req.RootPath, analyzedPaths),
+	}
+	for _, h := range hashes {
+		env.Manifest.SetHash(h.Path, h.SHA256, h.Bytes)
+	}
+
+	artifactPath, latestPath, err := s.ArtifactWriter.WriteToolArtifact(ports.WriteArtifactRequest{
+		ArtifactDir:    req.ArtifactDir,
+		ToolPrefix:     "MEGASECURE",
+		Envelope:       env,
+		GeneratedAtUTC: timePtr(now),
+	})
+	if err != nil {
+		return ScanResult{}, err
+	}
+
+	return ScanResult{
+		Report:            report,
+		ReportXML:         reportXML,
+		ReportJSON:        reportJSON,
+		RemediationPrompt: remediationPrompt,
+		ArtifactPath:      artifactPath,
+		LatestPath:        latestPath,
+	}, nil
+}
+
+// ParseSeverity validates a user-supplied severity threshold.
+func ParseSeverity(v string) (contract.SeverityV1, error) {
+	x := contract.SeverityV1(strings.ToLower(strings.TrimSpace(v)))
+	switch x {
+	case "":
+		return contract.SeverityLow, nil
+	case contract.SeverityCritical, contract.SeverityHigh, contract.SeverityMedium, contract.SeverityLow:
+		return x, nil
+	default:
+		return "", fmt.Errorf("invalid severity %q (expected critical|high|medium|low)", v)
+	}
+}
+
+func summarize(findings []contract.FindingV1, suppressed int) contract.SecureSummaryV1 {
+	s := contract.SecureSummaryV1{
+		Total:      len(findings),
+		Suppressed: suppressed,
+	}
+	for _, f := range findings {
+		switch f.Severity {
+		case contract.SeverityCritical:
+			s.Critical++
+		case contract.SeverityHigh:
+			s.High++
+		case contract.SeverityMedium:
+			s.Medium++
+		case contract.SeverityLow:
+			s.Low++
+		}
+		if s.ByCategory == nil {
+			s.ByCategory = map[string]int{}
+		}
+		s.ByCategory[string(f.Category)]++
+	}
+	return s
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
+
+func buildSafetyStopMessage(p project.ProjectProfileV1) string {
+	var b strings.Builder
+	b.WriteString("Safety stop: This directory does not appear to be a code project.\n")
+	if len(p.Why) > 0 {
+		b.WriteString("Evidence:\n")
+		for _, w := range p.Why {
+			b.WriteString("  - ")
+			b.WriteString(w)
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("If you are certain, re-run with --force.\n")
+	return b.String()
+}
+
+func cloneStrings(xs []string) []string {
+	if len(xs) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(xs))
+	for _, x := range xs {
+		x = strings.TrimSpace(x)
+		if x == "" {
+			continue
+		}
+		out = append(out, x)
+	}
+	return out
+}
+
+// truncateUTF8 cuts b to at most n bytes without splitting a UTF-8 sequence.
+func truncateUTF8(b []byte, n int64) []byte {
+	if int64(len(b)) <= n {
+		return b
+	}
+	for n > 0 && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	return b[:n]
+}
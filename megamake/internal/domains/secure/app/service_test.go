@@ -0,0 +1,87 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	repoadapters "github.com/megamake/megamake/internal/domains/repo/adapters"
+	repoapi "github.com/megamake/megamake/internal/domains/repo/api"
+	"github.com/megamake/megamake/internal/domains/secure/ports"
+	"github.com/megamake/megamake/internal/platform/clock"
+)
+
+type discardArtifacts struct{}
+
+func (discardArtifacts) WriteToolArtifact(req ports.WriteArtifactRequest) (string, string, error) {
+	return filepath.Join(req.ArtifactDir, req.ToolPrefix+"_test.txt"), "", nil
+}
+
+func newTestService() *Service {
+	repo := repoapi.New(repoapi.Dependencies{
+		Detector: repoadapters.NewOSDetector(),
+		Scanner:  repoadapters.NewOSScanner(),
+		Reader:   repoadapters.NewOSReader(),
+	})
+	return &Service{Clock: clock.SystemUTC{}, Repo: repo, ArtifactWriter: discardArtifacts{}}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hasFinding(res ScanResult, ruleID string, file string) bool {
+	for _, f := range res.Report.Findings {
+		if f.RuleID == ruleID && f.File == file {
+			return true
+		}
+	}
+	return false
+}
+
+// A file larger than MaxAnalyzeBytes but within MaxFileBytes is analyzed up to the cap,
+// not skipped.
+func TestScanAnalyzesFilesBetweenAnalyzeAndFileLimits(t *testing.T) {
+	root := t.TempDir()
+	var b strings.Builder
+	b.WriteString("package big\n\nconst awsKey = \"AKIAZ7Q3XK2M4P9RT5VW\"\n\n")
+	for b.Len() < 1_200_000 {
+		b.WriteString("// padding padding padding padding padding padding padding padding\n")
+	}
+	writeFile(t, filepath.Join(root, "big.go"), b.String())
+
+	res, err := newTestService().Scan(ScanRequest{RootPath: root, Force: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Report.FilesAnalyzed != 1 {
+		t.Fatalf("FilesAnalyzed = %d, warnings %v", res.Report.FilesAnalyzed, res.Report.Warnings)
+	}
+	if !hasFinding(res, "secret.aws-access-key-id", "big.go") {
+		t.Fatalf("no aws key finding in big.go: %+v", res.Report.Findings)
+	}
+}
+
+func TestTruncateUTF8KeepsRuneBoundary(t *testing.T) {
+	b := []byte("ab€cd") // € is 3 bytes at offsets 2..4
+	for n := int64(2); n <= 5; n++ {
+		got := truncateUTF8(b, n)
+		if !utf8.Valid(got) || int64(len(got)) > n {
+			t.Fatalf("truncateUTF8(%d) = %q", n, got)
+		}
+	}
+	if got := string(truncateUTF8(b, 4)); got != "ab" {
+		t.Fatalf("truncateUTF8(4) = %q, want \"ab\"", got)
+	}
+	if got := string(truncateUTF8(b, 100)); got != "ab€cd" {
+		t.Fatalf("truncateUTF8(100) = %q", got)
+	}
+}
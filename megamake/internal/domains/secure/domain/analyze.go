@@ -0,0 +1,247 @@
+package domain
+
+import (
+	"math"
+	"path"
+	"strings"
+
+	contract "github.com/megamake/megamake/internal/contracts/v1/secure"
+)
+
+const maxSnippetLen = 160
+
+// suppressMarkers silence every rule on the line they appear on.
+var suppressMarkers = []string{"nosec", "megamake:allow"}
+
+// LanguageForRel maps a relpath to the language name used by Rule.Languages.
+// Config formats whose values are written unquoted (dotenv, ini, properties, YAML) get
+// their own names; other docs and configs return "" and are only checked by secret and
+// key rules.
+func LanguageForRel(rel string) string {
+	if base := strings.ToLower(path.Base(rel)); base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env") {
+		return "dotenv"
+	}
+	switch strings.ToLower(path.Ext(rel)) {
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".js", ".jsx", ".mjs", ".cjs":
+		return "javascript"
+	case ".ts", ".tsx":
+		return "typescript"
+	case ".java":
+		return "java"
+	case ".kt", ".kts":
+		return "kotlin"
+	case ".rs":
+		return "rust"
+	case ".rb":
+		return "ruby"
+	case ".php":
+		return "php"
+	case ".cs":
+		return "csharp"
+	case ".c", ".h":
+		return "c"
+	case ".cc", ".cpp", ".cxx", ".hpp", ".hh":
+		return "cpp"
+	case ".swift":
+		return "swift"
+	case ".sh", ".bash", ".zsh":
+		return "shell"
+	case ".ini", ".cfg":
+		return "ini"
+	case ".properties":
+		return "properties"
+	case ".yaml", ".yml":
+		return "yaml"
+	default:
+		return ""
+	}
+}
+
+// AnalyzeFile runs rules over text line by line.
+//
+// For test files only secret and private-key rules run unless includeTests is set:
+// fixtures often use weak hashes or shell helpers on purpose, but a real key is a leak anywhere.
+// Matches on lines carrying a suppress marker are counted in suppressed and not reported.
+func AnalyzeFile(rel string, text string, isTest bool, includeTests bool, rules []Rule) (findings []contract.FindingV1, suppressed int) {
+	lang := LanguageForRel(rel)
+
+	var active []Rule
+	for _, r := range rules {
+		if isTest && !includeTests && !isSecretCategory(r.Category) {
+			continue
+		}
+		if r.Languages != nil && !containsString(r.Languages, lang) {
+			continue
+		}
+		active = append(active, r)
+	}
+	if len(active) == 0 {
+		return nil, 0
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		comment := isCommentLine(line)
+		suppress := hasSuppressMarker(line)
+
+		// Byte ranges already claimed on this line; later rules skip overlapping matches
+		// so a single token is reported once, by the most specific rule.
+		var claimed [][2]int
+
+		for _, r := range active {
+			if comment && !isSecretCategory(r.Category) {
+				continue
+			}
+			for _, m := range r.Pattern.FindAllStringSubmatchIndex(line, -1) {
+				start, end := m[0], m[1]
+				valStart, valEnd := start, end
+				if r.ValueGroup > 0 {
+					if len(m) <= 2*r.ValueGroup+1 || m[2*r.ValueGroup] < 0 {
+						continue
+					}
+					valStart, valEnd = m[2*r.ValueGroup], m[2*r.ValueGroup+1]
+					if !looksLikeSecretValue(line[valStart:valEnd]) {
+						continue
+					}
+				}
+				if overlaps(claimed, start, end) {
+					continue
+				}
+				claimed = append(claimed, [2]int{start, end})
+
+				if suppress {
+					suppressed++
+					continue
+				}
+
+				// PEM headers carry no key material; only secret values are masked.
+				snippet := line
+				if r.Category == contract.CategorySecret {
+					snippet = redact(line, valStart, valEnd)
+				}
+				findings = append(findings, contract.FindingV1{
+					RuleID:      r.ID,
+					Category:    r.Category,
+					Severity:    r.Severity,
+					Language:    lang,
+					File:        rel,
+					Line:        i + 1,
+					Column:      start + 1,
+					Message:     r.Message,
+					Snippet:     truncate(strings.TrimSpace(snippet), maxSnippetLen),
+					Remediation: r.Remediation,
+					IsTest:      isTest,
+				})
+			}
+		}
+	}
+	return findings, suppressed
+}
+
+func isSecretCategory(c contract.CategoryV1) bool {
+	return c == contract.CategorySecret || c == contract.CategoryPrivateKey
+}
+
+func isCommentLine(line string) bool {
+	t := strings.TrimSpace(line)
+	for _, p := range []string{"//", "#", "/*", "*", "--", "<!--"} {
+		if strings.HasPrefix(t, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasSuppressMarker(line string) bool {
+	lower := strings.ToLower(line)
+	for _, m := range suppressMarkers {
+		if strings.Contains(lower, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlaps(claimed [][2]int, start int, end int) bool {
+	for _, c := range claimed {
+		if start < c[1] && c[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+// redact keeps a short prefix of the secret so a reader can locate it, and hides the rest.
+func redact(line string, start int, end int) string {
+	if start < 0 || end > len(line) || start >= end {
+		return line
+	}
+	keep := 4
+	if end-start <= 8 {
+		keep = 0
+	}
+	return line[:start] + line[start:start+keep] + "[REDACTED]" + line[end:]
+}
+
+// looksLikeSecretValue filters out placeholders, env lookups and low-entropy words
+// that would otherwise dominate generic credential matches.
+func looksLikeSecretValue(v string) bool {
+	lower := strings.ToLower(v)
+	for _, p := range []string{"${", "{{", "<", "xxx", "***", "changeme", "change_me", "example", "placeholder", "your_", "your-", "dummy", "redacted", "process.env", "os.getenv", "env("} {
+		if strings.Contains(lower, p) {
+			return false
+		}
+	}
+	hasLetter := false
+	for _, r := range v {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			hasLetter = true
+			break
+		}
+	}
+	if !hasLetter {
+		return false
+	}
+	return shannonEntropy(v) >= 3.0
+}
+
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+func containsString(xs []string, x string) bool {
+	for _, v := range xs {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,58 @@
+package domain
+
+import "testing"
+
+func credentialLines(rel string, text string) []int {
+	findings, _ := AnalyzeFile(rel, text, false, false, DefaultRules())
+	var lines []int
+	for _, f := range findings {
+		if f.RuleID == "secret.hardcoded-credential" {
+			lines = append(lines, f.Line)
+		}
+	}
+	return lines
+}
+
+func TestAnalyzeFileFindsUnquotedConfigCredentials(t *testing.T) {
+	cases := []struct {
+		name string
+		rel  string
+		text string
+		want []int
+	}{
+		{"dotenv", ".env", "DEBUG=true\nSECRET_KEY=Zq8xK2mP9vL4nR7t\n", []int{2}},
+		{"dotenv export", "config/.env.local", "export STRIPE_API_KEY=Zq8xK2mP9vL4nR7t # rotate\n", []int{1}},
+		{"yaml", "deploy/values.yaml", "db:\n  host: localhost\n  password: Zq8xK2mP9vL4nR7t\n", []int{3}},
+		{"properties", "app.properties", "db.password=Zq8xK2mP9vL4nR7t\n", []int{1}},
+		{"ini", "settings.ini", "[auth]\nclient_secret = Zq8xK2mP9vL4nR7t\n", []int{2}},
+		{"quoted still found", ".env", "SECRET=\"Zq8xK2mP9vL4nR7t\"\n", []int{1}},
+		{"placeholder", ".env", "SECRET_KEY=${SECRET_KEY}\nPASSWORD=changeme123\n", nil},
+		{"too short", ".env", "PASSWORD=abc\n", nil},
+		{"nested yaml key", "values.yml", "secrets:\n  name: app\n", nil},
+		{"not in code", "main.go", "password := readPasswordFromVault()\n", nil},
+	}
+	for _, c := range cases {
+		got := credentialLines(c.rel, c.text)
+		if len(got) != len(c.want) {
+			t.Errorf("%s: credential lines = %v, want %v", c.name, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%s: credential lines = %v, want %v", c.name, got, c.want)
+			}
+		}
+	}
+}
+
+func TestLanguageForRelConfigFormats(t *testing.T) {
+	for rel, want := range map[string]string{
+		".env": "dotenv", "app/.env.production": "dotenv", "prod.env": "dotenv",
+		"a.yml": "yaml", "b.YAML": "yaml", "c.properties": "properties", "d.ini": "ini",
+		"README.md": "", "main.go": "go",
+	} {
+		if got := LanguageForRel(rel); got != want {
+			t.Errorf("LanguageForRel(%q) = %q, want %q", rel, got, want)
+		}
+	}
+}
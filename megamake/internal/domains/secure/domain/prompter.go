@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+
+	contractpatch "github.com/megamake/megamake/internal/contracts/v1/patch"
+	contract "github.com/megamake/megamake/internal/contracts/v1/secure"
+)
+
+// GenerateRemediationPrompt produces an agent prompt asking for fixes to the reported findings.
+func GenerateRemediationPrompt(report contract.SecureReportV1) string {
+	s := report.Summary
+
+	var lines []string
+	lines = append(lines, "You are an expert application security engineer. Remediate the following findings without changing behavior beyond what the fix requires.")
+	lines = append(lines, "")
+	lines = append(lines, "Context:")
+	lines = append(lines, "- Files analyzed: "+itoa(report.FilesAnalyzed)+" of "+itoa(report.FilesScanned)+" scanned")
+	lines = append(lines, "- Findings: "+itoa(s.Total)+" ("+itoa(s.Critical)+" critical, "+itoa(s.High)+" high, "+itoa(s.Medium)+" medium, "+itoa(s.Low)+" low)")
+	if len(s.ByCategory) > 0 {
+		var cats []string
+		for k := range s.ByCategory {
+			cats = append(cats, k)
+		}
+		sort.Strings(cats)
+		var parts []string
+		for _, k := range cats {
+			parts = append(parts, k+"="+itoa(s.ByCategory[k]))
+		}
+		lines = append(lines, "- By category: "+strings.Join(parts, ", "))
+	}
+	lines = append(lines, "")
+
+	if s.Total == 0 {
+		lines = append(lines, "No findings were reported. No changes are required.")
+		return strings.Join(lines, "\n")
+	}
+
+	// Findings are already sorted by severity, then file and line.
+	lines = append(lines, "Findings (most severe first):")
+	limit := 40
+	if len(report.Findings) < limit {
+		limit = len(report.Findings)
+	}
+	for i := 0; i < limit; i++ {
+		f := report.Findings[i]
+		lines = append(lines, "- ["+string(f.Severity)+"] "+f.File+":"+itoa(f.Line)+" "+f.RuleID+": "+f.Message)
+		if strings.TrimSpace(f.Snippet) != "" {
+			lines = append(lines, "    "+f.Snippet)
+		}
+	}
+	if len(report.Findings) > limit {
+		lines = append(lines, "- ... "+itoa(len(report.Findings)-limit)+" more (see artifact JSON)")
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, "Instructions:")
+	lines = append(lines, "- Secrets and private keys: remove them from source and read them from environment variables or a secret manager; list every credential that must be rotated.")
+	lines = append(lines, "- Weak crypto: switch to modern primitives (SHA-256+, AES-GCM, bcrypt/argon2 for passwords) and keep TLS verification on.")
+	lines = append(lines, "- Unsafe exec: pass argument lists to the process API; never interpolate input into a shell string.")
+	lines = append(lines, "- Unsafe SQL: use parameterized queries or the driver's placeholder syntax.")
+	lines = append(lines, "- If a finding is a false positive, say why and mark the line with a trailing 'nosec' comment instead of changing code.")
+	lines = append(lines, "")
+	lines = append(lines, "Return the changes as a MegaPatch v1 script (apply with: megamake patch).")
+	lines = append(lines, "")
+	lines = append(lines, contractpatch.SyntaxV1)
+	return strings.Join(lines, "\n")
+}
+
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	var buf [32]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = byte('0' + (n % 10))
+		n /= 10
+	}
+	return sign + string(buf[i:])
+}
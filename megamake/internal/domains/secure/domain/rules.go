@@ -0,0 +1,228 @@
+package domain
+
+import (
+	"regexp"
+
+	contract "github.com/megamake/megamake/internal/contracts/v1/secure"
+)
+
+// Rule is a single line-oriented security check.
+//
+// Languages restricts the rule to files of those languages (see LanguageForRel).
+// A nil Languages list means "any file", which is only used for secrets and keys:
+// code-pattern rules always name their languages so docs and configs don't produce noise.
+type Rule struct {
+	ID          string
+	Category    contract.CategoryV1
+	Severity    contract.SeverityV1
+	Languages   []string
+	Pattern     *regexp.Regexp
+	Message     string
+	Remediation string
+
+	// ValueGroup, when > 0, names the submatch holding the secret value.
+	// The value must pass looksLikeSecretValue, and it is the span that gets redacted.
+	ValueGroup int
+}
+
+var (
+	jsLike   = []string{"javascript", "typescript"}
+	jvmLike  = []string{"java", "kotlin"}
+	sqlHosts = []string{"go", "python", "javascript", "typescript", "java", "kotlin", "rust", "ruby", "php", "csharp", "swift"}
+
+	// configLike are the formats that assign bare, unquoted values (NAME=value, name: value).
+	configLike = []string{"dotenv", "ini", "properties", "yaml"}
+)
+
+const (
+	remediateSecret     = "Remove the value from source, rotate it, and load it from the environment or a secret manager."
+	remediatePrivateKey = "Remove the key from the repository, rotate it, and load it from a secret store at runtime."
+	remediateHash       = "Use SHA-256 or stronger for integrity, and bcrypt/scrypt/argon2 for passwords."
+	remediateCipher     = "Use an authenticated cipher such as AES-GCM or ChaCha20-Poly1305 with a random nonce."
+	remediateTLS        = "Keep certificate verification enabled; trust a custom CA instead of disabling checks."
+	remediateShell      = "Pass arguments as a list to the process API instead of building a shell command string."
+	remediateEval       = "Avoid evaluating dynamic code; parse the data explicitly instead."
+	remediateSQL        = "Use parameterized queries or prepared statements instead of building SQL with string formatting."
+)
+
+// DefaultRules returns the built-in rule set in a stable order.
+// Secret rules come first so that, on overlapping matches, the most specific rule wins.
+func DefaultRules() []Rule {
+	return []Rule{
+		// --- private keys ---
+		{ID: "private-key.pem-block", Category: contract.CategoryPrivateKey, Severity: contract.SeverityCritical,
+			Pattern:     regexp.MustCompile(`-----BEGIN[ A-Z0-9]*PRIVATE KEY(?: BLOCK)?-----`),
+			Message:     "Private key material committed to source.",
+			Remediation: remediatePrivateKey},
+
+		// --- provider-specific secrets ---
+		{ID: "secret.aws-access-key-id", Category: contract.CategorySecret, Severity: contract.SeverityCritical,
+			Pattern:     regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+			Message:     "AWS access key ID.",
+			Remediation: remediateSecret},
+		{ID: "secret.aws-secret-access-key", Category: contract.CategorySecret, Severity: contract.SeverityCritical,
+			Pattern:     regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]{1,2}\s*["']([A-Za-z0-9/+]{40})["']`),
+			Message:     "AWS secret access key.",
+			Remediation: remediateSecret, ValueGroup: 1},
+		{ID: "secret.gcp-api-key", Category: contract.CategorySecret, Severity: contract.SeverityHigh,
+			Pattern:     regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`),
+			Message:     "Google Cloud API key.",
+			Remediation: remediateSecret},
+		{ID: "secret.gcp-service-account", Category: contract.CategorySecret, Severity: contract.SeverityHigh,
+			Pattern:     regexp.MustCompile(`"type"\s*:\s*"service_account"`),
+			Message:     "Google Cloud service account credentials file.",
+			Remediation: remediateSecret},
+		{ID: "secret.anthropic-api-key", Category: contract.CategorySecret, Severity: contract.SeverityHigh,
+			Pattern:     regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_\-]{20,}`),
+			Message:     "Anthropic API key.",
+			Remediation: remediateSecret},
+		{ID: "secret.openai-api-key", Category: contract.CategorySecret, Severity: contract.SeverityHigh,
+			Pattern:     regexp.MustCompile(`\bsk-(?:proj-|svcacct-)?[A-Za-z0-9_\-]{32,}`),
+			Message:     "OpenAI API key.",
+			Remediation: remediateSecret},
+		{ID: "secret.github-token", Category: contract.CategorySecret, Severity: contract.SeverityHigh,
+			Pattern:     regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})\b`),
+			Message:     "GitHub token.",
+			Remediation: remediateSecret},
+		{ID: "secret.slack-token", Category: contract.CategorySecret, Severity: contract.SeverityHigh,
+			Pattern:     regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9\-]{10,}`),
+			Message:     "Slack token.",
+			Remediation: remediateSecret},
+		{ID: "secret.stripe-live-key", Category: contract.CategorySecret, Severity: contract.SeverityHigh,
+			Pattern:     regexp.MustCompile(`\b(?:sk|rk)_live_[0-9A-Za-z]{20,}`),
+			Message:     "Stripe live secret key.",
+			Remediation: remediateSecret},
+		{ID: "secret.jwt", Category: contract.CategorySecret, Severity: contract.SeverityMedium,
+			Pattern:     regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}`),
+			Message:     "Hardcoded JSON Web Token.",
+			Remediation: remediateSecret},
+
+		// --- generic hardcoded credentials ---
+		{ID: "secret.hardcoded-credential", Category: contract.CategorySecret, Severity: contract.SeverityMedium,
+			Pattern:     regexp.MustCompile(`(?i)\b[a-z0-9_.\-]*(?:password|passwd|secret|api[_\-]?key|access[_\-]?token|auth[_\-]?token|client[_\-]?secret)["']?\s*(?::=|[:=]|=>)\s*["']([^"'\s]{8,})["']`),
+			Message:     "Hardcoded credential assigned to a secret-like name.",
+			Remediation: remediateSecret, ValueGroup: 1},
+		{ID: "secret.hardcoded-credential", Category: contract.CategorySecret, Severity: contract.SeverityMedium, Languages: configLike,
+			Pattern:     regexp.MustCompile(`(?i)^\s*(?:export\s+)?[a-z0-9_.\-]*(?:password|passwd|secret|api[_\-]?key|access[_\-]?token|auth[_\-]?token|client[_\-]?secret)[a-z0-9_.\-]*\s*[:=]\s*([^"'\s#][^\s#]{7,})\s*(?:#.*)?$`),
+			Message:     "Hardcoded credential assigned to a secret-like name.",
+			Remediation: remediateSecret, ValueGroup: 1},
+
+		// --- weak crypto ---
+		{ID: "crypto.go-weak-hash", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityMedium, Languages: []string{"go"},
+			Pattern:     regexp.MustCompile(`\b(?:md5|sha1)\.(?:New|Sum)\(`),
+			Message:     "MD5/SHA-1 are broken for security purposes.",
+			Remediation: remediateHash},
+		{ID: "crypto.go-weak-cipher", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityHigh, Languages: []string{"go"},
+			Pattern:     regexp.MustCompile(`\b(?:des\.New(?:Triple)?Cipher|rc4\.NewCipher)\(`),
+			Message:     "DES/3DES/RC4 are weak ciphers.",
+			Remediation: remediateCipher},
+		{ID: "crypto.go-insecure-tls", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityHigh, Languages: []string{"go"},
+			Pattern:     regexp.MustCompile(`InsecureSkipVerify\s*:\s*true`),
+			Message:     "TLS certificate verification is disabled.",
+			Remediation: remediateTLS},
+		{ID: "crypto.python-weak-hash", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityMedium, Languages: []string{"python"},
+			Pattern:     regexp.MustCompile(`\bhashlib\.(?:md5|sha1)\(|\bhashlib\.new\(\s*["'](?i:md5|sha1)["']`),
+			Message:     "MD5/SHA-1 are broken for security purposes.",
+			Remediation: remediateHash},
+		{ID: "crypto.python-weak-cipher", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityHigh, Languages: []string{"python"},
+			Pattern:     regexp.MustCompile(`\b(?:DES3?|ARC4|Blowfish)\.new\(|\bMODE_ECB\b`),
+			Message:     "Weak cipher or ECB mode.",
+			Remediation: remediateCipher},
+		{ID: "crypto.python-insecure-tls", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityHigh, Languages: []string{"python"},
+			Pattern:     regexp.MustCompile(`\bverify\s*=\s*False\b|\bssl\._create_unverified_context\(`),
+			Message:     "TLS certificate verification is disabled.",
+			Remediation: remediateTLS},
+		{ID: "crypto.js-weak-hash", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityMedium, Languages: jsLike,
+			Pattern:     regexp.MustCompile(`\bcreateHash\(\s*["'](?i:md5|sha1)["']`),
+			Message:     "MD5/SHA-1 are broken for security purposes.",
+			Remediation: remediateHash},
+		{ID: "crypto.js-weak-cipher", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityHigh, Languages: jsLike,
+			Pattern:     regexp.MustCompile(`\bcreateCipher\(|\bcreateCipheriv\(\s*["'](?i:des[^"']*|rc4|[^"']*-ecb)["']`),
+			Message:     "Weak cipher, ECB mode, or IV-less createCipher.",
+			Remediation: remediateCipher},
+		{ID: "crypto.js-insecure-tls", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityHigh, Languages: jsLike,
+			Pattern:     regexp.MustCompile(`\brejectUnauthorized\s*:\s*false\b|NODE_TLS_REJECT_UNAUTHORIZED["']?\s*\]?\s*=\s*["']?0`),
+			Message:     "TLS certificate verification is disabled.",
+			Remediation: remediateTLS},
+		{ID: "crypto.jvm-weak-hash", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityMedium, Languages: jvmLike,
+			Pattern:     regexp.MustCompile(`MessageDigest\.getInstance\(\s*"(?i:md5|sha-?1)"`),
+			Message:     "MD5/SHA-1 are broken for security purposes.",
+			Remediation: remediateHash},
+		{ID: "crypto.jvm-weak-cipher", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityHigh, Languages: jvmLike,
+			Pattern:     regexp.MustCompile(`Cipher\.getInstance\(\s*"(?i:(?:des|desede|rc4|rc2|blowfish)(?:/[^"]*)?|[^"]*/ecb/[^"]*|aes)"`),
+			Message:     "Weak cipher or ECB mode (bare \"AES\" defaults to ECB).",
+			Remediation: remediateCipher},
+		{ID: "crypto.csharp-weak-hash", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityMedium, Languages: []string{"csharp"},
+			Pattern:     regexp.MustCompile(`\b(?:MD5|SHA1)(?:CryptoServiceProvider|Managed)?\.Create\(|new\s+(?:MD5|SHA1)(?:CryptoServiceProvider|Managed)\(`),
+			Message:     "MD5/SHA-1 are broken for security purposes.",
+			Remediation: remediateHash},
+		{ID: "crypto.rust-weak-hash", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityMedium, Languages: []string{"rust"},
+			Pattern:     regexp.MustCompile(`\bmd5::compute\(|\b(?:Md5|Sha1)::(?:new|digest)\(`),
+			Message:     "MD5/SHA-1 are broken for security purposes.",
+			Remediation: remediateHash},
+		{ID: "crypto.php-weak-hash", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityMedium, Languages: []string{"php"},
+			Pattern:     regexp.MustCompile(`\b(?:md5|sha1)\(\s*\$`),
+			Message:     "MD5/SHA-1 are broken for security purposes.",
+			Remediation: remediateHash},
+		{ID: "crypto.ruby-weak-hash", Category: contract.CategoryWeakCrypto, Severity: contract.SeverityMedium, Languages: []string{"ruby"},
+			Pattern:     regexp.MustCompile(`\bDigest::(?:MD5|SHA1)\b`),
+			Message:     "MD5/SHA-1 are broken for security purposes.",
+			Remediation: remediateHash},
+
+		// --- unsafe exec ---
+		{ID: "exec.go-shell", Category: contract.CategoryUnsafeExec, Severity: contract.SeverityHigh, Languages: []string{"go"},
+			Pattern:     regexp.MustCompile(`exec\.Command(?:Context)?\([^)]*"(?:sh|bash|zsh|cmd|cmd\.exe|powershell)"\s*,\s*"(?:-c|/c|/C|-Command)"\s*,\s*(?:[A-Za-z_]|fmt\.Sprintf|"[^"]*"\s*\+)`),
+			Message:     "Shell command built from a dynamic string.",
+			Remediation: remediateShell},
+		{ID: "exec.python-shell", Category: contract.CategoryUnsafeExec, Severity: contract.SeverityHigh, Languages: []string{"python"},
+			Pattern:     regexp.MustCompile(`\bos\.(?:system|popen)\(|\bsubprocess\.\w+\(.*\bshell\s*=\s*True`),
+			Message:     "Command executed through the shell.",
+			Remediation: remediateShell},
+		{ID: "exec.python-eval", Category: contract.CategoryUnsafeExec, Severity: contract.SeverityMedium, Languages: []string{"python"},
+			Pattern:     regexp.MustCompile(`(?:^|[^.\w])(?:eval|exec)\(\s*[^"'\s)]`),
+			Message:     "Dynamic code evaluation.",
+			Remediation: remediateEval},
+		{ID: "exec.js-child-process", Category: contract.CategoryUnsafeExec, Severity: contract.SeverityHigh, Languages: jsLike,
+			Pattern:     regexp.MustCompile("\\bexec(?:Sync)?\\(\\s*(?:`[^`]*\\$\\{|[\"'][^\"']*[\"']\\s*\\+)"),
+			Message:     "Shell command built from interpolated input.",
+			Remediation: remediateShell},
+		{ID: "exec.js-eval", Category: contract.CategoryUnsafeExec, Severity: contract.SeverityMedium, Languages: jsLike,
+			Pattern:     regexp.MustCompile(`(?:^|[^.\w])eval\(|\bnew\s+Function\(`),
+			Message:     "Dynamic code evaluation.",
+			Remediation: remediateEval},
+		{ID: "exec.jvm-runtime", Category: contract.CategoryUnsafeExec, Severity: contract.SeverityHigh, Languages: jvmLike,
+			Pattern:     regexp.MustCompile(`Runtime\.getRuntime\(\)\.exec\(\s*(?:"[^"]*"\s*\+|[A-Za-z_]\w*\s*\+)`),
+			Message:     "Process command built by string concatenation.",
+			Remediation: remediateShell},
+		{ID: "exec.php-shell", Category: contract.CategoryUnsafeExec, Severity: contract.SeverityHigh, Languages: []string{"php"},
+			Pattern:     regexp.MustCompile(`\b(?:shell_exec|system|passthru|exec|popen|proc_open)\(\s*(?:\$|["'][^"']*["']\s*\.)`),
+			Message:     "Shell command built from a variable.",
+			Remediation: remediateShell},
+		{ID: "exec.ruby-shell", Category: contract.CategoryUnsafeExec, Severity: contract.SeverityHigh, Languages: []string{"ruby"},
+			Pattern:     regexp.MustCompile("(?:\\b(?:system|exec|spawn)\\(?\\s*\"[^\"]*#\\{|`[^`]*#\\{|%x\\{[^}]*#\\{)"),
+			Message:     "Shell command built with string interpolation.",
+			Remediation: remediateShell},
+		{ID: "exec.shell-eval", Category: contract.CategoryUnsafeExec, Severity: contract.SeverityMedium, Languages: []string{"shell"},
+			Pattern:     regexp.MustCompile(`(?:^|[;&|]\s*)eval\s+["']?\$`),
+			Message:     "eval of a variable expansion.",
+			Remediation: remediateEval},
+
+		// --- unsafe SQL ---
+		{ID: "sql.go-sprintf", Category: contract.CategoryUnsafeSQL, Severity: contract.SeverityHigh, Languages: []string{"go"},
+			Pattern:     regexp.MustCompile(`fmt\.Sprintf\(\s*["` + "`" + `]\s*(?i:select|insert|update|delete|replace|merge)\b[^"` + "`" + `]*%[sv]`),
+			Message:     "SQL statement built with fmt.Sprintf.",
+			Remediation: remediateSQL},
+		{ID: "sql.python-format", Category: contract.CategoryUnsafeSQL, Severity: contract.SeverityHigh, Languages: []string{"python"},
+			Pattern:     regexp.MustCompile(`\bf["']\s*(?i:select|insert|update|delete)\b[^"']*\{|["']\s*(?i:select|insert|update|delete)\b[^"']*(?:%s[^"']*["']\s*%|\{\}[^"']*["']\.format\()`),
+			Message:     "SQL statement built with string formatting.",
+			Remediation: remediateSQL},
+		{ID: "sql.template-literal", Category: contract.CategoryUnsafeSQL, Severity: contract.SeverityHigh, Languages: jsLike,
+			Pattern:     regexp.MustCompile("`\\s*(?i:select|insert|update|delete)\\b[^`]*\\$\\{"),
+			Message:     "SQL statement built with a template literal.",
+			Remediation: remediateSQL},
+		{ID: "sql.string-concat", Category: contract.CategoryUnsafeSQL, Severity: contract.SeverityHigh, Languages: sqlHosts,
+			Pattern:     regexp.MustCompile(`["']\s*(?i:select\s.+\sfrom|insert\s+into|update\s.+\sset|delete\s+from)\b[^"']*["']\s*(?:\+|\.)\s*[$A-Za-z_]`),
+			Message:     "SQL statement built by string concatenation.",
+			Remediation: remediateSQL},
+	}
+}
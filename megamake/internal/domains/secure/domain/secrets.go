@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	contract "github.com/megamake/megamake/internal/contracts/v1/secure"
+)
+
+// SecretSpan is a secret value located in a text, as a byte range [Start, End).
+type SecretSpan struct {
+	Start  int
+	End    int
+	RuleID string
+}
+
+// pemBlockRe spans a whole private key block, including JSON-escaped single-line forms.
+var pemBlockRe = regexp.MustCompile(`(?s)-----BEGIN[ A-Z0-9]*PRIVATE KEY(?: BLOCK)?-----(.*?)-----END[ A-Z0-9]*PRIVATE KEY(?: BLOCK)?-----`)
+
+// entropyAssignRe matches a quoted value assigned to any name; the value is only a secret
+// if it is also long and random-looking (see looksLikeRandomToken).
+var entropyAssignRe = regexp.MustCompile(`(?i)\b[a-z_][a-z0-9_.\-]*["']?\s*(?::=|[:=]|=>)\s*["']([A-Za-z0-9+/=_\-.~]{20,})["']`)
+
+// entropyBareAssignRe is the unquoted form (dotenv NAME=value, YAML name: value): the value
+// must be the rest of the line, apart from a trailing comment.
+var entropyBareAssignRe = regexp.MustCompile(`(?i)^\s*(?:export\s+)?[a-z_][a-z0-9_.\-]*\s*(?::=|[:=]|=>)\s*([A-Za-z0-9+/=_\-.~]{20,})\s*(?:#.*)?\s*$`)
+
+// RuleHighEntropyAssignment is the rule ID FindSecrets reports for random-looking
+// values assigned to names that no specific rule recognizes.
+const RuleHighEntropyAssignment = "secret.high-entropy-assignment"
+
+// FindSecrets locates secret values in text using the secret and private-key rules,
+// plus random-looking strings in assignments, quoted or not. For private keys the span
+// covers the key material between the BEGIN and END lines, so the markers themselves
+// stay readable.
+// Suppress markers are ignored: callers redacting text want every secret gone.
+// Spans are sorted and never overlap.
+func FindSecrets(text string) []SecretSpan {
+	var spans []SecretSpan
+	for _, m := range pemBlockRe.FindAllStringSubmatchIndex(text, -1) {
+		start, end := trimKeyBody(text, m[2], m[3])
+		if end > start {
+			spans = append(spans, SecretSpan{Start: start, End: end, RuleID: "private-key.pem-block"})
+		}
+	}
+
+	var valueRules []Rule
+	for _, r := range DefaultRules() {
+		// Rules without a value of their own (PEM headers, service-account markers) are
+		// covered by the block pass or by the rules matching the values they contain.
+		// Language-bound rules need the file's path, which FindSecrets does not have.
+		if r.Category == contract.CategorySecret && r.ID != "secret.gcp-service-account" && r.Languages == nil {
+			valueRules = append(valueRules, r)
+		}
+	}
+
+	offset := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		for _, r := range valueRules {
+			for _, m := range r.Pattern.FindAllStringSubmatchIndex(line, -1) {
+				start, end := m[0], m[1]
+				if r.ValueGroup > 0 {
+					if len(m) <= 2*r.ValueGroup+1 || m[2*r.ValueGroup] < 0 {
+						continue
+					}
+					start, end = m[2*r.ValueGroup], m[2*r.ValueGroup+1]
+					if !looksLikeSecretValue(line[start:end]) {
+						continue
+					}
+				}
+				spans = appendSpan(spans, SecretSpan{Start: offset + start, End: offset + end, RuleID: r.ID})
+			}
+		}
+		assigns := entropyAssignRe.FindAllStringSubmatchIndex(line, -1)
+		if m := entropyBareAssignRe.FindStringSubmatchIndex(line); m != nil {
+			assigns = append(assigns, m)
+		}
+		for _, m := range assigns {
+			v := line[m[2]:m[3]]
+			if looksLikeSecretValue(v) && looksLikeRandomToken(v) {
+				spans = appendSpan(spans, SecretSpan{Start: offset + m[2], End: offset + m[3], RuleID: RuleHighEntropyAssignment})
+			}
+		}
+		offset += len(line)
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	return spans
+}
+
+// trimKeyBody narrows a key block body to its material, leaving the line breaks (real or
+// JSON-escaped) next to the BEGIN and END markers outside the span.
+func trimKeyBody(text string, start int, end int) (int, int) {
+	for start < end {
+		if strings.HasPrefix(text[start:end], `\n`) {
+			start += 2
+		} else if strings.ContainsRune(" \t\r\n", rune(text[start])) {
+			start++
+		} else {
+			break
+		}
+	}
+	for end > start {
+		if strings.HasSuffix(text[start:end], `\n`) {
+			end -= 2
+		} else if strings.ContainsRune(" \t\r\n", rune(text[end-1])) {
+			end--
+		} else {
+			break
+		}
+	}
+	return start, end
+}
+
+// appendSpan adds s unless it overlaps a span found earlier (by a more specific rule).
+func appendSpan(spans []SecretSpan, s SecretSpan) []SecretSpan {
+	for _, c := range spans {
+		if s.Start < c.End && c.Start < s.End {
+			return spans
+		}
+	}
+	return append(spans, s)
+}
+
+// looksLikeRandomToken is stricter than looksLikeSecretValue: it wants mixed letters and
+// digits, no path or URL shape, and entropy above what hex digests can reach, so
+// checksums, identifiers and file paths in ordinary config are left alone.
+func looksLikeRandomToken(v string) bool {
+	if strings.Contains(v, "://") || strings.HasPrefix(v, "/") || strings.HasPrefix(v, "./") || strings.Count(v, ".") > 1 {
+		return false
+	}
+	hasDigit, hasUpper, hasLower := false, false, false
+	for _, r := range v {
+		switch {
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r >= 'A' && r <= 'Z':
+			hasUpper = true
+		case r >= 'a' && r <= 'z':
+			hasLower = true
+		}
+	}
+	if !hasDigit || !hasUpper || !hasLower {
+		return false
+	}
+	return shannonEntropy(v) >= 4.2
+}
@@ -42,6 +42,9 @@ type BuildRequest struct {
 	MaxAnalyzeBytes int64
 	IgnoreNames     []string
 	IgnoreGlobs     []string
+	IncludeExts     []string
+	IncludeGlobs    []string
+	ExcludeExts     []string
 
 	Regression RegressionMode
 
@@ -104,6 +107,9 @@ func (s *Service) Build(req BuildRequest) (BuildResult, error) {
 		MaxFileBytes: req.MaxFileBytes,
 		IgnoreNames:  req.IgnoreNames,
 		IgnoreGlobs:  req.IgnoreGlobs,
+		IncludeExts:  req.IncludeExts,
+		IncludeGlobs: req.IncludeGlobs,
+		ExcludeExts:  req.ExcludeExts,
 	})
 	if err != nil {
 		return BuildResult{}, err
@@ -122,16 +128,19 @@ func (s *Service) Build(req BuildRequest) (BuildResult, error) {
 	sort.Slice(testFiles, func(i, j int) bool { return testFiles[i].RelPath < testFiles[j].RelPath })
 	sort.Slice(codeFiles, func(i, j int) bool { return codeFiles[i].RelPath < codeFiles[j].RelPath })
 
-	// Read helper
+	// Read helper; it records what it read for the artifact manifest.
+	hashes := map[string]contractartifact.FileHashV1{}
 	readRel := func(rel string, maxBytes int64) (string, bool) {
-		b, err := s.Repo.ReadFileRel(req.RootPath, rel, maxBytes)
+		tf, err := s.Repo.ReadTextFileRel(req.RootPath, rel, maxBytes)
 		if err != nil {
+			// Unreadable or binary.
 			return "", false
 		}
+		hashes[rel] = contractartifact.FileHashV1{Path: rel, SHA256: tf.SHA256, Bytes: tf.Bytes}
+		b := tf.Text
 		if maxBytes > 0 && int64(len(b)) > maxBytes {
 			b = b[:maxBytes]
 		}
-		// Assume text; if binary, it will produce odd subjects but won't crash.
 		return string(b), true
 	}
 
@@ -314,6 +323,11 @@ func (s *Service) Build(req BuildRequest) (BuildResult, error) {
 		XML:    reportXML,
 		JSON:   reportJSON,
 		Prompt: testPrompt,
+
+		Manifest: contractartifact.NewManifest(req.RootPath, project.RelPaths(files)),
+	}
+	for _, h := range hashes {
+		env.Manifest.SetHash(h.Path, h.SHA256, h.Bytes)
 	}
 
 	artifactPath, latestPath, err := s.ArtifactWriter.WriteToolArtifact(ports.WriteArtifactRequest{
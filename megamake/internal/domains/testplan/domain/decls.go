@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Function declarations as the Go, Python and JavaScript/TypeScript analyzers find them.
+// The prompt skeletonizer locates bodies with the same expressions (FunctionDecls).
+var (
+	goFuncRe = regexp.MustCompile(`(?m)^\s*func\s*(?:\([^)]+\)\s*)?([A-Za-z_]\w*)\s*(?:\[[^\]]*\])?\s*\(([^)]*)\)`)
+	pyFuncRe = regexp.MustCompile(`(?m)^\s*(?:async\s+)?def\s+([A-Za-z_]\w*)\s*\(([^)]*)\)[^:\n]*:`)
+	jsFuncRe = regexp.MustCompile(`(?m)^\s*(?:export\s+(?:default\s+)?)?(?:async\s+)?function\s+([A-Za-z_]\w*)\s*(?:<[^>\n]*>)?\s*\(([^)]*)\)`)
+	// jsArrowRe captures the export keyword; only exported arrow functions are test subjects.
+	jsArrowRe = regexp.MustCompile(`(?m)^\s*(export\s+(?:default\s+)?)?(?:const|let|var)\s+([A-Za-z_]\w*)\s*=\s*(?:async\s+)?\(([^)]*)\)(?:\s*:[^=;\n]*)?\s*=>`)
+	// jsMethodRe matches class members; analyzeJS reports the class rather than each method.
+	jsMethodRe = regexp.MustCompile(`(?m)^[ \t]+(?:(?:public|private|protected|static|async|readonly|override|abstract|get|set)\s+)*\*?\s*(#?[A-Za-z_$][\w$]*)\s*(?:<[^>\n]*>)?\s*\(([^)]*)\)`)
+)
+
+// jsNotMethods are statements that look like a method header to jsMethodRe.
+var jsNotMethods = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true,
+	"function": true, "with": true, "do": true, "else": true, "try": true, "new": true,
+	"typeof": true, "await": true, "yield": true, "super": true, "this": true,
+}
+
+// Kinds of FunctionDecl.
+const (
+	DeclFunction = "function"
+	DeclMethod   = "method"
+	DeclArrow    = "arrow" // const f = (...) => ...
+)
+
+// FunctionDecl locates a function declaration in source text.
+type FunctionDecl struct {
+	Name   string
+	Kind   string
+	Start  int // offset of the start of the declaration's line
+	Params int // offset of the "(" opening the parameter list
+}
+
+// FunctionDecls returns the function declarations of a Go, Python or JavaScript/TypeScript
+// file, ordered by position: the functions AnalyzeFile reports, plus the non-exported arrow
+// functions and class methods it folds away. ok is false for other languages.
+func FunctionDecls(content string, lang string) (decls []FunctionDecl, ok bool) {
+	add := func(re *regexp.Regexp, kind string, nameGroup int, paramsGroup int) {
+		for _, m := range re.FindAllStringSubmatchIndex(content, -1) {
+			name := content[m[2*nameGroup]:m[2*nameGroup+1]]
+			if kind == DeclMethod && jsNotMethods[name] {
+				continue
+			}
+			decls = append(decls, FunctionDecl{
+				Name:   name,
+				Kind:   kind,
+				Start:  strings.LastIndexByte(content[:m[2*nameGroup]], '\n') + 1,
+				Params: m[2*paramsGroup] - 1,
+			})
+		}
+	}
+	switch lang {
+	case "go":
+		add(goFuncRe, DeclFunction, 1, 2)
+	case "python":
+		add(pyFuncRe, DeclFunction, 1, 2)
+	case "javascript", "typescript":
+		add(jsFuncRe, DeclFunction, 1, 2)
+		add(jsArrowRe, DeclArrow, 2, 3)
+		add(jsMethodRe, DeclMethod, 1, 2)
+	default:
+		return nil, false
+	}
+	sort.SliceStable(decls, func(i, j int) bool { return decls[i].Params < decls[j].Params })
+	return decls, true
+}
@@ -129,8 +129,7 @@ func analyzeJS(rel string, content string, lang string) []contract.TestSubjectV1
 	risk, factors, io := scoreRisk(lower)
 
 	// export function foo(...)
-	fnRe := regexp.MustCompile(`(?m)^\s*(?:export\s+(?:default\s+)?)?(?:async\s+)?function\s+([A-Za-z_]\w*)\s*\(([^)]*)\)`)
-	for _, m := range fnRe.FindAllStringSubmatch(content, -1) {
+	for _, m := range jsFuncRe.FindAllStringSubmatch(content, -1) {
 		name := m[1]
 		sig := "function " + name + "(" + m[2] + ")"
 		exported := strings.Contains(content, "export function "+name) || strings.Contains(content, "export default function "+name)
@@ -151,18 +150,20 @@ func analyzeJS(rel string, content string, lang string) []contract.TestSubjectV1
 	}
 
 	// export const foo = (...) =>
-	arrowRe := regexp.MustCompile(`(?m)^\s*export\s+(?:default\s+)?(?:const|let|var)\s+([A-Za-z_]\w*)\s*=\s*(?:async\s+)?\(([^)]*)\)\s*=>`)
-	for _, m := range arrowRe.FindAllStringSubmatch(content, -1) {
-		name := m[1]
+	for _, m := range jsArrowRe.FindAllStringSubmatch(content, -1) {
+		if m[1] == "" {
+			continue
+		}
+		name := m[2]
 		out = append(out, contract.TestSubjectV1{
 			ID:          rel + "#fn:" + name,
 			Kind:        contract.KindFunction,
 			Language:    lang,
 			Name:        name,
 			Path:        rel,
-			Signature:   "const " + name + " = (" + m[2] + ") =>",
+			Signature:   "const " + name + " = (" + m[3] + ") =>",
 			Exported:    true,
-			Params:      parseParamsColon(m[2]),
+			Params:      parseParamsColon(m[3]),
 			RiskScore:   risk,
 			RiskFactors: factors,
 			IO:          io,
@@ -217,8 +218,7 @@ func analyzePython(rel string, content string) []contract.TestSubjectV1 {
 	lower := strings.ToLower(content)
 	risk, factors, io := scoreRisk(lower)
 
-	fnRe := regexp.MustCompile(`(?m)^\s*def\s+([A-Za-z_]\w*)\s*\(([^)]*)\)\s*:`)
-	for _, m := range fnRe.FindAllStringSubmatch(content, -1) {
+	for _, m := range pyFuncRe.FindAllStringSubmatch(content, -1) {
 		name := m[1]
 		out = append(out, contract.TestSubjectV1{
 			ID:          rel + "#fn:" + name,
@@ -280,8 +280,7 @@ func analyzeGo(rel string, content string) []contract.TestSubjectV1 {
 	lower := strings.ToLower(content)
 	risk, factors, io := scoreRisk(lower)
 
-	fnRe := regexp.MustCompile(`(?m)^\s*func\s*(?:\([^)]+\)\s*)?([A-Za-z_]\w*)\s*\(([^)]*)\)`)
-	for _, m := range fnRe.FindAllStringSubmatch(content, -1) {
+	for _, m := range goFuncRe.FindAllStringSubmatch(content, -1) {
 		name := m[1]
 		exported := len(name) > 0 && strings.ToUpper(name[:1]) == name[:1]
 		out = append(out, contract.TestSubjectV1{
@@ -0,0 +1,522 @@
+package adapters
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	contractpatch "github.com/megamake/megamake/internal/contracts/v1/patch"
+	contract "github.com/megamake/megamake/internal/contracts/v1/workflow"
+	chatapi "github.com/megamake/megamake/internal/domains/chat/api"
+	chatapp "github.com/megamake/megamake/internal/domains/chat/app"
+	chatports "github.com/megamake/megamake/internal/domains/chat/ports"
+	diagapi "github.com/megamake/megamake/internal/domains/diagnose/api"
+	diagapp "github.com/megamake/megamake/internal/domains/diagnose/app"
+	docapi "github.com/megamake/megamake/internal/domains/doc/api"
+	docapp "github.com/megamake/megamake/internal/domains/doc/app"
+	patchapi "github.com/megamake/megamake/internal/domains/patch/api"
+	patchapp "github.com/megamake/megamake/internal/domains/patch/app"
+	promptapi "github.com/megamake/megamake/internal/domains/prompt/api"
+	promptapp "github.com/megamake/megamake/internal/domains/prompt/app"
+	promptdomain "github.com/megamake/megamake/internal/domains/prompt/domain"
+	secureapi "github.com/megamake/megamake/internal/domains/secure/api"
+	secureapp "github.com/megamake/megamake/internal/domains/secure/app"
+	tpapi "github.com/megamake/megamake/internal/domains/testplan/api"
+	tpapp "github.com/megamake/megamake/internal/domains/testplan/app"
+	"github.com/megamake/megamake/internal/domains/workflow/ports"
+)
+
+const (
+	defaultChatTimeoutSeconds = 600
+	chatPollInterval          = 250 * time.Millisecond
+	chatOutputLimitBytes      = 2_000_000
+)
+
+// DomainStepRunner runs workflow steps by calling the other domains' APIs in-process.
+type DomainStepRunner struct {
+	Prompt   promptapi.API
+	Doc      docapi.API
+	Diagnose diagapi.API
+	TestPlan tpapi.API
+	Secure   secureapi.API
+	Patch    patchapi.API
+	Chat     chatapi.API
+}
+
+// commonKeys are accepted by every repo-scanning step.
+var commonKeys = []string{"force", "maxFileBytes", "ignore"}
+
+// scanKeys add the include/exclude overrides accepted by the file-scanning steps.
+var scanKeys = append([]string{"includeExt", "includeGlob", "excludeExt"}, commonKeys...)
+
+func (r DomainStepRunner) RunStep(req ports.StepRequest) (ports.StepOutput, error) {
+	w := withArgs{m: req.With}
+
+	switch req.Type {
+	case contract.StepPrompt:
+		if err := w.only(append([]string{"maxTokens", "focus", "depth", "dependents", "since", "range", "format", "noRedact", "splitBytes", "splitTokens", "skeleton", "skeletonExcept", "explainIgnores"}, scanKeys...)...); err != nil {
+			return ports.StepOutput{}, err
+		}
+		if r.Prompt == nil {
+			return ports.StepOutput{}, fmt.Errorf("internal error: Prompt API is nil")
+		}
+		names, globs := w.ignores(req)
+		res, err := r.Prompt.Generate(promptapp.GenerateRequest{
+			RootPath:        req.RootPath,
+			ArtifactDir:     req.ArtifactDir,
+			Force:           w.boolean("force", false),
+			MaxFileBytes:    w.int64("maxFileBytes", 0),
+			IgnoreNames:     names,
+			IgnoreGlobs:     globs,
+			IncludeExts:     w.list("includeExt"),
+			IncludeGlobs:    w.list("includeGlob"),
+			ExcludeExts:     w.list("excludeExt"),
+			ExplainIgnores:  w.boolean("explainIgnores", false),
+			Focus:           w.str("focus", ""),
+			FocusDepth:      w.integer("depth", 1),
+			FocusDependents: w.boolean("dependents", false),
+			SinceRef:        w.str("since", ""),
+			Range:           w.str("range", ""),
+			Format:          promptdomain.ContextFormat(w.str("format", "")),
+			MaxTokens:       w.integer("maxTokens", 0),
+			Skeleton:        w.boolean("skeleton", false),
+			SkeletonExcept:  w.list("skeletonExcept"),
+			NoRedact:        w.boolean("noRedact", false),
+			SplitBytes:      w.integer("splitBytes", 0),
+			SplitTokens:     w.integer("splitTokens", 0),
+		})
+		if err != nil {
+			return ports.StepOutput{}, err
+		}
+		return ports.StepOutput{ArtifactPath: res.ArtifactPath, Outputs: map[string]string{
+			"filesScanned":  strconv.Itoa(res.Report.FilesScanned),
+			"filesIncluded": strconv.Itoa(res.Report.FilesIncluded),
+			"tokens":        strconv.Itoa(res.Report.EstimatedTokens),
+			"parts":         strconv.Itoa(len(res.PartPaths)),
+			"context":       res.ContextXML,
+			"prompt":        res.AgentPrompt,
+		}}, nil
+
+	case contract.StepDoc:
+		if err := w.only(append([]string{"maxAnalyzeBytes", "treeDepth", "uml", "umlGranularity", "umlMaxNodes", "umlIncludeIO", "umlIncludeEndpoints"}, scanKeys...)...); err != nil {
+			return ports.StepOutput{}, err
+		}
+		if r.Doc == nil {
+			return ports.StepOutput{}, fmt.Errorf("internal error: Doc API is nil")
+		}
+		names, globs := w.ignores(req)
+		res, err := r.Doc.Create(docapp.CreateRequest{
+			RootPath:            req.RootPath,
+			ArtifactDir:         req.ArtifactDir,
+			Force:               w.boolean("force", false),
+			MaxFileBytes:        w.int64("maxFileBytes", 0),
+			MaxAnalyzeBytes:     w.int64("maxAnalyzeBytes", 0),
+			TreeDepth:           w.integer("treeDepth", 0),
+			UMLFormats:          w.str("uml", ""),
+			UMLGranularity:      w.str("umlGranularity", ""),
+			UMLMaxNodes:         w.integer("umlMaxNodes", 0),
+			UMLIncludeIO:        w.boolean("umlIncludeIO", true),
+			UMLIncludeEndpoints: w.boolean("umlIncludeEndpoints", true),
+			IgnoreNames:         names,
+			IgnoreGlobs:         globs,
+			IncludeExts:         w.list("includeExt"),
+			IncludeGlobs:        w.list("includeGlob"),
+			ExcludeExts:         w.list("excludeExt"),
+			NetEnabled:          req.Policy.NetEnabled,
+			AllowDomains:        req.Policy.AllowDomains,
+		})
+		if err != nil {
+			return ports.StepOutput{}, err
+		}
+		return ports.StepOutput{ArtifactPath: res.ArtifactPath, Outputs: map[string]string{
+			"prompt": res.PromptText,
+		}}, nil
+
+	case contract.StepDiagnose:
+		if err := w.only(append([]string{"includeTests", "timeoutSeconds"}, commonKeys...)...); err != nil {
+			return ports.StepOutput{}, err
+		}
+		if r.Diagnose == nil {
+			return ports.StepOutput{}, fmt.Errorf("internal error: Diagnose API is nil")
+		}
+		names, globs := w.ignores(req)
+		res, err := r.Diagnose.Diagnose(diagapp.DiagnoseRequest{
+			RootPath:       req.RootPath,
+			ArtifactDir:    req.ArtifactDir,
+			Force:          w.boolean("force", false),
+			TimeoutSeconds: w.integer("timeoutSeconds", 120),
+			IncludeTests:   w.boolean("includeTests", false),
+			MaxFileBytes:   w.int64("maxFileBytes", 0),
+			IgnoreNames:    names,
+			IgnoreGlobs:    globs,
+			NetEnabled:     req.Policy.NetEnabled,
+			AllowDomains:   req.Policy.AllowDomains,
+		})
+		if err != nil {
+			return ports.StepOutput{}, err
+		}
+		issues, errs, warns := 0, 0, 0
+		for _, ld := range res.Report.Languages {
+			issues += len(ld.Issues)
+			for _, d := range ld.Issues {
+				switch string(d.Severity) {
+				case "error":
+					errs++
+				case "warning":
+					warns++
+				}
+			}
+		}
+		return ports.StepOutput{ArtifactPath: res.ArtifactPath, Outputs: map[string]string{
+			"issues":   strconv.Itoa(issues),
+			"errors":   strconv.Itoa(errs),
+			"warnings": strconv.Itoa(warns),
+			"prompt":   res.FixPrompt,
+		}}, nil
+
+	case contract.StepTest:
+		if err := w.only(append([]string{"levels", "limitSubjects", "maxAnalyzeBytes", "since", "range", "noRegression"}, scanKeys...)...); err != nil {
+			return ports.StepOutput{}, err
+		}
+		if r.TestPlan == nil {
+			return ports.StepOutput{}, fmt.Errorf("internal error: TestPlan API is nil")
+		}
+		names, globs := w.ignores(req)
+		res, err := r.TestPlan.Build(tpapp.BuildRequest{
+			RootPath:        req.RootPath,
+			ArtifactDir:     req.ArtifactDir,
+			Force:           w.boolean("force", false),
+			LimitSubjects:   w.integer("limitSubjects", 0),
+			LevelsCSV:       w.csv("levels"),
+			MaxFileBytes:    w.int64("maxFileBytes", 0),
+			MaxAnalyzeBytes: w.int64("maxAnalyzeBytes", 0),
+			IgnoreNames:     names,
+			IgnoreGlobs:     globs,
+			IncludeExts:     w.list("includeExt"),
+			IncludeGlobs:    w.list("includeGlob"),
+			ExcludeExts:     w.list("excludeExt"),
+			Regression: tpapp.RegressionMode{
+				Disabled: w.boolean("noRegression", false),
+				SinceRef: w.str("since", ""),
+				Range:    w.str("range", ""),
+			},
+			NetEnabled:   req.Policy.NetEnabled,
+			AllowDomains: req.Policy.AllowDomains,
+		})
+		if err != nil {
+			return ports.StepOutput{}, err
+		}
+		return ports.StepOutput{ArtifactPath: res.ArtifactPath, Outputs: map[string]string{
+			"subjects":  strconv.Itoa(res.Report.Summary.TotalSubjects),
+			"scenarios": strconv.Itoa(res.Report.Summary.TotalScenarios),
+			"prompt":    res.TestPrompt,
+		}}, nil
+
+	case contract.StepSecure:
+		if err := w.only(append([]string{"includeTests", "minSeverity"}, scanKeys...)...); err != nil {
+			return ports.StepOutput{}, err
+		}
+		if r.Secure == nil {
+			return ports.StepOutput{}, fmt.Errorf("internal error: Secure API is nil")
+		}
+		minSev, err := secureapp.ParseSeverity(w.str("minSeverity", ""))
+		if err != nil {
+			return ports.StepOutput{}, err
+		}
+		names, globs := w.ignores(req)
+		res, err := r.Secure.Scan(secureapp.ScanRequest{
+			RootPath:     req.RootPath,
+			ArtifactDir:  req.ArtifactDir,
+			Force:        w.boolean("force", false),
+			IncludeTests: w.boolean("includeTests", false),
+			MinSeverity:  minSev,
+			MaxFileBytes: w.int64("maxFileBytes", 0),
+			IgnoreNames:  names,
+			IgnoreGlobs:  globs,
+			IncludeExts:  w.list("includeExt"),
+			IncludeGlobs: w.list("includeGlob"),
+			ExcludeExts:  w.list("excludeExt"),
+			NetEnabled:   req.Policy.NetEnabled,
+			AllowDomains: req.Policy.AllowDomains,
+		})
+		if err != nil {
+			return ports.StepOutput{}, err
+		}
+		s := res.Report.Summary
+		return ports.StepOutput{ArtifactPath: res.ArtifactPath, Outputs: map[string]string{
+			"findings": strconv.Itoa(s.Total),
+			"critical": strconv.Itoa(s.Critical),
+			"high":     strconv.Itoa(s.High),
+			"medium":   strconv.Itoa(s.Medium),
+			"low":      strconv.Itoa(s.Low),
+			"prompt":   res.RemediationPrompt,
+		}}, nil
+
+	case contract.StepPatch:
+		if err := w.only("script", "file", "dryRun"); err != nil {
+			return ports.StepOutput{}, err
+		}
+		if r.Patch == nil {
+			return ports.StepOutput{}, fmt.Errorf("internal error: Patch API is nil")
+		}
+		script := w.str("script", "")
+		source := "workflow step " + req.ID
+		if file := w.str("file", ""); file != "" {
+			if script != "" {
+				return ports.StepOutput{}, fmt.Errorf("patch step: set either script or file, not both")
+			}
+			p := file
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(req.RootPath, filepath.FromSlash(p))
+			}
+			b, err := os.ReadFile(p)
+			if err != nil {
+				return ports.StepOutput{}, fmt.Errorf("patch step: %v", err)
+			}
+			script = string(b)
+			source = p
+		}
+		if strings.TrimSpace(script) == "" {
+			return ports.StepOutput{}, fmt.Errorf("patch step: script (or file) is required")
+		}
+		res, err := r.Patch.Apply(patchapp.ApplyRequest{
+			RootPath:     req.RootPath,
+			ArtifactDir:  req.ArtifactDir,
+			Script:       script,
+			Source:       source,
+			DryRun:       w.boolean("dryRun", false),
+			AllowDelete:  req.Policy.AllowDelete,
+			NetEnabled:   req.Policy.NetEnabled,
+			AllowDomains: req.Policy.AllowDomains,
+		})
+		if err != nil {
+			return ports.StepOutput{}, err
+		}
+		s := res.Report.Summary
+		out := ports.StepOutput{ArtifactPath: res.ArtifactPath, Outputs: map[string]string{
+			"patchStatus": string(res.Report.Status),
+			"operations":  strconv.Itoa(s.Operations),
+			"created":     strconv.Itoa(s.Created),
+			"modified":    strconv.Itoa(s.Modified),
+			"deleted":     strconv.Itoa(s.Deleted),
+		}}
+		switch res.Report.Status {
+		case contractpatch.PatchStatusBlocked, contractpatch.PatchStatusRolledBack:
+			return out, fmt.Errorf("patch %s: %s", res.Report.Status, res.Report.Error)
+		}
+		return out, nil
+
+	case contract.StepChat:
+		if err := w.only("run", "title", "provider", "model", "system", "message", "timeoutSeconds"); err != nil {
+			return ports.StepOutput{}, err
+		}
+		return r.runChat(req, w)
+
+	default:
+		return ports.StepOutput{}, fmt.Errorf("unsupported step type: %s", req.Type)
+	}
+}
+
+// runChat sends one chat turn and waits for the reply.
+// Provider network access is enforced by the chat domain using req.Policy.
+func (r DomainStepRunner) runChat(req ports.StepRequest, w withArgs) (ports.StepOutput, error) {
+	if r.Chat == nil {
+		return ports.StepOutput{}, fmt.Errorf("internal error: Chat API is nil")
+	}
+	message := w.str("message", "")
+	if strings.TrimSpace(message) == "" {
+		return ports.StepOutput{}, fmt.Errorf("chat step: message is required")
+	}
+
+	runName := w.str("run", "")
+	if runName == "" {
+		title := w.str("title", "")
+		if title == "" {
+			title = "workflow " + req.ID
+		}
+		nr, err := r.Chat.NewRun(chatapp.NewRunRequest{
+			ArtifactDir: req.ChatArtifactDir,
+			Title:       title,
+			Provider:    w.str("provider", ""),
+			Model:       w.str("model", ""),
+			SystemText:  w.str("system", ""),
+		})
+		if err != nil {
+			return ports.StepOutput{}, err
+		}
+		runName = nr.RunName
+	}
+
+	job, err := r.Chat.RunAsync(chatapp.RunAsyncRequest{
+		ArtifactDir:  req.ChatArtifactDir,
+		RunName:      runName,
+		Message:      message,
+		NetEnabled:   req.Policy.NetEnabled,
+		AllowDomains: req.Policy.AllowDomains,
+	})
+	if err != nil {
+		return ports.StepOutput{}, err
+	}
+
+	out := ports.StepOutput{Outputs: map[string]string{
+		"run":  runName,
+		"turn": strconv.Itoa(job.Turn),
+	}}
+
+	deadline := time.Now().Add(time.Duration(w.integer("timeoutSeconds", defaultChatTimeoutSeconds)) * time.Second)
+	for {
+		st, err := r.Chat.JobStatus(chatapp.JobStatusRequest{JobID: job.JobID})
+		if err != nil {
+			return out, err
+		}
+		switch st.Job.Status {
+		case chatports.JobDone:
+			tail, err := r.Chat.JobTail(chatapp.JobTailRequest{
+				ArtifactDir: req.ChatArtifactDir,
+				JobID:       job.JobID,
+				Limit:       chatOutputLimitBytes,
+			})
+			if err != nil {
+				return out, err
+			}
+			out.Outputs["output"] = tail.Text
+			return out, nil
+		case chatports.JobError:
+			return out, fmt.Errorf("chat turn failed: %s", st.Job.Error)
+		case chatports.JobCanceled:
+			return out, fmt.Errorf("chat turn was canceled")
+		}
+		if time.Now().After(deadline) {
+			_, _ = r.Chat.CancelJob(chatapp.CancelJobRequest{JobID: job.JobID})
+			return out, fmt.Errorf("chat turn timed out")
+		}
+		time.Sleep(chatPollInterval)
+	}
+}
+
+// withArgs is a typed view over a step's interpolated "with" block.
+type withArgs struct {
+	m map[string]any
+}
+
+// only rejects keys the step type does not understand, so typos fail loudly.
+func (w withArgs) only(allowed ...string) error {
+	ok := map[string]bool{}
+	for _, k := range allowed {
+		ok[k] = true
+	}
+	var bad []string
+	for k := range w.m {
+		if !ok[k] {
+			bad = append(bad, k)
+		}
+	}
+	if len(bad) == 0 {
+		return nil
+	}
+	sort.Strings(bad)
+	sort.Strings(allowed)
+	return fmt.Errorf("unknown with keys: %s (allowed: %s)", strings.Join(bad, ", "), strings.Join(allowed, ", "))
+}
+
+func (w withArgs) str(key string, def string) string {
+	v, ok := w.m[key]
+	if !ok || v == nil {
+		return def
+	}
+	switch x := v.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+func (w withArgs) boolean(key string, def bool) bool {
+	v, ok := w.m[key]
+	if !ok || v == nil {
+		return def
+	}
+	switch x := v.(type) {
+	case bool:
+		return x
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(x)); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+func (w withArgs) int64(key string, def int64) int64 {
+	v, ok := w.m[key]
+	if !ok || v == nil {
+		return def
+	}
+	switch x := v.(type) {
+	case float64:
+		return int64(x)
+	case string:
+		if n, err := strconv.ParseInt(strings.TrimSpace(x), 10, 64); err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+func (w withArgs) integer(key string, def int) int {
+	return int(w.int64(key, int64(def)))
+}
+
+// list accepts either a YAML/JSON list or a comma-separated string.
+func (w withArgs) list(key string) []string {
+	v, ok := w.m[key]
+	if !ok || v == nil {
+		return nil
+	}
+	var out []string
+	switch x := v.(type) {
+	case []any:
+		for _, it := range x {
+			if s := strings.TrimSpace(fmt.Sprint(it)); s != "" {
+				out = append(out, s)
+			}
+		}
+	case string:
+		for _, s := range strings.Split(x, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func (w withArgs) csv(key string) string {
+	return strings.Join(w.list(key), ",")
+}
+
+// ignores merges the invocation-wide ignores with the step's own "ignore" list,
+// using the same name-vs-glob split as the CLI --ignore flag.
+func (w withArgs) ignores(req ports.StepRequest) ([]string, []string) {
+	names := append([]string(nil), req.IgnoreNames...)
+	globs := append([]string(nil), req.IgnoreGlobs...)
+	for _, raw := range w.list("ignore") {
+		s := strings.Trim(strings.TrimPrefix(strings.ReplaceAll(raw, "\\", "/"), "./"), "/")
+		if s == "" {
+			continue
+		}
+		if strings.ContainsAny(s, "*?/") {
+			globs = append(globs, s)
+		} else {
+			names = append(names, s)
+		}
+	}
+	return names, globs
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	workflowapp "github.com/megamake/megamake/internal/domains/workflow/app"
+	"github.com/megamake/megamake/internal/domains/workflow/domain"
+	workflowports "github.com/megamake/megamake/internal/domains/workflow/ports"
+	"github.com/megamake/megamake/internal/platform/clock"
+)
+
+type API interface {
+	Run(req workflowapp.RunRequest) (workflowapp.RunResult, error)
+	Validate(data []byte, workflowFile string) (domain.Spec, error)
+}
+
+type Dependencies struct {
+	Clock          clock.Clock
+	Steps          workflowports.StepRunner
+	ArtifactWriter workflowports.ArtifactWriter
+}
+
+func New(deps Dependencies) API {
+	return &workflowAPI{
+		svc: &workflowapp.Service{
+			Clock:          deps.Clock,
+			Steps:          deps.Steps,
+			ArtifactWriter: deps.ArtifactWriter,
+		},
+	}
+}
+
+type workflowAPI struct {
+	svc *workflowapp.Service
+}
+
+func (a *workflowAPI) Run(req workflowapp.RunRequest) (workflowapp.RunResult, error) {
+	return a.svc.Run(req)
+}
+
+func (a *workflowAPI) Validate(data []byte, workflowFile string) (domain.Spec, error) {
+	return a.svc.Validate(data, workflowFile)
+}
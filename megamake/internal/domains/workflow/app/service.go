@@ -0,0 +1,352 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	contractartifact "github.com/megamake/megamake/internal/contracts/v1/artifact"
+	contract "github.com/megamake/megamake/internal/contracts/v1/workflow"
+	"github.com/megamake/megamake/internal/domains/workflow/domain"
+	"github.com/megamake/megamake/internal/domains/workflow/ports"
+	"github.com/megamake/megamake/internal/platform/clock"
+	"github.com/megamake/megamake/internal/platform/policy"
+)
+
+// maxReportedOutputBytes bounds how much of each step output is copied into the report.
+// Full values stay available to later steps; large text (prompts, chat replies) lives
+// in the step's own artifact.
+const maxReportedOutputBytes = 256
+
+type Service struct {
+	Clock          clock.Clock
+	Steps          ports.StepRunner
+	ArtifactWriter ports.ArtifactWriter
+}
+
+type RunRequest struct {
+	// RootPath overrides the workflow's root. If empty, the workflow "root" field is
+	// resolved against WorkflowDir (falling back to WorkflowDir itself).
+	RootPath    string
+	ArtifactDir string
+
+	ChatArtifactDir string
+
+	// Workflow is the raw file content; WorkflowFile is its path (used for format
+	// detection and reporting) and WorkflowDir its directory.
+	Workflow     []byte
+	WorkflowFile string
+	WorkflowDir  string
+
+	IgnoreNames []string
+	IgnoreGlobs []string
+
+	NetEnabled   bool
+	AllowDomains []string
+	AllowDelete  bool
+	Args         []string
+
+	// OnStep, if set, is called after each step finishes (or is skipped), for progress output.
+	OnStep func(contract.StepResultV1)
+}
+
+type RunResult struct {
+	Report      contract.WorkflowReportV1
+	ReportXML   string
+	ReportJSON  string
+	AgentPrompt string
+
+	ArtifactPath string
+	LatestPath   string
+}
+
+// Validate parses and validates a workflow without running it.
+func (s *Service) Validate(data []byte, workflowFile string) (domain.Spec, error) {
+	return domain.ParseSpec(data, workflowFile)
+}
+
+// Run executes the workflow steps in order and writes one MEGAMAKE summary artifact.
+//
+// A step runs only if its condition holds; it is attempted 1+retries times. When a step
+// fails without continueOnError, the remaining steps are skipped and the run fails.
+func (s *Service) Run(req RunRequest) (RunResult, error) {
+	if s.Clock == nil {
+		return RunResult{}, fmt.Errorf("internal error: Clock is nil")
+	}
+	if s.Steps == nil {
+		return RunResult{}, fmt.Errorf("internal error: Steps is nil")
+	}
+	if s.ArtifactWriter == nil {
+		return RunResult{}, fmt.Errorf("internal error: ArtifactWriter is nil")
+	}
+
+	spec, err := domain.ParseSpec(req.Workflow, req.WorkflowFile)
+	if err != nil {
+		return RunResult{}, err
+	}
+
+	if strings.TrimSpace(req.WorkflowDir) == "" {
+		req.WorkflowDir = "."
+	}
+	if strings.TrimSpace(req.RootPath) == "" {
+		req.RootPath = req.WorkflowDir
+		if r := strings.TrimSpace(spec.Root); r != "" {
+			if filepath.IsAbs(r) {
+				req.RootPath = r
+			} else {
+				req.RootPath = filepath.Join(req.WorkflowDir, filepath.FromSlash(r))
+			}
+		}
+	}
+	if strings.TrimSpace(req.ArtifactDir) == "" {
+		req.ArtifactDir = req.RootPath
+	}
+	if strings.TrimSpace(req.ChatArtifactDir) == "" {
+		req.ChatArtifactDir = req.ArtifactDir
+	}
+
+	pol := policy.Policy{
+		NetEnabled:   req.NetEnabled,
+		AllowDomains: cloneStrings(req.AllowDomains),
+		AllowDelete:  req.AllowDelete,
+	}
+
+	now := s.Clock.NowUTC()
+
+	// outputs[id] holds the full outputs of finished steps, including "status".
+	outputs := map[string]map[string]string{}
+	declared := map[string]bool{}
+	for _, st := range spec.Steps {
+		declared[st.ID] = true
+	}
+	lookup := func(ref string) (string, error) {
+		parts := strings.SplitN(ref, ".", 3)
+		if len(parts) != 3 || parts[0] != "steps" {
+			return "", fmt.Errorf("unknown reference %q (expected steps.<id>.<output>)", ref)
+		}
+		if !declared[parts[1]] {
+			return "", fmt.Errorf("unknown step %q in reference %q", parts[1], ref)
+		}
+		return outputs[parts[1]][parts[2]], nil
+	}
+
+	report := contract.WorkflowReportV1{
+		GeneratedAt:  contractartifact.FormatRFC3339NanoUTC(now),
+		RootPath:     req.RootPath,
+		WorkflowFile: req.WorkflowFile,
+		Name:         spec.Name,
+		Status:       contract.WorkflowOK,
+	}
+
+	halted := ""
+	for _, st := range spec.Steps {
+		res := contract.StepResultV1{
+			ID:        st.ID,
+			Type:      contract.StepTypeV1(st.Type),
+			Condition: strings.TrimSpace(st.If),
+		}
+
+		switch {
+		case halted != "":
+			res.Status = contract.StepSkipped
+			res.SkipReason = "step " + halted + " failed"
+		case res.Condition != "":
+			ok, err := domain.EvalCondition(res.Condition, lookup)
+			if err != nil {
+				res.Status = contract.StepFailed
+				res.Error = "condition: " + err.Error()
+			} else if !ok {
+				res.Status = contract.StepSkipped
+				res.SkipReason = "condition not met"
+			}
+		}
+
+		if res.Status == "" {
+			s.runStep(&res, st, req, pol, lookup)
+		}
+
+		out := map[string]string{}
+		for k, v := range res.Outputs {
+			out[k] = v
+		}
+		out["status"] = string(res.Status)
+		out["error"] = res.Error
+		outputs[st.ID] = out
+
+		if res.Status == contract.StepFailed && !st.ContinueOnError && halted == "" {
+			halted = st.ID
+			report.Status = contract.WorkflowFailed
+		}
+		if res.Status == contract.StepFailed && st.ContinueOnError {
+			report.Warnings = append(report.Warnings, "step "+st.ID+" failed (continueOnError): "+res.Error)
+		}
+
+		report.Steps = append(report.Steps, res)
+		if req.OnStep != nil {
+			req.OnStep(res)
+		}
+	}
+
+	// Keep the per-step outputs map in the report trimmed to scalar-sized values.
+	for i := range report.Steps {
+		report.Steps[i].Outputs = reportOutputs(outputs[report.Steps[i].ID])
+	}
+	report.Summary = summarize(report.Steps)
+
+	reportXML := report.ToXML()
+	jb, _ := json.MarshalIndent(report, "", "  ")
+	reportJSON := string(jb)
+	agentPrompt := domain.GenerateWorkflowPrompt(report)
+
+	meta := contractartifact.ArtifactMetaV1{
+		Tool:         "megamake",
+		Contract:     "v1",
+		GeneratedAt:  contractartifact.FormatRFC3339NanoUTC(now),
+		RootPath:     req.RootPath,
+		Args:         req.Args,
+		NetEnabled:   req.NetEnabled,
+		AllowDomains: cloneStrings(req.AllowDomains),
+		Warnings:     report.Warnings,
+	}
+
+	env := contractartifact.ArtifactEnvelopeV1{
+		Meta:   meta,
+		XML:    reportXML,
+		JSON:   reportJSON,
+		Prompt: agentPrompt,
+	}
+
+	artifactPath, latestPath, err := s.ArtifactWriter.WriteToolArtifact(ports.WriteArtifactRequest{
+		ArtifactDir:    req.ArtifactDir,
+		ToolPrefix:     "MEGAMAKE",
+		Envelope:       env,
+		GeneratedAtUTC: timePtr(now),
+	})
+	if err != nil {
+		return RunResult{}, err
+	}
+
+	return RunResult{
+		Report:       report,
+		ReportXML:    reportXML,
+		ReportJSON:   reportJSON,
+		AgentPrompt:  agentPrompt,
+		ArtifactPath: artifactPath,
+		LatestPath:   latestPath,
+	}, nil
+}
+
+// runStep interpolates the step inputs and runs it with retries, filling res in place.
+func (s *Service) runStep(res *contract.StepResultV1, st domain.StepSpec, req RunRequest, pol policy.Policy, lookup domain.Lookup) {
+	started := s.Clock.NowUTC()
+	res.StartedAt = contractartifact.FormatRFC3339NanoUTC(started)
+
+	with := map[string]any{}
+	if len(st.With) > 0 {
+		v, err := domain.Interpolate(map[string]any(st.With), lookup)
+		if err != nil {
+			res.Status = contract.StepFailed
+			res.Error = "with: " + err.Error()
+			return
+		}
+		with = v.(map[string]any)
+	}
+
+	stepReq := ports.StepRequest{
+		ID:              st.ID,
+		Type:            contract.StepTypeV1(st.Type),
+		With:            with,
+		RootPath:        req.RootPath,
+		ArtifactDir:     req.ArtifactDir,
+		ChatArtifactDir: req.ChatArtifactDir,
+		IgnoreNames:     cloneStrings(req.IgnoreNames),
+		IgnoreGlobs:     cloneStrings(req.IgnoreGlobs),
+		Policy:          pol,
+	}
+
+	var out ports.StepOutput
+	var err error
+	for attempt := 1; attempt <= 1+st.Retries; attempt++ {
+		res.Attempts = attempt
+		out, err = s.Steps.RunStep(stepReq)
+		if err == nil {
+			break
+		}
+		if attempt <= st.Retries && st.RetryDelaySeconds > 0 {
+			time.Sleep(time.Duration(st.RetryDelaySeconds) * time.Second)
+		}
+	}
+
+	res.DurationMs = s.Clock.NowUTC().Sub(started).Milliseconds()
+	res.Artifact = out.ArtifactPath
+	if err != nil {
+		res.Status = contract.StepFailed
+		res.Error = err.Error()
+	} else {
+		res.Status = contract.StepOK
+	}
+
+	// Full outputs are read by later steps; Run adds status/error and trims them for the report.
+	full := map[string]string{}
+	for k, v := range out.Outputs {
+		full[k] = v
+	}
+	if out.ArtifactPath != "" {
+		full["artifact"] = out.ArtifactPath
+	}
+	full["attempts"] = strconv.Itoa(res.Attempts)
+	res.Outputs = full
+}
+
+// reportOutputs copies outputs for the report, replacing large values with their size.
+func reportOutputs(full map[string]string) map[string]string {
+	if len(full) == 0 {
+		return nil
+	}
+	out := map[string]string{}
+	for k, v := range full {
+		if v == "" {
+			continue
+		}
+		if len(v) > maxReportedOutputBytes {
+			v = "(" + strconv.Itoa(len(v)) + " bytes)"
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func summarize(steps []contract.StepResultV1) contract.WorkflowSummaryV1 {
+	s := contract.WorkflowSummaryV1{Total: len(steps)}
+	for _, st := range steps {
+		switch st.Status {
+		case contract.StepOK:
+			s.OK++
+		case contract.StepFailed:
+			s.Failed++
+		case contract.StepSkipped:
+			s.Skipped++
+		}
+	}
+	return s
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
+
+func cloneStrings(xs []string) []string {
+	if len(xs) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(xs))
+	for _, x := range xs {
+		x = strings.TrimSpace(x)
+		if x == "" {
+			continue
+		}
+		out = append(out, x)
+	}
+	return out
+}
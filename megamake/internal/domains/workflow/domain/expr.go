@@ -0,0 +1,339 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Lookup resolves a reference such as "steps.diag.errors" to its string value.
+// It returns an error for references that cannot exist (unknown step, malformed path).
+type Lookup func(ref string) (string, error)
+
+// EvalCondition evaluates a step condition.
+//
+// Grammar (lowest to highest precedence):
+//
+//	expr  := and ("||" and)*
+//	and   := unary ("&&" unary)*
+//	unary := "!" unary | "(" expr ")" | cmp
+//	cmp   := value [("==" | "!=" | ">" | ">=" | "<" | "<=") value]
+//	value := ref | "string" | 'string' | number | true | false
+//
+// Values compare numerically when both sides are numbers and as strings otherwise.
+// A bare value is true unless it is empty, "0" or "false".
+//
+// Example: steps.diag.errors > 0 && steps.diag.status == "ok"
+func EvalCondition(expr string, lookup Lookup) (bool, error) {
+	n, err := parseExpr(expr)
+	if err != nil {
+		return false, err
+	}
+	return n.eval(lookup)
+}
+
+var placeholderRe = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// Interpolate replaces {{ref}} placeholders in every string inside v (recursively).
+func Interpolate(v any, lookup Lookup) (any, error) {
+	switch x := v.(type) {
+	case string:
+		var firstErr error
+		out := placeholderRe.ReplaceAllStringFunc(x, func(m string) string {
+			ref := strings.TrimSpace(placeholderRe.FindStringSubmatch(m)[1])
+			val, err := lookup(ref)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			return val
+		})
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return out, nil
+	case []any:
+		out := make([]any, 0, len(x))
+		for _, it := range x {
+			r, err := Interpolate(it, lookup)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, r)
+		}
+		return out, nil
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, it := range x {
+			r, err := Interpolate(it, lookup)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = r
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// --- parser ---
+
+type exprNode interface {
+	eval(lookup Lookup) (bool, error)
+}
+
+type orNode struct{ l, r exprNode }
+type andNode struct{ l, r exprNode }
+type notNode struct{ x exprNode }
+
+type cmpNode struct {
+	op   string // "" for a bare value
+	l, r operand
+}
+
+type operand struct {
+	literal string
+	ref     string // set when the operand is a reference
+}
+
+func (n orNode) eval(lookup Lookup) (bool, error) {
+	l, err := n.l.eval(lookup)
+	if err != nil || l {
+		return l, err
+	}
+	return n.r.eval(lookup)
+}
+
+func (n andNode) eval(lookup Lookup) (bool, error) {
+	l, err := n.l.eval(lookup)
+	if err != nil || !l {
+		return false, err
+	}
+	return n.r.eval(lookup)
+}
+
+func (n notNode) eval(lookup Lookup) (bool, error) {
+	x, err := n.x.eval(lookup)
+	return !x, err
+}
+
+func (n cmpNode) eval(lookup Lookup) (bool, error) {
+	l, err := n.l.value(lookup)
+	if err != nil {
+		return false, err
+	}
+	if n.op == "" {
+		l = strings.TrimSpace(l)
+		return l != "" && l != "0" && !strings.EqualFold(l, "false"), nil
+	}
+	r, err := n.r.value(lookup)
+	if err != nil {
+		return false, err
+	}
+
+	lf, lerr := strconv.ParseFloat(strings.TrimSpace(l), 64)
+	rf, rerr := strconv.ParseFloat(strings.TrimSpace(r), 64)
+	if lerr == nil && rerr == nil {
+		switch n.op {
+		case "==":
+			return lf == rf, nil
+		case "!=":
+			return lf != rf, nil
+		case ">":
+			return lf > rf, nil
+		case ">=":
+			return lf >= rf, nil
+		case "<":
+			return lf < rf, nil
+		case "<=":
+			return lf <= rf, nil
+		}
+	}
+	switch n.op {
+	case "==":
+		return l == r, nil
+	case "!=":
+		return l != r, nil
+	default:
+		return false, fmt.Errorf("operator %s needs numeric operands (got %q and %q)", n.op, l, r)
+	}
+}
+
+func (o operand) value(lookup Lookup) (string, error) {
+	if o.ref == "" {
+		return o.literal, nil
+	}
+	return lookup(o.ref)
+}
+
+type exprParser struct {
+	toks []string
+	pos  int
+}
+
+func parseExpr(s string) (exprNode, error) {
+	toks, err := tokenizeExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	p := &exprParser{toks: toks}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %q", p.toks[p.pos])
+	}
+	return n, nil
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.pos++
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = orNode{l, r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = andNode{l, r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	switch p.peek() {
+	case "!":
+		p.pos++
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x}, nil
+	case "(":
+		p.pos++
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return x, nil
+	}
+
+	l, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	switch op := p.peek(); op {
+	case "==", "!=", ">", ">=", "<", "<=":
+		p.pos++
+		r, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return cmpNode{op: op, l: l, r: r}, nil
+	}
+	return cmpNode{l: l}, nil
+}
+
+func (p *exprParser) parseOperand() (operand, error) {
+	t := p.peek()
+	if t == "" {
+		return operand{}, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+	switch {
+	case t[0] == '"' || t[0] == '\'':
+		return operand{literal: t[1 : len(t)-1]}, nil
+	case t == "true" || t == "false":
+		return operand{literal: t}, nil
+	case isExprOperator(t):
+		return operand{}, fmt.Errorf("unexpected %q", t)
+	}
+	if _, err := strconv.ParseFloat(t, 64); err == nil {
+		return operand{literal: t}, nil
+	}
+	return operand{ref: t}, nil
+}
+
+func isExprOperator(t string) bool {
+	switch t {
+	case "(", ")", "!", "&&", "||", "==", "!=", ">", ">=", "<", "<=":
+		return true
+	}
+	return false
+}
+
+func tokenizeExpr(s string) ([]string, error) {
+	var toks []string
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '"' || c == '\'':
+			j := strings.IndexByte(s[i+1:], c)
+			if j < 0 {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			toks = append(toks, s[i:i+j+2])
+			i += j + 2
+		case strings.HasPrefix(s[i:], "&&"), strings.HasPrefix(s[i:], "||"),
+			strings.HasPrefix(s[i:], "=="), strings.HasPrefix(s[i:], "!="),
+			strings.HasPrefix(s[i:], ">="), strings.HasPrefix(s[i:], "<="):
+			toks = append(toks, s[i:i+2])
+			i += 2
+		case c == '(' || c == ')' || c == '!' || c == '>' || c == '<':
+			toks = append(toks, string(c))
+			i++
+		default:
+			j := i
+			for j < len(s) && (isWordByte(s[j])) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected character %q", string(c))
+			}
+			toks = append(toks, s[i:j])
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+func isWordByte(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '-' || c == '.'
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"strings"
+
+	contract "github.com/megamake/megamake/internal/contracts/v1/workflow"
+)
+
+// GenerateWorkflowPrompt summarizes a workflow run for an agent: what ran, where the
+// per-step artifacts are, and what to look at next when something failed.
+func GenerateWorkflowPrompt(report contract.WorkflowReportV1) string {
+	s := report.Summary
+
+	var lines []string
+	lines = append(lines, "You are an expert software engineer reviewing the result of a megamake workflow run.")
+	lines = append(lines, "")
+	lines = append(lines, "Context:")
+	name := report.Name
+	if strings.TrimSpace(name) == "" {
+		name = "(unnamed)"
+	}
+	lines = append(lines, "- Workflow: "+name+" ("+report.WorkflowFile+")")
+	lines = append(lines, "- Status: "+string(report.Status))
+	lines = append(lines, "- Steps: "+itoa(s.Total)+" ("+itoa(s.OK)+" ok, "+itoa(s.Failed)+" failed, "+itoa(s.Skipped)+" skipped)")
+	lines = append(lines, "")
+	lines = append(lines, "Steps:")
+	for _, st := range report.Steps {
+		line := "- " + st.ID + " [" + string(st.Type) + "]: " + string(st.Status)
+		if st.Attempts > 1 {
+			line += " after " + itoa(st.Attempts) + " attempts"
+		}
+		if st.SkipReason != "" {
+			line += " (" + st.SkipReason + ")"
+		}
+		lines = append(lines, line)
+		if st.Artifact != "" {
+			lines = append(lines, "    artifact: "+st.Artifact)
+		}
+		if st.Error != "" {
+			lines = append(lines, "    error: "+st.Error)
+		}
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, "Instructions:")
+	if report.Status == contract.WorkflowOK {
+		lines = append(lines, "- All required steps succeeded. Review the step artifacts above for follow-up work.")
+	} else {
+		lines = append(lines, "- Explain why each failed step failed, using its error and artifact.")
+		lines = append(lines, "- Propose the smallest change (to the project or to the workflow file) that lets the run succeed.")
+		lines = append(lines, "- If a step failed because of network or delete policy, say which global flag (--net, --allow-domain, --allow-delete) the user must consent to; do not work around the policy.")
+	}
+	return strings.Join(lines, "\n")
+}
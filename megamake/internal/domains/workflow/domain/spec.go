@@ -0,0 +1,209 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	contract "github.com/megamake/megamake/internal/contracts/v1/workflow"
+)
+
+// DefaultWorkflowFiles are looked up (in order) when no workflow path is given.
+var DefaultWorkflowFiles = []string{
+	"megamake.workflow.yaml",
+	"megamake.workflow.yml",
+	"megamake.workflow.json",
+}
+
+const maxRetries = 10
+
+// Spec is a parsed workflow file.
+type Spec struct {
+	Version int        `json:"version"`
+	Name    string     `json:"name"`
+	Root    string     `json:"root"` // relative to the workflow file's directory
+	Steps   []StepSpec `json:"steps"`
+}
+
+// StepSpec is one declared step.
+//
+// String values inside With may reference earlier steps with {{steps.<id>.<output>}};
+// If is a condition expression (see EvalCondition).
+type StepSpec struct {
+	ID                string         `json:"id"`
+	Type              string         `json:"type"`
+	If                string         `json:"if"`
+	Retries           int            `json:"retries"`
+	RetryDelaySeconds int            `json:"retryDelaySeconds"`
+	ContinueOnError   bool           `json:"continueOnError"`
+	With              map[string]any `json:"with"`
+}
+
+var (
+	stepIDRe  = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_\-]*$`)
+	stepRefRe = regexp.MustCompile(`\bsteps\.([A-Za-z][A-Za-z0-9_\-]*)\b`)
+)
+
+// ParseSpec parses a workflow from JSON or the supported YAML subset.
+// The format is chosen by file extension; unknown extensions are sniffed.
+// Unknown fields are rejected so typos fail loudly instead of being ignored.
+func ParseSpec(data []byte, fileName string) (Spec, error) {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	isJSON := ext == ".json" || (ext != ".yaml" && ext != ".yml" && bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")))
+
+	jsonData := data
+	if !isJSON {
+		v, err := parseYAML(string(data))
+		if err != nil {
+			return Spec{}, err
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return Spec{}, fmt.Errorf("workflow: %v", err)
+		}
+		jsonData = b
+	}
+
+	var spec Spec
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return Spec{}, fmt.Errorf("workflow: %v", err)
+	}
+	if err := spec.normalize(); err != nil {
+		return Spec{}, err
+	}
+	return spec, nil
+}
+
+// normalize applies defaults (ids) and validates types, retries and step references.
+func (s *Spec) normalize() error {
+	if s.Version != 0 && s.Version != 1 {
+		return fmt.Errorf("workflow: unsupported version %d (expected 1)", s.Version)
+	}
+	s.Version = 1
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("workflow: no steps defined")
+	}
+
+	known := map[string]bool{}
+	for _, t := range contract.KnownStepTypes() {
+		known[string(t)] = true
+	}
+
+	seen := map[string]bool{}
+	for i := range s.Steps {
+		st := &s.Steps[i]
+		where := "step " + itoa(i+1)
+
+		st.Type = strings.ToLower(strings.TrimSpace(st.Type))
+		if !known[st.Type] {
+			return fmt.Errorf("workflow: %s: unknown type %q (expected one of: %s)", where, st.Type, knownTypesCSV())
+		}
+
+		st.ID = strings.TrimSpace(st.ID)
+		if st.ID == "" {
+			st.ID = st.Type
+			for n := 2; seen[st.ID]; n++ {
+				st.ID = st.Type + "_" + itoa(n)
+			}
+		}
+		if !stepIDRe.MatchString(st.ID) {
+			return fmt.Errorf("workflow: %s: invalid id %q (letters, digits, _ and -; must start with a letter)", where, st.ID)
+		}
+		if seen[st.ID] {
+			return fmt.Errorf("workflow: %s: duplicate id %q", where, st.ID)
+		}
+
+		if st.Retries < 0 || st.Retries > maxRetries {
+			return fmt.Errorf("workflow: step %q: retries must be between 0 and %d", st.ID, maxRetries)
+		}
+		if st.RetryDelaySeconds < 0 {
+			return fmt.Errorf("workflow: step %q: retryDelaySeconds must not be negative", st.ID)
+		}
+
+		// References may only point at earlier steps: outputs flow forward.
+		refs := stepRefs(st.If)
+		for _, v := range st.With {
+			refs = append(refs, stepRefsInValue(v)...)
+		}
+		for _, ref := range refs {
+			if !seen[ref] {
+				return fmt.Errorf("workflow: step %q references %q, which is not an earlier step", st.ID, ref)
+			}
+		}
+		if strings.TrimSpace(st.If) != "" {
+			if _, err := parseExpr(st.If); err != nil {
+				return fmt.Errorf("workflow: step %q: invalid if: %v", st.ID, err)
+			}
+		}
+
+		seen[st.ID] = true
+	}
+	return nil
+}
+
+func stepRefs(s string) []string {
+	var out []string
+	for _, m := range stepRefRe.FindAllStringSubmatch(s, -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
+
+func stepRefsInValue(v any) []string {
+	switch x := v.(type) {
+	case string:
+		return stepRefs(x)
+	case []any:
+		var out []string
+		for _, it := range x {
+			out = append(out, stepRefsInValue(it)...)
+		}
+		return out
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var out []string
+		for _, k := range keys {
+			out = append(out, stepRefsInValue(x[k])...)
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func knownTypesCSV() string {
+	var xs []string
+	for _, t := range contract.KnownStepTypes() {
+		xs = append(xs, string(t))
+	}
+	return strings.Join(xs, ", ")
+}
+
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	var buf [32]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = byte('0' + (n % 10))
+		n /= 10
+	}
+	return sign + string(buf[i:])
+}
@@ -0,0 +1,470 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseYAML parses the YAML subset used by workflow files into generic values
+// (map[string]any, []any, string, int64, float64, bool, nil).
+//
+// Supported: block mappings and sequences (including "- key: value" items),
+// plain/single/double-quoted scalars, literal (|) and folded (>) block scalars with
+// chomping indicators, simple flow sequences/mappings ([a, b], {k: v}), comments,
+// and a leading "---" document marker. Anchors, aliases, tags, merge keys, explicit
+// keys, multi-document streams and scalars or flow collections spanning several lines
+// are not: they are reported with their line number rather than misparsed.
+func parseYAML(src string) (any, error) {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	p := &yamlParser{lines: strings.Split(src, "\n")}
+
+	// Skip a leading document marker.
+	if i, ok := p.peek(); ok && strings.TrimSpace(p.lines[i]) == "---" {
+		p.pos = i + 1
+	}
+
+	i, ok := p.peek()
+	if !ok {
+		return nil, nil
+	}
+	ind, _, err := p.lineAt(i)
+	if err != nil {
+		return nil, err
+	}
+	v, err := p.parseNode(ind)
+	if err != nil {
+		return nil, err
+	}
+	if j, ok := p.peek(); ok {
+		t := strings.TrimSpace(p.lines[j])
+		if t == "---" {
+			return nil, fmt.Errorf("yaml line %d: multiple documents are not supported", j+1)
+		}
+		if t != "..." {
+			return nil, fmt.Errorf("yaml line %d: unexpected content: %s", j+1, truncate(t, 60))
+		}
+	}
+	return v, nil
+}
+
+type yamlParser struct {
+	lines []string
+	pos   int
+}
+
+// peek returns the index of the next structural (non-blank, non-comment) line.
+func (p *yamlParser) peek() (int, bool) {
+	for i := p.pos; i < len(p.lines); i++ {
+		t := strings.TrimSpace(p.lines[i])
+		if t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		return i, true
+	}
+	return 0, false
+}
+
+// lineAt returns the indentation and comment-stripped content of line i.
+func (p *yamlParser) lineAt(i int) (int, string, error) {
+	raw := p.lines[i]
+	ind := 0
+	for ind < len(raw) && raw[ind] == ' ' {
+		ind++
+	}
+	if ind < len(raw) && raw[ind] == '\t' {
+		return 0, "", fmt.Errorf("yaml line %d: tabs are not allowed for indentation", i+1)
+	}
+	return ind, strings.TrimSpace(stripYAMLComment(raw[ind:])), nil
+}
+
+func (p *yamlParser) parseNode(ind int) (any, error) {
+	i, ok := p.peek()
+	if !ok {
+		return nil, nil
+	}
+	_, text, err := p.lineAt(i)
+	if err != nil {
+		return nil, err
+	}
+	if isSeqItem(text) {
+		return p.parseSeq(ind)
+	}
+	if _, _, ok := splitKeyValue(text); ok {
+		return p.parseMap(ind)
+	}
+	p.pos = i + 1
+	return parseYAMLScalar(text, i+1)
+}
+
+func (p *yamlParser) parseMap(ind int) (any, error) {
+	out := map[string]any{}
+	for {
+		i, ok := p.peek()
+		if !ok {
+			return out, nil
+		}
+		lineInd, text, err := p.lineAt(i)
+		if err != nil {
+			return nil, err
+		}
+		if lineInd < ind {
+			return out, nil
+		}
+		if lineInd > ind {
+			return nil, fmt.Errorf("yaml line %d: unexpected indentation", i+1)
+		}
+		if isSeqItem(text) || isDocumentMarker(text) {
+			return out, nil
+		}
+		key, rest, ok := splitKeyValue(text)
+		if !ok {
+			return nil, fmt.Errorf("yaml line %d: expected \"key: value\"", i+1)
+		}
+		if key == "<<" {
+			return nil, fmt.Errorf("yaml line %d: merge keys are not supported", i+1)
+		}
+		if _, dup := out[key]; dup {
+			return nil, fmt.Errorf("yaml line %d: duplicate key %q", i+1, key)
+		}
+		p.pos = i + 1
+
+		v, err := p.parseValue(ind, rest, i+1, true)
+		if err != nil {
+			return nil, err
+		}
+		out[key] = v
+	}
+}
+
+func (p *yamlParser) parseSeq(ind int) (any, error) {
+	out := []any{}
+	for {
+		i, ok := p.peek()
+		if !ok {
+			return out, nil
+		}
+		lineInd, text, err := p.lineAt(i)
+		if err != nil {
+			return nil, err
+		}
+		if lineInd < ind || (lineInd == ind && !isSeqItem(text)) {
+			return out, nil
+		}
+		if lineInd > ind {
+			return nil, fmt.Errorf("yaml line %d: unexpected indentation", i+1)
+		}
+
+		rest := strings.TrimSpace(strings.TrimPrefix(text, "-"))
+		_, _, isKV := splitKeyValue(rest)
+		isKV = isKV && !strings.HasPrefix(rest, "{") && !strings.HasPrefix(rest, "\"") && !strings.HasPrefix(rest, "'")
+		if isKV || isSeqItem(rest) {
+			// "- key: value" starts a mapping whose keys align with "key", and "- - item"
+			// a sequence whose dashes align with the second one.
+			raw := p.lines[i]
+			dash := strings.Index(raw, "-")
+			off := dash + 1
+			for off < len(raw) && raw[off] == ' ' {
+				off++
+			}
+			p.lines[i] = strings.Repeat(" ", off) + raw[off:]
+			p.pos = i
+			v, err := p.parseNode(off)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+			continue
+		}
+
+		p.pos = i + 1
+		v, err := p.parseValue(ind, rest, i+1, false)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+}
+
+// parseValue parses the value that follows "key:" or "- " on lineNo.
+// allowSameIndentSeq permits the common "key:\n- item" style where the sequence
+// is not indented deeper than its key.
+func (p *yamlParser) parseValue(ind int, rest string, lineNo int, allowSameIndentSeq bool) (any, error) {
+	if strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, ">") {
+		return p.parseBlockScalar(ind, rest, lineNo)
+	}
+	if rest != "" {
+		return parseYAMLScalar(rest, lineNo)
+	}
+
+	j, ok := p.peek()
+	if !ok {
+		return nil, nil
+	}
+	childInd, childText, err := p.lineAt(j)
+	if err != nil {
+		return nil, err
+	}
+	if childInd > ind {
+		return p.parseNode(childInd)
+	}
+	if allowSameIndentSeq && childInd == ind && isSeqItem(childText) {
+		return p.parseSeq(ind)
+	}
+	return nil, nil
+}
+
+func (p *yamlParser) parseBlockScalar(parentInd int, header string, lineNo int) (any, error) {
+	style := header[0]
+	chomp := byte(0)
+	for _, c := range header[1:] {
+		switch c {
+		case '-', '+':
+			chomp = byte(c)
+		case ' ':
+		default:
+			if c < '1' || c > '9' {
+				return nil, fmt.Errorf("yaml line %d: unsupported block scalar header %q", lineNo, header)
+			}
+		}
+	}
+
+	var body []string
+	blockInd := -1
+	i := p.pos
+	for ; i < len(p.lines); i++ {
+		raw := p.lines[i]
+		if strings.TrimSpace(raw) == "" {
+			body = append(body, "")
+			continue
+		}
+		ind := 0
+		for ind < len(raw) && raw[ind] == ' ' {
+			ind++
+		}
+		if ind <= parentInd {
+			break
+		}
+		if blockInd < 0 {
+			blockInd = ind
+		}
+		if ind < blockInd {
+			break
+		}
+		body = append(body, raw[blockInd:])
+	}
+	p.pos = i
+
+	// Separate trailing blank lines for chomping.
+	trailing := 0
+	for len(body) > 0 && body[len(body)-1] == "" {
+		body = body[:len(body)-1]
+		trailing++
+	}
+
+	var text string
+	if style == '|' {
+		text = strings.Join(body, "\n")
+	} else {
+		var b strings.Builder
+		for k, l := range body {
+			if k > 0 {
+				if l == "" || body[k-1] == "" {
+					b.WriteString("\n")
+				} else {
+					b.WriteString(" ")
+				}
+			}
+			b.WriteString(l)
+		}
+		text = b.String()
+	}
+
+	switch chomp {
+	case '-':
+	case '+':
+		if len(body) > 0 {
+			text += "\n"
+		}
+		text += strings.Repeat("\n", trailing)
+	default:
+		if len(body) > 0 {
+			text += "\n"
+		}
+	}
+	return text, nil
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isDocumentMarker(text string) bool {
+	return text == "---" || text == "..."
+}
+
+// splitKeyValue splits "key: value" at the first ": " (or trailing ":") outside quotes.
+func splitKeyValue(text string) (key string, rest string, ok bool) {
+	inSingle, inDouble := false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\'' && !inDouble:
+			inSingle = !inSingle
+		case c == '"' && !inSingle:
+			if !(inDouble && i > 0 && text[i-1] == '\\') {
+				inDouble = !inDouble
+			}
+		case c == ':' && !inSingle && !inDouble:
+			if i == len(text)-1 || text[i+1] == ' ' {
+				k := strings.TrimSpace(text[:i])
+				if k == "" {
+					return "", "", false
+				}
+				if len(k) >= 2 && (k[0] == '"' || k[0] == '\'') && k[len(k)-1] == k[0] {
+					k = k[1 : len(k)-1]
+				}
+				return k, strings.TrimSpace(text[i+1:]), true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// stripYAMLComment removes a trailing "# comment" that is outside quotes.
+func stripYAMLComment(s string) string {
+	inSingle, inDouble := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\'' && !inDouble:
+			inSingle = !inSingle
+		case c == '"' && !inSingle:
+			if !(inDouble && i > 0 && s[i-1] == '\\') {
+				inDouble = !inDouble
+			}
+		case c == '#' && !inSingle && !inDouble:
+			if i == 0 || s[i-1] == ' ' {
+				return s[:i]
+			}
+		}
+	}
+	return s
+}
+
+func parseYAMLScalar(s string, lineNo int) (any, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return nil, nil
+	case s[0] == '"':
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("yaml line %d: invalid double-quoted string", lineNo)
+		}
+		return v, nil
+	case s[0] == '\'':
+		if len(s) < 2 || s[len(s)-1] != '\'' {
+			return nil, fmt.Errorf("yaml line %d: unterminated single-quoted string", lineNo)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case s[0] == '[':
+		if s[len(s)-1] != ']' {
+			return nil, fmt.Errorf("yaml line %d: unterminated flow sequence", lineNo)
+		}
+		out := []any{}
+		for _, item := range splitFlow(s[1 : len(s)-1]) {
+			v, err := parseYAMLScalar(item, lineNo)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	case s[0] == '{':
+		if s[len(s)-1] != '}' {
+			return nil, fmt.Errorf("yaml line %d: unterminated flow mapping", lineNo)
+		}
+		out := map[string]any{}
+		for _, item := range splitFlow(s[1 : len(s)-1]) {
+			k, rest, ok := splitKeyValue(item)
+			if !ok {
+				return nil, fmt.Errorf("yaml line %d: expected \"key: value\" in flow mapping", lineNo)
+			}
+			v, err := parseYAMLScalar(rest, lineNo)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = v
+		}
+		return out, nil
+	}
+
+	switch s[0] {
+	case '&':
+		return nil, fmt.Errorf("yaml line %d: anchors are not supported", lineNo)
+	case '*':
+		return nil, fmt.Errorf("yaml line %d: aliases are not supported (quote the value if it is a string)", lineNo)
+	case '!':
+		return nil, fmt.Errorf("yaml line %d: tags are not supported", lineNo)
+	case '?':
+		return nil, fmt.Errorf("yaml line %d: explicit keys are not supported", lineNo)
+	}
+	if _, _, ok := splitKeyValue(s); ok {
+		return nil, fmt.Errorf("yaml line %d: mapping values are not allowed in a plain scalar (quote the value)", lineNo)
+	}
+
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && strings.ContainsAny(s, "0123456789") {
+		return f, nil
+	}
+	return s, nil
+}
+
+// splitFlow splits a flow collection body on top-level commas.
+func splitFlow(s string) []string {
+	var out []string
+	depth := 0
+	inSingle, inDouble := false, false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\'' && !inDouble:
+			inSingle = !inSingle
+		case c == '"' && !inSingle:
+			if !(inDouble && i > 0 && s[i-1] == '\\') {
+				inDouble = !inDouble
+			}
+		case inSingle || inDouble:
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			out = append(out, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		out = append(out, last)
+	}
+	return out
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
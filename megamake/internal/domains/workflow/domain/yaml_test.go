@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want any
+	}{
+		{
+			name: "nested maps and sequences",
+			src: `version: 1
+name: check
+steps:
+  - id: diag
+    type: diagnose
+  - id: fix
+    with:
+      tags:
+        - a
+        - b
+      limits:
+        retries: 2
+        ratio: 0.5
+        enabled: true
+        none: ~
+`,
+			want: map[string]any{
+				"version": int64(1),
+				"name":    "check",
+				"steps": []any{
+					map[string]any{"id": "diag", "type": "diagnose"},
+					map[string]any{"id": "fix", "with": map[string]any{
+						"tags":   []any{"a", "b"},
+						"limits": map[string]any{"retries": int64(2), "ratio": 0.5, "enabled": true, "none": nil},
+					}},
+				},
+			},
+		},
+		{
+			name: "sequence at the key's indentation",
+			src:  "steps:\n- one\n- two\nafter: x\n",
+			want: map[string]any{"steps": []any{"one", "two"}, "after": "x"},
+		},
+		{
+			name: "sequence of sequences",
+			src:  "- - a\n  - b\n- c\n",
+			want: []any{[]any{"a", "b"}, "c"},
+		},
+		{
+			name: "quoted scalars with colons and hashes",
+			src: `a: "x: y"
+b: 'http://host:80/#frag'
+c: "# not a comment"
+d: 'it''s: fine'  # a comment
+"quoted: key": "tab\tand \"quote\""
+e: a#b
+`,
+			want: map[string]any{
+				"a":           "x: y",
+				"b":           "http://host:80/#frag",
+				"c":           "# not a comment",
+				"d":           "it's: fine",
+				"quoted: key": "tab\tand \"quote\"",
+				"e":           "a#b",
+			},
+		},
+		{
+			name: "comments and blank lines",
+			src: `# leading comment
+---
+
+name: x   # trailing comment
+
+  # indented comment
+steps:
+
+  # between items
+  - id: a
+`,
+			want: map[string]any{"name": "x", "steps": []any{map[string]any{"id": "a"}}},
+		},
+		{
+			name: "flow collections",
+			src:  "a: [1, \"two, three\", [x]]\nb: {k: v, n: 2}\n",
+			want: map[string]any{"a": []any{int64(1), "two, three", []any{"x"}}, "b": map[string]any{"k": "v", "n": int64(2)}},
+		},
+		{
+			name: "block scalars",
+			src:  "lit: |\n  line one\n  line two\nfold: >-\n  a\n  b\nkeep: |+\n  x\n\nnext: 1\n",
+			want: map[string]any{"lit": "line one\nline two\n", "fold": "a b", "keep": "x\n\n", "next": int64(1)},
+		},
+		{
+			name: "empty value",
+			src:  "a:\nb: 1\n",
+			want: map[string]any{"a": nil, "b": int64(1)},
+		},
+		{
+			name: "empty document",
+			src:  "# only a comment\n\n",
+			want: nil,
+		},
+	}
+	for _, c := range cases {
+		got, err := parseYAML(c.src)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s:\n got %#v\nwant %#v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want string // substring of the error, including the line number
+	}{
+		{"tab indentation", "a:\n\tb: 1\n", "line 2: tabs"},
+		{"over-indented key", "a: 1\n  b: 2\n", "line 2: unexpected indentation"},
+		{"over-indented item", "- a\n   - b\n", "line 2: unexpected indentation"},
+		{"duplicate key", "a: 1\nb: 2\na: 3\n", "line 3: duplicate key"},
+		{"not a mapping entry", "a: 1\njust text\n", "line 2: expected \"key: value\""},
+		{"trailing content", "a: 1\n- b\n", "line 2: unexpected content"},
+		{"anchor", "base: &base\n  type: prompt\n", "line 1: anchors"},
+		{"alias", "a: 1\nb: *a\n", "line 2: aliases"},
+		{"alias item", "steps:\n  - *step\n", "line 2: aliases"},
+		{"unquoted glob", "include: *.go\n", "line 1: aliases"},
+		{"merge key", "a:\n  <<: *base\n", "line 2: merge keys"},
+		{"tag", "a: !!str 1\n", "line 1: tags"},
+		{"explicit key", "? a\n: b\n", "line 1: explicit keys"},
+		{"multi-line plain scalar", "a: first\n  second\n", "line 2: unexpected indentation"},
+		{"multi-line double-quoted scalar", "a: \"first\n  second\"\n", "line 1: invalid double-quoted string"},
+		{"multi-line single-quoted scalar", "a: 'first\n  second'\n", "line 1: unterminated single-quoted string"},
+		{"multi-line flow sequence", "a: [1,\n  2]\n", "line 1: unterminated flow sequence"},
+		{"multi-line flow mapping", "a: {k: v,\n  n: 2}\n", "line 1: unterminated flow mapping"},
+		{"plain value with colon", "message: Fix: this\n", "line 1: mapping values"},
+		{"multiple documents", "a: 1\n---\nb: 2\n", "line 2: multiple documents"},
+		{"bad block scalar header", "a: |x\n  b\n", "line 1: unsupported block scalar header"},
+	}
+	for _, c := range cases {
+		v, err := parseYAML(c.src)
+		if err == nil {
+			t.Errorf("%s: no error, parsed %#v", c.name, v)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: error %q, want it to contain %q", c.name, err, c.want)
+		}
+	}
+}
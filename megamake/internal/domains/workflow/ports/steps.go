@@ -0,0 +1,38 @@
+package ports
+
+import (
+	contract "github.com/megamake/megamake/internal/contracts/v1/workflow"
+	"github.com/megamake/megamake/internal/platform/policy"
+)
+
+// StepRequest is one step invocation with its "with" block already interpolated.
+type StepRequest struct {
+	ID   string
+	Type contract.StepTypeV1
+	With map[string]any
+
+	RootPath    string
+	ArtifactDir string // local tools (prompt/doc/diagnose/test/secure/patch)
+
+	// ChatArtifactDir is where chat runs live (the global --artifact-dir or cwd).
+	ChatArtifactDir string
+
+	IgnoreNames []string
+	IgnoreGlobs []string
+
+	// Policy is the invocation's global policy; steps can never widen it.
+	Policy policy.Policy
+}
+
+// StepOutput is what a step exposes to later steps as {{steps.<id>.<key>}}.
+type StepOutput struct {
+	ArtifactPath string
+	Outputs      map[string]string
+}
+
+// StepRunner executes a single step against the underlying domain APIs.
+// A runner may return both outputs and an error when a step completed but did not
+// succeed (e.g. a blocked patch); the outputs are still recorded.
+type StepRunner interface {
+	RunStep(req StepRequest) (StepOutput, error)
+}
@@ -2,6 +2,7 @@ package artifact
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -24,6 +25,10 @@ type WriteRequest struct {
 
 type Writer struct {
 	Clock clock.Clock
+
+	// Retention, if set, supplies a policy that is applied to the tool's artifacts in
+	// ArtifactDir after each successful write (see Prune).
+	Retention RetentionSource
 }
 
 // WriteToolArtifact writes:
@@ -32,6 +37,35 @@ type Writer struct {
 //
 // NOTE: Uses UTC timestamps and pointer files (no symlinks) for cross-platform reliability.
 func (w Writer) WriteToolArtifact(req WriteRequest) (artifactPath string, latestPointerPath string, err error) {
+	return w.StreamToolArtifact(StreamRequest{
+		ArtifactDir:    req.ArtifactDir,
+		ToolPrefix:     req.ToolPrefix,
+		Envelope:       &req.Envelope,
+		GeneratedAtUTC: req.GeneratedAtUTC,
+	})
+}
+
+// StreamRequest is a WriteRequest whose XML block may be produced while the file is written.
+type StreamRequest struct {
+	ArtifactDir    string
+	ToolPrefix     string
+	Envelope       *artifact.ArtifactEnvelopeV1
+	GeneratedAtUTC *time.Time
+
+	// XML, if non-nil, writes the XML block in place of Envelope.XML. It may set
+	// Envelope.JSON and Envelope.Prompt, which are written after it.
+	XML func(w io.Writer) error
+}
+
+// StreamToolArtifact writes the artifact and latest pointer like WriteToolArtifact, streaming
+// the envelope to disk instead of rendering it in memory. A failed write removes the partial
+// artifact and leaves the latest pointer untouched.
+//
+// A manifest on the envelope is completed (git state, version, and hashes the tool did not
+// record while reading) before it is written.
+// Automatic retention runs once the latest pointer is updated. An invalid retention setting
+// fails the write up front; errors while pruning do not (the artifact is already written).
+func (w Writer) StreamToolArtifact(req StreamRequest) (artifactPath string, latestPointerPath string, err error) {
 	if w.Clock == nil {
 		return "", "", errors.NewInternal("artifact writer clock is nil", nil)
 	}
@@ -41,12 +75,24 @@ func (w Writer) WriteToolArtifact(req WriteRequest) (artifactPath string, latest
 	if strings.TrimSpace(req.ToolPrefix) == "" {
 		return "", "", errors.NewInternal("toolPrefix is empty", nil)
 	}
+	if req.Envelope == nil {
+		return "", "", errors.NewInternal("envelope is nil", nil)
+	}
 
 	now := w.Clock.NowUTC()
 	if req.GeneratedAtUTC != nil {
 		now = req.GeneratedAtUTC.UTC()
 	}
 
+	var retention Retention
+	if w.Retention != nil {
+		r, err := w.Retention.RetentionFor(req.ArtifactDir)
+		if err != nil {
+			return "", "", errors.New(errors.KindUsage, "invalid artifact retention setting", err)
+		}
+		retention = r
+	}
+
 	filename := fmt.Sprintf("%s_%s.txt", req.ToolPrefix, formatUTCForFilename(now))
 	fullPath := filepath.Join(req.ArtifactDir, filename)
 
@@ -54,10 +100,33 @@ func (w Writer) WriteToolArtifact(req WriteRequest) (artifactPath string, latest
 		return "", "", errors.New(errors.KindIO, "failed to create artifact directory", err)
 	}
 
-	payload := req.Envelope.Render()
-	if err := os.WriteFile(fullPath, []byte(payload), 0o644); err != nil {
+	// A streaming XML callback reads the files and records their hashes as it goes; the
+	// manifest follows it in the envelope, so it is completed only once the callback is done.
+	xml := req.XML
+	if m := req.Envelope.Manifest; m != nil {
+		if xml == nil {
+			stampManifest(m)
+		} else {
+			xml = func(w io.Writer) error {
+				err := req.XML(w)
+				stampManifest(m)
+				return err
+			}
+		}
+	}
+
+	f, err := os.OpenFile(fullPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
 		return "", "", errors.New(errors.KindIO, "failed to write artifact file", err)
 	}
+	werr := req.Envelope.WriteStreaming(f, xml)
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		_ = os.Remove(fullPath)
+		return "", "", errors.New(errors.KindIO, "failed to write artifact file", werr)
+	}
 
 	latestName := fmt.Sprintf("%s_latest.txt", req.ToolPrefix)
 	latestPath := filepath.Join(req.ArtifactDir, latestName)
@@ -65,10 +134,56 @@ func (w Writer) WriteToolArtifact(req WriteRequest) (artifactPath string, latest
 		return "", "", errors.New(errors.KindIO, "failed to write latest pointer file", err)
 	}
 
+	if !retention.IsZero() {
+		_, _ = w.Prune(PruneRequest{ArtifactDir: req.ArtifactDir, ToolPrefix: req.ToolPrefix, Retention: retention})
+	}
+
 	return fullPath, latestPath, nil
 }
 
+// WritePartsRequest describes the part files of a split artifact.
+type WritePartsRequest struct {
+	ArtifactDir    string
+	ToolPrefix     string
+	Parts          []string // rendered part contents, in order
+	GeneratedAtUTC *time.Time
+}
+
+// WriteParts writes <ToolPrefix>_YYYYMMDD_HHMMSSZ_partNN.txt for each part and returns their paths.
+// Call it before WriteToolArtifact with the same timestamp, so the latest pointer only moves
+// once every part the main artifact lists exists.
+func (w Writer) WriteParts(req WritePartsRequest) ([]string, error) {
+	if w.Clock == nil {
+		return nil, errors.NewInternal("artifact writer clock is nil", nil)
+	}
+	if strings.TrimSpace(req.ArtifactDir) == "" {
+		return nil, errors.NewInternal("artifactDir is empty", nil)
+	}
+	if strings.TrimSpace(req.ToolPrefix) == "" {
+		return nil, errors.NewInternal("toolPrefix is empty", nil)
+	}
+
+	now := w.Clock.NowUTC()
+	if req.GeneratedAtUTC != nil {
+		now = req.GeneratedAtUTC.UTC()
+	}
+
+	if err := os.MkdirAll(req.ArtifactDir, 0o755); err != nil {
+		return nil, errors.New(errors.KindIO, "failed to create artifact directory", err)
+	}
+
+	paths := make([]string, 0, len(req.Parts))
+	for i, part := range req.Parts {
+		p := filepath.Join(req.ArtifactDir, artifact.PartFilename(req.ToolPrefix, now, i+1, len(req.Parts)))
+		if err := os.WriteFile(p, []byte(part), 0o644); err != nil {
+			return nil, errors.New(errors.KindIO, "failed to write artifact part file", err)
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
 func formatUTCForFilename(t time.Time) string {
 	// Example: 20260120_154233Z
-	return t.UTC().Format("20060102_150405Z")
+	return artifact.FilenameTimestamp(t)
 }
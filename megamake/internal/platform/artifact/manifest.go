@@ -0,0 +1,60 @@
+package artifact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/megamake/megamake/internal/contracts/v1/artifact"
+	"github.com/megamake/megamake/internal/platform/errors"
+	"github.com/megamake/megamake/internal/platform/git"
+	"github.com/megamake/megamake/internal/platform/version"
+)
+
+// HashFile returns the hex SHA-256 and size of the file at path.
+func HashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, errors.New(errors.KindIO, "failed to open file", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, errors.New(errors.KindIO, "failed to read file", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// stampManifest completes a manifest a tool started: the megamake version, the git state of
+// the root, and, as a fallback for files the tool did not read itself (diagnose's compiler
+// inputs), the hash of every listed file not hashed yet. Such files that can no longer be
+// read are dropped, since they cannot have contributed to the artifact.
+func stampManifest(m *artifact.ManifestV1) {
+	m.MegamakeVersion = version.String()
+	// Record an absolute root so the artifact can be verified from any directory.
+	if m.RootPath != "" {
+		if abs, err := filepath.Abs(m.RootPath); err == nil {
+			m.RootPath = abs
+		}
+	}
+	if m.Git == nil && m.RootPath != "" {
+		if head, dirty, ok := git.HeadState(m.RootPath); ok {
+			m.Git = &artifact.GitStateV1{Head: head, Dirty: dirty}
+		}
+	}
+	files := m.Files[:0]
+	for _, f := range m.Files {
+		if f.SHA256 == "" {
+			sum, n, err := HashFile(filepath.Join(m.RootPath, filepath.FromSlash(f.Path)))
+			if err != nil {
+				continue
+			}
+			f.SHA256, f.Bytes = sum, n
+		}
+		files = append(files, f)
+	}
+	m.Files = files
+}
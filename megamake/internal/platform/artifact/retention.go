@@ -0,0 +1,229 @@
+package artifact
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/megamake/megamake/internal/platform/config"
+	"github.com/megamake/megamake/internal/platform/errors"
+)
+
+// Retention limits how many artifacts of a tool are kept in a directory. An artifact's age
+// comes from the timestamp in its filename. When both limits are set, an artifact is removed
+// only if it is outside the newest Keep and older than MaxAge. The artifact the tool's latest
+// pointer names is never removed.
+type Retention struct {
+	Keep   int           // keep the newest Keep artifacts per tool (0: no count limit)
+	MaxAge time.Duration // remove artifacts older than this (0: no age limit)
+}
+
+func (r Retention) IsZero() bool {
+	return r.Keep <= 0 && r.MaxAge <= 0
+}
+
+// RetentionSource supplies the automatic retention policy for an artifact directory.
+type RetentionSource interface {
+	RetentionFor(artifactDir string) (Retention, error)
+}
+
+// ConfigRetention reads the policy from the [artifact] section of the megamake.toml or
+// .megamake.json found from the artifact directory upwards: keep = N, older-than = "7d".
+type ConfigRetention struct{}
+
+func (ConfigRetention) RetentionFor(artifactDir string) (Retention, error) {
+	cfg, err := config.Load(artifactDir)
+	if err != nil {
+		return Retention{}, err
+	}
+	var r Retention
+	for _, e := range cfg.Effective("artifact") {
+		if len(e.Value.Values) != 1 {
+			continue
+		}
+		v := e.Value.Values[0]
+		switch e.Key {
+		case "keep":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return Retention{}, fmt.Errorf("config: %s: keep must be a non-negative integer, got %q", e.Value.Source, v)
+			}
+			r.Keep = n
+		case "older-than":
+			d, err := ParseAge(v)
+			if err != nil {
+				return Retention{}, fmt.Errorf("config: %s: older-than: %v", e.Value.Source, err)
+			}
+			r.MaxAge = d
+		}
+	}
+	return r, nil
+}
+
+// ParseAge parses an age such as "7d", "2w", "36h" or "90m" (any Go duration, plus d and w
+// for days and weeks).
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit > 0 {
+		n, err := strconv.Atoi(strings.TrimSpace(s[:len(s)-1]))
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid age %q (expected e.g. 7d, 2w, 36h)", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid age %q (expected e.g. 7d, 2w, 36h)", s)
+	}
+	return d, nil
+}
+
+type PruneRequest struct {
+	ArtifactDir string
+	ToolPrefix  string // empty: every MEGA* tool with artifacts in ArtifactDir
+	Retention   Retention
+	DryRun      bool
+}
+
+// PrunedArtifact is one artifact removed (or, in a dry run, that would be removed), with
+// the part files written alongside it.
+type PrunedArtifact struct {
+	ToolPrefix  string
+	GeneratedAt time.Time
+	Files       []string // main artifact first (if present), then parts
+	Bytes       int64
+}
+
+type PruneResult struct {
+	Removed []PrunedArtifact
+	Kept    int
+}
+
+var artifactFileRe = regexp.MustCompile(`^(MEGA[A-Z]+)_(\d{8}_\d{6}Z)(?:_part\d+)?\.txt$`)
+
+type artifactGroup struct {
+	prefix string
+	stamp  string
+	at     time.Time
+	files  []string
+	bytes  int64
+}
+
+// Prune removes artifacts of ArtifactDir beyond the retention policy, oldest first, together
+// with their part files. Only files named <PREFIX>_YYYYMMDD_HHMMSSZ[_partNN].txt are considered.
+func (w Writer) Prune(req PruneRequest) (PruneResult, error) {
+	if w.Clock == nil {
+		return PruneResult{}, errors.NewInternal("artifact writer clock is nil", nil)
+	}
+	if strings.TrimSpace(req.ArtifactDir) == "" {
+		return PruneResult{}, errors.NewInternal("artifactDir is empty", nil)
+	}
+	if req.Retention.IsZero() {
+		return PruneResult{}, nil
+	}
+
+	entries, err := os.ReadDir(req.ArtifactDir)
+	if err != nil {
+		return PruneResult{}, errors.New(errors.KindIO, "failed to list artifact directory", err)
+	}
+
+	groups := map[string]*artifactGroup{}
+	for _, ent := range entries {
+		if !ent.Type().IsRegular() {
+			continue
+		}
+		m := artifactFileRe.FindStringSubmatch(ent.Name())
+		if m == nil || (req.ToolPrefix != "" && m[1] != req.ToolPrefix) {
+			continue
+		}
+		at, err := time.Parse("20060102_150405Z", m[2])
+		if err != nil {
+			continue
+		}
+		key := m[1] + "_" + m[2]
+		g := groups[key]
+		if g == nil {
+			g = &artifactGroup{prefix: m[1], stamp: m[2], at: at}
+			groups[key] = g
+		}
+		// Sorted names put the main artifact (".txt") before its parts ("_part01.txt").
+		g.files = append(g.files, filepath.Join(req.ArtifactDir, ent.Name()))
+		if info, err := ent.Info(); err == nil {
+			g.bytes += info.Size()
+		}
+	}
+
+	byTool := map[string][]*artifactGroup{}
+	for _, g := range groups {
+		byTool[g.prefix] = append(byTool[g.prefix], g)
+	}
+	tools := make([]string, 0, len(byTool))
+	for t := range byTool {
+		tools = append(tools, t)
+	}
+	sort.Strings(tools)
+
+	cutoff := w.Clock.NowUTC().Add(-req.Retention.MaxAge)
+	var res PruneResult
+	for _, tool := range tools {
+		gs := byTool[tool]
+		sort.Slice(gs, func(i, j int) bool { return gs[i].stamp > gs[j].stamp }) // newest first
+		latest := latestStamp(req.ArtifactDir, tool)
+
+		for i, g := range gs {
+			remove := g.stamp != latest
+			if req.Retention.Keep > 0 && i < req.Retention.Keep {
+				remove = false
+			}
+			if req.Retention.MaxAge > 0 && !g.at.Before(cutoff) {
+				remove = false
+			}
+			if !remove {
+				res.Kept++
+				continue
+			}
+			pa := PrunedArtifact{ToolPrefix: tool, GeneratedAt: g.at, Files: g.files, Bytes: g.bytes}
+			if !req.DryRun {
+				for _, f := range g.files {
+					if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+						return res, errors.New(errors.KindIO, "failed to remove artifact file", err)
+					}
+				}
+			}
+			res.Removed = append(res.Removed, pa)
+		}
+	}
+
+	// Report oldest first across tools.
+	sort.SliceStable(res.Removed, func(i, j int) bool {
+		return res.Removed[i].GeneratedAt.Before(res.Removed[j].GeneratedAt)
+	})
+	return res, nil
+}
+
+// latestStamp returns the filename timestamp of the artifact <prefix>_latest.txt points at,
+// or "" if there is no readable pointer.
+func latestStamp(dir string, prefix string) string {
+	b, err := os.ReadFile(filepath.Join(dir, prefix+"_latest.txt"))
+	if err != nil {
+		return ""
+	}
+	name := strings.TrimSpace(strings.SplitN(string(b), "\n", 2)[0])
+	m := artifactFileRe.FindStringSubmatch(name)
+	if m == nil || m[1] != prefix {
+		return ""
+	}
+	return m[2]
+}
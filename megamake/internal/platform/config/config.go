@@ -0,0 +1,419 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FileNames are the project config files looked up in each directory.
+// A directory may hold at most one of them.
+var FileNames = []string{"megamake.toml", ".megamake.json"}
+
+// Value is one configured key: its flag values (one element for scalars) and origin.
+type Value struct {
+	Values []string
+	List   bool
+	Source string // "/abs/megamake.toml:12" or "/abs/.megamake.json (prompt.max-tokens)"
+}
+
+// Config is the merged project configuration.
+// Sections maps a command name ("prompt", "doc", ...) to flag name -> value;
+// the "" section holds top-level keys, which apply to every command defining that flag.
+type Config struct {
+	Files    []string // outermost first
+	Sections map[string]map[string]Value
+}
+
+// Entry is a key of the effective configuration for one command.
+type Entry struct {
+	Key         string
+	Value       Value
+	FromSection bool // set in the command's own section (not only at top level)
+}
+
+// Load collects the config files from startDir up to the filesystem root and merges them:
+// nearer files override farther ones key by key, except "ignore" lists, which accumulate.
+// No config file is not an error.
+func Load(startDir string) (Config, error) {
+	cfg := Config{Sections: map[string]map[string]Value{}}
+
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return cfg, err
+	}
+	if fi, err := os.Stat(dir); err == nil && !fi.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	var files []string
+	for {
+		var found []string
+		for _, name := range FileNames {
+			p := filepath.Join(dir, name)
+			if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+				found = append(found, p)
+			}
+		}
+		if len(found) > 1 {
+			return cfg, fmt.Errorf("config: both %s found; keep one", strings.Join(found, " and "))
+		}
+		files = append(files, found...)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	for i := len(files) - 1; i >= 0; i-- {
+		data, err := os.ReadFile(files[i])
+		if err != nil {
+			return cfg, fmt.Errorf("config: %v", err)
+		}
+		var sections map[string]map[string]Value
+		if strings.HasSuffix(files[i], ".json") {
+			sections, err = ParseJSON(files[i], data)
+		} else {
+			sections, err = ParseTOML(files[i], data)
+		}
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Files = append(cfg.Files, files[i])
+		cfg.merge(sections)
+	}
+	return cfg, nil
+}
+
+func (c *Config) merge(sections map[string]map[string]Value) {
+	for name, keys := range sections {
+		dst := c.Sections[name]
+		if dst == nil {
+			dst = map[string]Value{}
+			c.Sections[name] = dst
+		}
+		for k, v := range keys {
+			if prev, ok := dst[k]; ok && k == "ignore" {
+				v = Value{
+					Values: append(append([]string{}, prev.Values...), v.Values...),
+					List:   true,
+					Source: prev.Source + ", " + v.Source,
+				}
+			}
+			dst[k] = v
+		}
+	}
+}
+
+// SectionNames returns the configured command sections, sorted (without the top level).
+func (c Config) SectionNames() []string {
+	var out []string
+	for name := range c.Sections {
+		if name != "" {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Effective returns the keys that apply to a command, sorted: its section over top-level
+// keys, with top-level and section "ignore" lists combined.
+func (c Config) Effective(command string) []Entry {
+	merged := map[string]Entry{}
+	for k, v := range c.Sections[""] {
+		merged[k] = Entry{Key: k, Value: v}
+	}
+	for k, v := range c.Sections[command] {
+		if top, ok := merged[k]; ok && k == "ignore" {
+			v = Value{
+				Values: append(append([]string{}, top.Value.Values...), v.Values...),
+				List:   true,
+				Source: top.Value.Source + ", " + v.Source,
+			}
+		}
+		merged[k] = Entry{Key: k, Value: v, FromSection: true}
+	}
+
+	out := make([]Entry, 0, len(merged))
+	for _, e := range merged {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// ParseJSON parses a .megamake.json: top-level keys plus one object per command section.
+// Values may be strings, numbers, booleans or arrays of those.
+func ParseJSON(path string, data []byte) (map[string]map[string]Value, error) {
+	var raw map[string]any
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("config: %s: invalid JSON: %v", path, err)
+	}
+
+	out := map[string]map[string]Value{"": {}}
+	var keys []string
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if obj, ok := raw[k].(map[string]any); ok {
+			sec := map[string]Value{}
+			for sk, sv := range obj {
+				v, err := jsonValue(sv)
+				if err != nil {
+					return nil, fmt.Errorf("config: %s: %s.%s: %v", path, k, sk, err)
+				}
+				v.Source = path + " (" + k + "." + sk + ")"
+				sec[sk] = v
+			}
+			out[k] = sec
+			continue
+		}
+		v, err := jsonValue(raw[k])
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %s: %v", path, k, err)
+		}
+		v.Source = path + " (" + k + ")"
+		out[""][k] = v
+	}
+	return out, nil
+}
+
+func jsonValue(x any) (Value, error) {
+	if arr, ok := x.([]any); ok {
+		v := Value{List: true}
+		for _, e := range arr {
+			s, err := jsonScalar(e)
+			if err != nil {
+				return Value{}, err
+			}
+			v.Values = append(v.Values, s)
+		}
+		return v, nil
+	}
+	s, err := jsonScalar(x)
+	if err != nil {
+		return Value{}, err
+	}
+	return Value{Values: []string{s}}, nil
+}
+
+func jsonScalar(x any) (string, error) {
+	switch t := x.(type) {
+	case string:
+		return t, nil
+	case json.Number:
+		return t.String(), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v (use a string, number, boolean or array)", x)
+	}
+}
+
+// ParseTOML parses the TOML subset megamake.toml uses: comments, [section] tables,
+// key = value pairs with bare or quoted keys, basic and literal strings, integers,
+// booleans, and arrays of those (which may span lines).
+func ParseTOML(path string, data []byte) (map[string]map[string]Value, error) {
+	out := map[string]map[string]Value{"": {}}
+	section := ""
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		fail := func(format string, args ...any) error {
+			return fmt.Errorf("config: %s:%d: %s", path, lineNo, fmt.Sprintf(format, args...))
+		}
+
+		t := strings.TrimSpace(stripTOMLComment(lines[i]))
+		if t == "" {
+			continue
+		}
+		if strings.HasPrefix(t, "[") {
+			if !strings.HasSuffix(t, "]") || strings.HasPrefix(t, "[[") {
+				return nil, fail("unsupported table header %q", t)
+			}
+			section = strings.TrimSpace(t[1 : len(t)-1])
+			if section == "" {
+				return nil, fail("empty table name")
+			}
+			if _, dup := out[section]; dup {
+				return nil, fail("duplicate table [%s]", section)
+			}
+			out[section] = map[string]Value{}
+			continue
+		}
+
+		eq := strings.Index(t, "=")
+		if eq < 0 {
+			return nil, fail("expected key = value")
+		}
+		key := unquoteTOMLKey(strings.TrimSpace(t[:eq]))
+		if key == "" {
+			return nil, fail("empty key")
+		}
+		rest := strings.TrimSpace(t[eq+1:])
+
+		// Arrays may continue over the following lines until the closing bracket.
+		if strings.HasPrefix(rest, "[") {
+			for !arrayClosed(rest) && i+1 < len(lines) {
+				i++
+				rest += " " + strings.TrimSpace(stripTOMLComment(lines[i]))
+			}
+		}
+
+		v, err := parseTOMLValue(rest)
+		if err != nil {
+			return nil, fail("%s: %v", key, err)
+		}
+		v.Source = path + ":" + strconv.Itoa(lineNo)
+		if _, dup := out[section][key]; dup {
+			return nil, fail("duplicate key %q", key)
+		}
+		out[section][key] = v
+	}
+	return out, nil
+}
+
+func parseTOMLValue(s string) (Value, error) {
+	if strings.HasPrefix(s, "[") {
+		if !arrayClosed(s) || !strings.HasSuffix(s, "]") {
+			return Value{}, fmt.Errorf("unterminated array")
+		}
+		v := Value{List: true}
+		body := strings.TrimSpace(s[1 : len(s)-1])
+		for body != "" {
+			item, rest, err := nextTOMLScalar(body)
+			if err != nil {
+				return Value{}, err
+			}
+			v.Values = append(v.Values, item)
+			rest = strings.TrimSpace(rest)
+			if rest != "" && !strings.HasPrefix(rest, ",") {
+				return Value{}, fmt.Errorf("expected \",\" between array items")
+			}
+			body = strings.TrimSpace(strings.TrimPrefix(rest, ","))
+		}
+		return v, nil
+	}
+	item, rest, err := nextTOMLScalar(s)
+	if err != nil {
+		return Value{}, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return Value{}, fmt.Errorf("unexpected %q after value", strings.TrimSpace(rest))
+	}
+	return Value{Values: []string{item}}, nil
+}
+
+// nextTOMLScalar reads one string, integer or boolean from the start of s.
+func nextTOMLScalar(s string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			c := s[i]
+			if c == '"' {
+				return b.String(), s[i+1:], nil
+			}
+			if c == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case '"', '\\':
+					b.WriteByte(s[i])
+				default:
+					return "", "", fmt.Errorf("unsupported escape \\%c", s[i])
+				}
+				continue
+			}
+			b.WriteByte(c)
+		}
+		return "", "", fmt.Errorf("unterminated string")
+	case strings.HasPrefix(s, "'"):
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated string")
+		}
+		return s[1 : 1+end], s[end+2:], nil
+	}
+
+	end := strings.IndexAny(s, ", \t]")
+	if end < 0 {
+		end = len(s)
+	}
+	word := s[:end]
+	switch word {
+	case "true", "false":
+		return word, s[end:], nil
+	}
+	n := strings.ReplaceAll(word, "_", "")
+	if _, err := strconv.ParseInt(n, 10, 64); err == nil {
+		return n, s[end:], nil
+	}
+	return "", "", fmt.Errorf("unsupported value %q (quote strings)", word)
+}
+
+// arrayClosed reports whether the brackets in s balance, ignoring brackets inside strings.
+func arrayClosed(s string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// stripTOMLComment drops a "#" comment that is not inside a string.
+func stripTOMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func unquoteTOMLKey(k string) string {
+	if len(k) >= 2 && (k[0] == '"' || k[0] == '\'') && k[len(k)-1] == k[0] {
+		return k[1 : len(k)-1]
+	}
+	return k
+}
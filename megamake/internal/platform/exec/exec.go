@@ -31,6 +31,12 @@ func Which(name string) (string, bool) {
 // - launchPath must be an executable path (no shell expansion).
 // - exitCode=124 is used for timeout, matching common conventions.
 func Run(launchPath string, args []string, cwd string, timeout time.Duration) Result {
+	return RunEnv(launchPath, args, cwd, nil, timeout)
+}
+
+// RunEnv is Run with extra KEY=VALUE environment entries, which override the inherited
+// environment.
+func RunEnv(launchPath string, args []string, cwd string, env []string, timeout time.Duration) Result {
 	if timeout <= 0 {
 		timeout = 120 * time.Second
 	}
@@ -42,6 +48,9 @@ func Run(launchPath string, args []string, cwd string, timeout time.Duration) Re
 	if strings.TrimSpace(cwd) != "" {
 		cmd.Dir = cwd
 	}
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
 	var outBuf bytes.Buffer
 	var errBuf bytes.Buffer
@@ -8,14 +8,53 @@ import (
 
 // ChangedFilesSince returns git diff name-only for ref..HEAD, or empty if git is unavailable or not a repo.
 func ChangedFilesSince(root string, ref string) []string {
-	return runNameOnly(root, []string{"diff", "--name-only", ref + "..HEAD"})
+	return runNameOnly(root, []string{"diff", "--name-only", "--relative", ref + "..HEAD"})
 }
 
 // ChangedFilesInRange returns git diff name-only for the specified range (A..B or A...B), or empty if unavailable.
 func ChangedFilesInRange(root string, rng string) []string {
-	return runNameOnly(root, []string{"diff", "--name-only", rng})
+	return runNameOnly(root, []string{"diff", "--name-only", "--relative", rng})
 }
 
+// DiffSince returns the unified diff for ref..HEAD limited to paths (all paths if empty),
+// or empty if git is unavailable or not a repo.
+func DiffSince(root string, ref string, paths []string) string {
+	return runDiff(root, ref+"..HEAD", paths)
+}
+
+// DiffInRange returns the unified diff for the specified range (A..B or A...B) limited to
+// paths (all paths if empty), or empty if unavailable.
+func DiffInRange(root string, rng string, paths []string) string {
+	return runDiff(root, rng, paths)
+}
+
+func runDiff(root string, rng string, paths []string) string {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		return ""
+	}
+	if !isGitWorkTree(gitPath, root) {
+		return ""
+	}
+
+	args := []string{"diff", "--no-color", "--no-ext-diff", "--relative", rng}
+	if len(paths) > 0 {
+		args = append(args, "--")
+		args = append(args, paths...)
+	}
+	cmd := exec.Command(gitPath, args...)
+	cmd.Dir = root
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return ""
+	}
+	return out.String()
+}
+
+// runNameOnly and runDiff pass --relative, so paths are relative to root even when root
+// is a subdirectory of the work tree (and changes outside root are left out).
 func runNameOnly(root string, args []string) []string {
 	gitPath, err := exec.LookPath("git")
 	if err != nil {
@@ -51,6 +90,69 @@ func runNameOnly(root string, args []string) []string {
 	return uniqueSorted(res)
 }
 
+// HeadState returns the commit HEAD points at and whether root has uncommitted changes
+// (untracked files included, megamake's own MEGA*_*.txt artifacts excluded). ok is false if
+// git is unavailable, root is not in a work tree, or HEAD has no commit yet.
+func HeadState(root string) (head string, dirty bool, ok bool) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		return "", false, false
+	}
+	if !isGitWorkTree(gitPath, root) {
+		return "", false, false
+	}
+
+	cmd := exec.Command(gitPath, "rev-parse", "HEAD")
+	cmd.Dir = root
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return "", false, false
+	}
+	head = strings.TrimSpace(out.String())
+
+	cmd = exec.Command(gitPath, "status", "--porcelain", "--untracked-files=normal", "--", ".")
+	cmd.Dir = root
+	out.Reset()
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return head, false, true
+	}
+	for _, ln := range strings.Split(out.String(), "\n") {
+		if len(ln) < 4 {
+			continue
+		}
+		p := strings.TrimSuffix(strings.Trim(ln[3:], "\""), "/")
+		if i := strings.LastIndex(p, " -> "); i >= 0 {
+			p = p[i+4:]
+		}
+		if isArtifactName(p[strings.LastIndex(p, "/")+1:]) {
+			continue
+		}
+		dirty = true
+		break
+	}
+	return head, dirty, true
+}
+
+// isArtifactName reports whether name looks like a megamake artifact or latest pointer
+// (MEGAPROMPT_20260120_154233Z.txt, MEGADIAG_latest.txt).
+func isArtifactName(name string) bool {
+	if !strings.HasPrefix(name, "MEGA") || !strings.HasSuffix(name, ".txt") {
+		return false
+	}
+	i := strings.IndexByte(name, '_')
+	if i <= len("MEGA") {
+		return false
+	}
+	for _, c := range name[len("MEGA"):i] {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
 func isGitWorkTree(gitPath string, root string) bool {
 	cmd := exec.Command(gitPath, "rev-parse", "--is-inside-work-tree")
 	cmd.Dir = root
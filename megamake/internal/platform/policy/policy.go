@@ -11,6 +11,17 @@ import (
 type Policy struct {
 	NetEnabled   bool
 	AllowDomains []string
+
+	// AllowDelete is the explicit user consent required before any tool deletes a file.
+	AllowDelete bool
+}
+
+// RequireDeleteAllowed returns a policy error unless the user consented to file deletes.
+func (p Policy) RequireDeleteAllowed(relPath string) error {
+	if p.AllowDelete {
+		return nil
+	}
+	return errors.NewPolicy("file delete requires explicit consent (pass --allow-delete): " + relPath)
 }
 
 // RequireNetworkAllowed returns a policy error if networking is disabled or the host is not allowed.
@@ -0,0 +1,46 @@
+package version
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// Version is the release version, set at build time:
+//
+//	go build -ldflags "-X github.com/megamake/megamake/internal/platform/version.Version=v1.2.3" ./cmd/megamake
+var Version = ""
+
+// String returns Version if set, else the module version or VCS revision recorded by the Go
+// toolchain (e.g. "devel+3f2a1c9d0b7e-dirty"), else "devel".
+func String() string {
+	if strings.TrimSpace(Version) != "" {
+		return Version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+	rev, dirty := "", false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			rev = s.Value
+		case "vcs.modified":
+			dirty = s.Value == "true"
+		}
+	}
+	if rev == "" {
+		return "devel"
+	}
+	if len(rev) > 12 {
+		rev = rev[:12]
+	}
+	out := "devel+" + rev
+	if dirty {
+		out += "-dirty"
+	}
+	return out
+}